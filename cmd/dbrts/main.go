@@ -1,14 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/hook"
+	"github.com/kadirbelkuyu/DBRTS/internal/preset"
+	"github.com/kadirbelkuyu/DBRTS/internal/profile"
+	"github.com/kadirbelkuyu/DBRTS/internal/settings"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/format"
+	"github.com/kadirbelkuyu/DBRTS/pkg/interactive"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 const appName = "Database Backup Restore Transfer System"
@@ -45,6 +59,19 @@ var backupCmd = &cobra.Command{
 	RunE:  runBackup,
 }
 
+var backupInspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "List the contents of a backup file without restoring it",
+	RunE:  runBackupInspect,
+}
+
+var backupDiffCmd = &cobra.Command{
+	Use:   "diff <older backup> <newer backup>",
+	Short: "List the tables whose content changed between two backups taken with --table-checksums",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runBackupDiff,
+}
+
 var restoreCmd = &cobra.Command{
 	Use:   "restore",
 	Short: "Restore a database backup",
@@ -57,6 +84,153 @@ var listDbCmd = &cobra.Command{
 	RunE:  runListDatabases,
 }
 
+var dropDatabaseCmd = &cobra.Command{
+	Use:   "drop-database",
+	Short: "Drop a database, e.g. to tear down a test environment",
+	Long: `Drop a database on the server a config points at. PostgreSQL sessions
+connected to it are terminated first. The database name must be typed again
+to confirm unless --yes is given. System databases (postgres, template0,
+template1; admin, local, config) are never dropped.`,
+	RunE: runDropDatabase,
+}
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Describe a single table or collection",
+	RunE:  runDescribe,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a MongoDB collection as a flattened CSV file",
+	RunE:  runExport,
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query <sql statement | mongo filter>",
+	Short: "Run a SQL statement or MongoDB find and stream results as JSON lines",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runQuery,
+}
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage MongoDB collection indexes",
+}
+
+var indexListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List indexes on a collection",
+	RunE:  runIndexList,
+}
+
+var indexCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an index on a collection",
+	RunE:  runIndexCreate,
+}
+
+var indexDropCmd = &cobra.Command{
+	Use:   "drop <name>",
+	Short: "Drop an index from a collection",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runIndexDrop,
+}
+
+var compareCountsCmd = &cobra.Command{
+	Use:   "compare-counts",
+	Short: "Compare per-table row counts between two databases",
+	RunE:  runCompareCounts,
+}
+
+var migratePlanCmd = &cobra.Command{
+	Use:   "migrate-plan",
+	Short: "Write the SQL that would bring the target schema in line with the source, without running it",
+	RunE:  runMigratePlan,
+}
+
+var showDSNCmd = &cobra.Command{
+	Use:   "show-dsn",
+	Short: "Print the connection string DBRTS builds from a config, with secrets masked",
+	RunE:  runShowDSN,
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the installed dump and restore tools match the server version",
+	RunE:  runDoctor,
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check a config file for mistakes without connecting",
+	RunE:  runValidate,
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check a backup against the checksum recorded in its .meta.json",
+	Long: `Recompute the SHA-256 of a backup and compare it with the checksum recorded
+when it was taken. Exits 0 when it matches, 2 on a mismatch, 3 when the backup
+or its .meta.json is missing, and 1 on any other error.`,
+	RunE: runVerify,
+}
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Manage saved transfer presets",
+}
+
+var presetSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save transfer options as a named preset",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPresetSave,
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved transfer presets",
+	RunE:  runPresetList,
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage saved connection profiles",
+}
+
+var profileSetDefaultCmd = &cobra.Command{
+	Use:   "set-default <name>",
+	Short: "Use a saved profile when --config is omitted",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileSetDefault,
+}
+
+var profileMigrateCmd = &cobra.Command{
+	Use:   "migrate [name...]",
+	Short: "Rewrite saved profiles in the current config format (all profiles when no name is given)",
+	RunE:  runProfileMigrate,
+}
+
+var profileSetSecretCmd = &cobra.Command{
+	Use:   "set-secret <name>",
+	Short: "Store a profile's password or URI in the system keychain",
+	Long: "Reads the secret from stdin, stores it in the system keychain and replaces the\n" +
+		"profile's field with a keychain:<service>/<account> reference.",
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileSetSecret,
+}
+
+var profileCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report saved profiles that fail to load and why",
+	Long: "Checks every saved profile, including those the interactive picker skips.\n" +
+		"--fix rewrites outdated profiles with their defaults made explicit and\n" +
+		"--quarantine moves invalid ones into " + profile.DefaultDir + "/" + profile.QuarantineDir + ".",
+	Args: cobra.NoArgs,
+	RunE: runProfileCheck,
+}
+
 var interactiveCmd = &cobra.Command{
 	Use:   "interactive",
 	Short: "Launch the guided interactive workflow",
@@ -72,52 +246,452 @@ var (
 	parallelWorkers  int
 	batchSize        int
 	verbose          bool
+	readPreference   string
+	splitThreshold   int64
+	describeTable    string
+	describeColl     string
+	outputFormat     string
+	presetName       string
+	identifierCase   string
+	unkeyedStrategy  string
+	disableTriggers  bool
+	preserveStorage  bool
+	verifyChecksums  bool
+	maxConcurrency   int
+	rateLimitRows    int
+	rateLimitMB      float64
+	junitOut         string
+	sameServer       bool
+	preHook          string
+	postHook         string
+	ignorePreHookErr bool
+	fromRegistry     bool
+	indexCollection  string
+	indexSpec        app.IndexSpec
+	assumeYes        bool
+	exportCollection string
+	exportFormat     string
+	exportOutput     string
+	secretField      string
+	checkFix         bool
+	checkQuarantine  bool
+	includeDBs       []string
+	excludeDBs       []string
+	connectableOnly  bool
+	exportLimit      int64
+	exportFilter     string
+	literalIDs       bool
+	mongoDB          string
+	flushEvery       int
+	outBufferSize    int
+	extraArgs        []string
+	strictVersion    bool
+	transformFlags   []string
+	excludeColumns   []string
+	schemaMapFlags   []string
+	nullString       string
+	boolFormat       string
+	dropDatabase     string
+	refreshMatViews  bool
+	mongoTransforms  []string
+	appendMode       bool
+	schemaDiff       bool
+	consistentSnap   bool
+	regenerateIDs    bool
+	estimateCounts   bool
+	viaDump          bool
+	dumpCompression  int
+	backupFormat     string
+	filenameTemplate string
+	ifExists         bool
+	atomicSwap       bool
+	tolerateExisting bool
+	dumpJobs         int
+	excludeTables    []string
+	excludeSchemas   []string
+	tableChecksums   bool
+	listenChannel    string
+	useCopy          bool
+	transferQuery    string
+	targetTable      string
+	verifyPath       string
+	restoreJobs      int
+	inspectFile      string
+	migrateOut       string
+	connFlags        config.DatabaseConfig
+	dumpGlobals      bool
+	applyGlobals     bool
+	queryCollection  string
+	queryLimit       int64
+	queryOutput      string
+	applicationName  string
 )
 
 func init() {
-	transferCmd.Flags().StringVar(&sourceConfigPath, "source-config", "", "Path to the source database configuration file")
+	rootCmd.PersistentFlags().StringVar(&applicationName, "application-name", "", "Name reported to the server as application_name/appName (overrides the config)")
+
+	transferCmd.Flags().StringVar(&sourceConfigPath, "source-config", "", "Path to the source database configuration file (defaults to the default profile)")
 	transferCmd.Flags().StringVar(&targetConfigPath, "target-config", "", "Path to the target database configuration file")
-	transferCmd.Flags().BoolVar(&schemaOnly, "schema-only", false, "Transfer schema objects only")
-	transferCmd.Flags().BoolVar(&dataOnly, "data-only", false, "Transfer data only")
-	transferCmd.Flags().IntVar(&parallelWorkers, "workers", 4, "Number of parallel workers during transfer")
-	transferCmd.Flags().IntVar(&batchSize, "batch-size", 1000, "Batch size for data transfer")
+	addTransferOptionFlags(transferCmd)
+	transferCmd.Flags().IntVar(&maxConcurrency, "max-concurrency", 0, "Upper bound on concurrent copy operations across all tables (defaults to the number of CPUs)")
+	transferCmd.Flags().IntVar(&rateLimitRows, "rate-limit-rows", 0, "Cap combined throughput at this many rows/documents per second (0 is unlimited)")
+	transferCmd.Flags().Float64Var(&rateLimitMB, "rate-limit-mb", 0, "Cap combined throughput at this many megabytes per second (0 is unlimited)")
+	transferCmd.Flags().StringVar(&listenChannel, "listen", "", "PostgreSQL: LISTEN on this channel on source and target and log NOTIFY messages (e.g. progress sent with pg_notify by triggers)")
+	transferCmd.Flags().StringVar(&transferQuery, "query", "", "PostgreSQL: copy the result of this query instead of the source's tables (needs --target-table)")
+	transferCmd.Flags().StringVar(&targetTable, "target-table", "", "Table, as schema.table, that --query results are copied into; created from the result's column types if missing")
+	transferCmd.Flags().BoolVar(&sameServer, "same-server-optimize", false, "PostgreSQL: when source and target share a server, copy each table server-side through dblink")
+	transferCmd.Flags().StringArrayVar(&mongoTransforms, "mongo-transform", nil, "MongoDB: rewrite a field while copying, as field.path:operation (remove, mask, hash, nullify, const=<value>; repeatable)")
+	transferCmd.Flags().StringArrayVar(&excludeColumns, "exclude-column", nil, "PostgreSQL: leave a column out of both the target table and the copy, as schema.table.column (repeatable)")
+	transferCmd.Flags().BoolVar(&refreshMatViews, "refresh-matviews", false, "PostgreSQL: refresh materialized views on the target after the data is copied (they are created empty)")
+	transferCmd.Flags().StringArrayVar(&schemaMapFlags, "map-schema", nil, "PostgreSQL: create and copy a source schema's objects into another target schema, as source:target (repeatable)")
+	transferCmd.Flags().StringArrayVar(&transformFlags, "transform", nil, "Rewrite a column while copying, as schema.table.column:transform (mask, hash, nullify, const=<value>; repeatable)")
+	transferCmd.Flags().BoolVar(&viaDump, "via-dump", false, "PostgreSQL: stream pg_dump --format=custom into pg_restore instead of copying through two connections")
+	transferCmd.Flags().IntVar(&dumpCompression, "dump-compression", 0, "Compression level (0-9) of the --via-dump archive stream (0 uses the pg_dump default)")
+	transferCmd.Flags().StringVar(&presetName, "preset", "", "Load transfer options from a saved preset (explicit flags take precedence)")
 	transferCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+	transferCmd.Flags().StringVar(&outputFormat, "output", "text", "Per-table report format: text or json")
+	transferCmd.Flags().StringVar(&junitOut, "junit-out", "", "Also write the per-table outcome to this file as JUnit XML for CI")
+	addHookFlags(transferCmd)
 
-	transferCmd.MarkFlagRequired("source-config")
 	transferCmd.MarkFlagRequired("target-config")
 
-	backupCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file")
+	backupCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
 	backupCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
-	backupCmd.MarkFlagRequired("config")
+	backupCmd.Flags().StringVar(&backupFormat, "format", "", "PostgreSQL backup format: custom, sql, tar or directory (skips the format prompt)")
+	backupCmd.Flags().StringVar(&readPreference, "read-preference", "", "MongoDB read preference for mongodump (e.g. secondary, secondaryPreferred)")
+	backupCmd.Flags().StringArrayVar(&extraArgs, "extra-arg", nil, "Extra argument passed verbatim to pg_dump/mongodump (repeatable)")
+	backupCmd.Flags().IntVar(&dumpJobs, "jobs", 0, "PostgreSQL: dump this many tables in parallel (directory format only)")
+	backupCmd.Flags().StringArrayVar(&excludeTables, "exclude-table", nil, "PostgreSQL: leave tables matching this pg_dump pattern out of the backup, e.g. 'audit.*' (repeatable)")
+	backupCmd.Flags().StringArrayVar(&excludeSchemas, "exclude-schema", nil, "PostgreSQL: leave schemas matching this pg_dump pattern out of the backup (repeatable)")
+	backupCmd.Flags().BoolVar(&tableChecksums, "table-checksums", false, "PostgreSQL: also checksum every table into a companion .checksums.json for backup diff (reads every row once more)")
+	backupCmd.Flags().BoolVar(&dumpGlobals, "dump-globals", false, "PostgreSQL: also write roles and tablespaces to a companion .globals.sql via pg_dumpall (requires superuser)")
+	backupCmd.Flags().StringVar(&filenameTemplate, "filename-template", "", "Name for backups under backup/: a Go time layout where {db} is the database name (default \"{db}_20060102_150405\")")
+	backupCmd.Flags().BoolVar(&strictVersion, "strict-version", false, "Fail instead of warning when the dump tool is older than the server")
+	backupCmd.Flags().StringVar(&junitOut, "junit-out", "", "Also write the outcome of each backup step to this file as JUnit XML for CI")
+	addHookFlags(backupCmd)
+	backupInspectCmd.Flags().StringVar(&inspectFile, "file", "", "Backup file or pg_dump directory to inspect")
+	backupInspectCmd.Flags().StringVar(&outputFormat, "output", "text", "Output format: text or json")
+	backupInspectCmd.MarkFlagRequired("file")
+	backupCmd.AddCommand(backupInspectCmd)
+
+	backupDiffCmd.Flags().StringVar(&outputFormat, "output", "text", "Output format: text or json")
+	backupCmd.AddCommand(backupDiffCmd)
 
-	restoreCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file")
+	restoreCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
 	restoreCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
-	restoreCmd.MarkFlagRequired("config")
+	restoreCmd.Flags().BoolVar(&fromRegistry, "from-registry", false, "Choose the backup to restore from previously recorded backups")
+	restoreCmd.Flags().StringArrayVar(&extraArgs, "extra-arg", nil, "Extra argument passed verbatim to pg_restore/psql/mongorestore (repeatable)")
+	restoreCmd.Flags().BoolVar(&ifExists, "if-exists", true, "When cleaning before restore, use DROP ... IF EXISTS so missing objects are not errors")
+	restoreCmd.Flags().BoolVar(&atomicSwap, "atomic-swap", false, "PostgreSQL: restore into a temporary database, check it, then rename it over the target (the old one is kept as <name>_old)")
+	restoreCmd.Flags().BoolVar(&tolerateExisting, "tolerate-existing", false, "Keep going past errors about objects or documents the target already has and report how many were skipped; other errors still fail")
+	restoreCmd.Flags().IntVar(&restoreJobs, "jobs", 0, "PostgreSQL: restore a custom or directory backup with this many parallel pg_restore jobs")
+	restoreCmd.Flags().BoolVar(&applyGlobals, "apply-globals", false, "PostgreSQL: apply the backup's companion .globals.sql before restoring it")
+	restoreCmd.Flags().BoolVar(&strictVersion, "strict-version", false, "Fail instead of warning when the restore tool is older than the server")
+	addHookFlags(restoreCmd)
+
+	listDbCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+	addDatabaseFilterFlags(listDbCmd)
+
+	dropDatabaseCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+	dropDatabaseCmd.Flags().StringVar(&dropDatabase, "name", "", "Database to drop")
+	dropDatabaseCmd.Flags().BoolVar(&assumeYes, "yes", false, "Drop without asking for the database name to be typed again")
+	dropDatabaseCmd.MarkFlagRequired("name")
+	addDatabaseFilterFlags(backupCmd)
+
+	queryCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+	queryCmd.Flags().StringVar(&queryOutput, "output", "ndjson", "Output format (ndjson)")
+	queryCmd.Flags().StringVar(&queryCollection, "collection", "", "MongoDB collection to query; the argument is then a filter in extended JSON")
+	queryCmd.Flags().Int64Var(&queryLimit, "limit", 0, "MongoDB: return at most this many documents (0 returns all)")
+	queryCmd.Flags().BoolVar(&literalIDs, "literal-ids", false, "Do not convert 24-character hex _id strings in the filter to ObjectIDs")
+	addCellFlags(queryCmd)
+	addOutputBufferFlags(queryCmd)
+
+	for _, cmd := range []*cobra.Command{listDbCmd, describeCmd, exportCmd, queryCmd, indexListCmd, showDSNCmd, doctorCmd} {
+		addConnectionFlags(cmd)
+	}
+	for _, cmd := range []*cobra.Command{describeCmd, exportCmd, queryCmd, indexListCmd} {
+		addMongoDatabaseFlag(cmd)
+	}
+
+	describeCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+	describeCmd.Flags().StringVar(&describeTable, "table", "", "PostgreSQL table to describe (schema.table)")
+	describeCmd.Flags().StringVar(&describeColl, "collection", "", "MongoDB collection to describe")
+	describeCmd.Flags().StringVar(&outputFormat, "output", "text", "Output format: text or json")
 
-	listDbCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file")
-	listDbCmd.MarkFlagRequired("config")
+	exportCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+	exportCmd.Flags().StringVar(&exportCollection, "collection", "", "MongoDB collection to export")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "csv", "Export format (csv)")
+	exportCmd.Flags().StringVar(&exportOutput, "out", "", "Output file (defaults to stdout)")
+	exportCmd.Flags().Int64Var(&exportLimit, "limit", 0, "Export at most this many documents (0 exports all)")
+	exportCmd.Flags().StringVar(&exportFilter, "filter", "", `Query filter as extended JSON, e.g. '{"status":"active"}'`)
+	exportCmd.Flags().BoolVar(&literalIDs, "literal-ids", false, "Do not convert 24-character hex _id strings in --filter to ObjectIDs")
+	addCellFlags(exportCmd)
+	addOutputBufferFlags(exportCmd)
+	exportCmd.MarkFlagRequired("collection")
+
+	for _, cmd := range []*cobra.Command{indexListCmd, indexCreateCmd, indexDropCmd} {
+		cmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+		cmd.Flags().StringVar(&indexCollection, "collection", "", "MongoDB collection")
+		cmd.MarkFlagRequired("collection")
+	}
+	indexCreateCmd.Flags().StringVar(&indexSpec.Keys, "keys", "", "Index keys as field:direction pairs, e.g. email:1,created_at:-1")
+	indexCreateCmd.Flags().StringVar(&indexSpec.Name, "name", "", "Index name (defaults to the server-generated name)")
+	indexCreateCmd.Flags().BoolVar(&indexSpec.Unique, "unique", false, "Reject documents with duplicate key values")
+	indexCreateCmd.Flags().BoolVar(&indexSpec.Sparse, "sparse", false, "Only index documents that contain the key fields")
+	indexCreateCmd.Flags().Int32Var(&indexSpec.TTLSeconds, "ttl", 0, "Expire documents this many seconds after the indexed date (single-key indexes only)")
+	indexCreateCmd.MarkFlagRequired("keys")
+	indexDropCmd.Flags().BoolVar(&assumeYes, "yes", false, "Drop without asking for confirmation")
+	indexCmd.AddCommand(indexListCmd)
+	indexCmd.AddCommand(indexCreateCmd)
+	indexCmd.AddCommand(indexDropCmd)
+
+	compareCountsCmd.Flags().StringVar(&sourceConfigPath, "source-config", "", "Path to the source database configuration file (defaults to the default profile)")
+	compareCountsCmd.Flags().StringVar(&targetConfigPath, "target-config", "", "Path to the target database configuration file")
+	compareCountsCmd.Flags().BoolVar(&estimateCounts, "estimate", false, "Use planner statistics or collection metadata instead of counting every row")
+	compareCountsCmd.Flags().StringVar(&outputFormat, "output", "text", "Output format: text or json")
+
+	migratePlanCmd.Flags().StringVar(&sourceConfigPath, "source-config", "", "Path to the source database configuration file (defaults to the default profile)")
+	migratePlanCmd.Flags().StringVar(&targetConfigPath, "target-config", "", "Path to the target database configuration file")
+	migratePlanCmd.Flags().StringVar(&migrateOut, "out", "", "Write the script to this file instead of stdout")
+	migratePlanCmd.Flags().StringVar(&identifierCase, "identifier-case", "preserve", "Case folding for target table/column/index names: preserve, lower or upper")
+	migratePlanCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+	migratePlanCmd.MarkFlagRequired("target-config")
+
+	showDSNCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+
+	validateCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+
+	verifyCmd.Flags().StringVar(&verifyPath, "backup", "", "Backup file or pg_dump directory to verify")
+	verifyCmd.MarkFlagRequired("backup")
+
+	doctorCmd.Flags().StringVar(&configPath, "config", "", "Path to the database configuration file (defaults to the default profile)")
+	doctorCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+
+	addTransferOptionFlags(presetSaveCmd)
+	presetCmd.AddCommand(presetSaveCmd)
+	presetCmd.AddCommand(presetListCmd)
+
+	profileCmd.AddCommand(profileSetDefaultCmd)
+	profileCmd.AddCommand(profileMigrateCmd)
+	profileSetSecretCmd.Flags().StringVar(&secretField, "field", "password", "Profile field to store: password or uri")
+	profileCmd.AddCommand(profileSetSecretCmd)
+	profileCheckCmd.Flags().BoolVar(&checkFix, "fix", false, "Rewrite outdated profiles in the current config format")
+	profileCheckCmd.Flags().BoolVar(&checkQuarantine, "quarantine", false, "Move invalid profiles out of the profile directory")
+	profileCmd.AddCommand(profileCheckCmd)
 
 	rootCmd.AddCommand(transferCmd)
 	rootCmd.AddCommand(backupCmd)
 	rootCmd.AddCommand(restoreCmd)
 	rootCmd.AddCommand(listDbCmd)
+	rootCmd.AddCommand(dropDatabaseCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(indexCmd)
+	rootCmd.AddCommand(compareCountsCmd)
+	rootCmd.AddCommand(migratePlanCmd)
+	rootCmd.AddCommand(showDSNCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(presetCmd)
+	rootCmd.AddCommand(profileCmd)
 	rootCmd.AddCommand(interactiveCmd)
 }
 
+// addTransferOptionFlags registers the flags shared by transfer and preset save.
+func addTransferOptionFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&schemaOnly, "schema-only", false, "Transfer schema objects only")
+	cmd.Flags().BoolVar(&dataOnly, "data-only", false, "Transfer data only")
+	cmd.Flags().IntVar(&parallelWorkers, "workers", 4, "Number of parallel workers during transfer")
+	cmd.Flags().IntVar(&batchSize, "batch-size", 1000, "Batch size for data transfer")
+	cmd.Flags().Int64Var(&splitThreshold, "split-threshold", 0, "Row count above which a table with a numeric primary key is copied in parallel ranges (0 disables)")
+	cmd.Flags().StringVar(&identifierCase, "identifier-case", "preserve", "Case folding for target table/column/index names: preserve, lower or upper")
+	cmd.Flags().BoolVar(&disableTriggers, "disable-triggers", false, "Disable target table triggers while loading data (requires table ownership)")
+	cmd.Flags().BoolVar(&preserveStorage, "preserve-storage", false, "Copy table storage parameters (fillfactor, autovacuum) and tablespaces")
+	cmd.Flags().BoolVar(&appendMode, "append", false, "MongoDB: keep existing target documents instead of dropping collections; duplicate _ids are skipped")
+	cmd.Flags().BoolVar(&regenerateIDs, "regenerate-ids", false, "MongoDB: insert documents without their _id so the target assigns new ObjectIds (breaks references by _id)")
+	cmd.Flags().BoolVar(&schemaDiff, "schema-diff", false, "PostgreSQL: create only tables, columns, indexes and foreign keys missing on the target")
+	cmd.Flags().BoolVar(&consistentSnap, "consistent-snapshot", false, "PostgreSQL: read every table from one exported source snapshot so the copy is point-in-time consistent")
+	cmd.Flags().StringVar(&unkeyedStrategy, "unkeyed-strategy", transfer.UnkeyedOffset, "PostgreSQL: how to read tables with no primary key or unique NOT NULL index: offset (paged, may skip or repeat rows under concurrent writes) or full-read (one unpaged query)")
+	cmd.Flags().BoolVar(&useCopy, "copy", false, "PostgreSQL: load batches with COPY FROM STDIN instead of INSERT (faster for wide tables; a row that already exists on the target fails its batch)")
+	cmd.Flags().BoolVar(&verifyChecksums, "verify-checksums", false, "Compare per-table content checksums between source and target after copying (reads every row twice)")
+}
+
+// addHookFlags registers the pre/post hook flags shared by transfer, backup and restore.
+func addHookFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&preHook, "pre-hook", "", "Shell command to run before the operation; a failure aborts it")
+	cmd.Flags().StringVar(&postHook, "post-hook", "", "Shell command to run after the operation, with DBRTS_STATUS set to success or failure")
+	cmd.Flags().BoolVar(&ignorePreHookErr, "continue-on-hook-failure", false, "Run the operation even if the pre-hook fails")
+}
+
+// addDatabaseFilterFlags registers the flags that narrow the listed databases.
+func addDatabaseFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&includeDBs, "include-db", nil, "Only list databases matching this shell pattern, e.g. 'app_*' (repeatable; adds to the profile's database_filter)")
+	cmd.Flags().StringArrayVar(&excludeDBs, "exclude-db", nil, "Hide databases matching this shell pattern (repeatable; adds to the profile's database_filter)")
+	cmd.Flags().BoolVar(&connectableOnly, "connectable-only", false, "Only list databases the configured user may connect to")
+}
+
+// withDatabaseFilter adds the filter flags to the profile's database_filter.
+func withDatabaseFilter(cfg *config.Config) (*config.Config, error) {
+	filter := &cfg.DatabaseFilter
+	filter.Include = append(filter.Include, includeDBs...)
+	filter.Exclude = append(filter.Exclude, excludeDBs...)
+	filter.ConnectableOnly = filter.ConnectableOnly || connectableOnly
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// addConnectionFlags lets a command connect without a saved config file.
+func addConnectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&connFlags.Type, "type", "", "Database type for a direct connection: postgres or mongo (inferred from --uri)")
+	cmd.Flags().StringVar(&connFlags.Host, "host", "", "Connect directly to this host instead of using a config file")
+	cmd.Flags().IntVar(&connFlags.Port, "port", 0, "Port for a direct connection (defaults to the engine's port)")
+	cmd.Flags().StringVar(&connFlags.Username, "user", "", "Username for a direct connection")
+	cmd.Flags().StringVar(&connFlags.Password, "password", "", "Password for a direct connection (or set DBRTS_PASSWORD)")
+	cmd.Flags().StringVar(&connFlags.Database, "database", "", "Database for a direct connection")
+	cmd.Flags().StringVar(&connFlags.SSLMode, "sslmode", "", "PostgreSQL sslmode for a direct connection")
+	cmd.Flags().StringVar(&connFlags.URI, "uri", "", "MongoDB connection URI for a direct connection")
+}
+
+// loadCommandConfig builds an in-memory config when connection flags are
+// given and otherwise resolves --config like loadConfig. Nothing is saved.
+func loadCommandConfig(cmd *cobra.Command) (*config.Config, error) {
+	if !cmd.Flags().Changed("host") && !cmd.Flags().Changed("uri") {
+		cfg, err := loadConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		return withMongoDatabase(cfg)
+	}
+	if configPath != "" {
+		return nil, fmt.Errorf("--config cannot be combined with --host or --uri")
+	}
+
+	db := connFlags
+	if db.Password == "" {
+		db.Password = os.Getenv("DBRTS_PASSWORD")
+	}
+	cfg, err := config.NewConfig(db)
+	if err != nil {
+		return nil, err
+	}
+	return withMongoDatabase(withApplicationName(cfg))
+}
+
+// addMongoDatabaseFlag lets a MongoDB command work on another database of the
+// same server without editing the profile.
+func addMongoDatabaseFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&mongoDB, "mongo-db", "", "MongoDB database to use instead of the config's (the profile is not changed)")
+}
+
+// withMongoDatabase applies --mongo-db over the configured database.
+func withMongoDatabase(cfg *config.Config) (*config.Config, error) {
+	if mongoDB == "" {
+		return cfg, nil
+	}
+	if cfg.Database.Type != "mongo" {
+		return nil, fmt.Errorf("--mongo-db only applies to MongoDB configs")
+	}
+	return cfg.WithMongoDatabase(mongoDB), nil
+}
+
+// withApplicationName applies --application-name over the configured name.
+func withApplicationName(cfg *config.Config) *config.Config {
+	if applicationName != "" {
+		cfg.Database.ApplicationName = applicationName
+	}
+	return cfg
+}
+
+func hooksFromFlags() hook.Hooks {
+	return hook.Hooks{
+		Pre:                  preHook,
+		Post:                 postHook,
+		ContinueOnPreFailure: ignorePreHookErr,
+	}
+}
+
+func transferOptionsFromFlags() transfer.Options {
+	return transfer.Options{
+		SchemaOnly:      schemaOnly,
+		DataOnly:        dataOnly,
+		ParallelWorkers: parallelWorkers,
+		BatchSize:       batchSize,
+		SplitThreshold:  splitThreshold,
+		IdentifierCase:  identifierCase,
+		DisableTriggers: disableTriggers,
+		PreserveStorage: preserveStorage,
+		VerifyChecksums: verifyChecksums,
+		Append:          appendMode,
+		SchemaDiff:      schemaDiff,
+		UseCopy:         useCopy,
+
+		ConsistentSnapshot: consistentSnap,
+		RegenerateIDs:      regenerateIDs,
+
+		UnkeyedStrategy: unkeyedStrategy,
+	}
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
+// Exit codes that let scripts tell why verify failed. Every other error
+// exits 1.
+const (
+	exitChecksumMismatch = 2
+	exitBackupMissing    = 3
+)
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, backup.ErrChecksumMismatch):
+		return exitChecksumMismatch
+	case errors.Is(err, backup.ErrBackupMissing):
+		return exitBackupMissing
+	default:
+		return 1
+	}
+}
+
+// loadConfig resolves the config path (explicit flag, then default profile)
+// and loads it. Every command that accepts --config goes through here.
+func loadConfig(flagValue string) (*config.Config, error) {
+	path, err := profile.ResolveConfigPath(flagValue, profile.DefaultDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return withApplicationName(cfg), nil
+}
+
+// loadSettings reads the per-user defaults file. It is optional, so a missing
+// file or home directory means no defaults.
+func loadSettings() (*settings.Settings, error) {
+	path, err := settings.DefaultPath()
+	if err != nil {
+		return &settings.Settings{}, nil
+	}
+	return settings.Load(path)
+}
+
 func runInteractive(cmd *cobra.Command, args []string) error {
 	application := app.NewApplication(os.Stdin, printBanner)
 	return application.RunInteractive()
 }
 
 func runTransfer(cmd *cobra.Command, args []string) error {
-	sourceConfig, err := config.LoadConfig(sourceConfigPath)
+	sourceConfig, err := loadConfig(sourceConfigPath)
 	if err != nil {
 		return fmt.Errorf("cannot load source config: %w", err)
 	}
@@ -126,37 +700,505 @@ func runTransfer(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("cannot load target config: %w", err)
 	}
+	targetConfig = withApplicationName(targetConfig)
 
-	return app.RunTransfer(sourceConfig, targetConfig, schemaOnly, dataOnly, parallelWorkers, batchSize, verbose)
+	opts := transferOptionsFromFlags()
+	opts.MaxConcurrency = maxConcurrency
+	opts.RateLimitRows = rateLimitRows
+	opts.RateLimitMB = rateLimitMB
+	opts.SameServerOptimize = sameServer
+	opts.ListenChannel = listenChannel
+	opts.RefreshMatViews = refreshMatViews
+	opts.Query = transferQuery
+	opts.TargetTable = targetTable
+	opts.ViaDump = viaDump
+	opts.DumpCompression = dumpCompression
+	opts.Transforms, err = transfer.ParseTransformFlags(transformFlags)
+	if err != nil {
+		return err
+	}
+	opts.ExcludeColumns, err = transfer.ParseExcludeColumnFlags(excludeColumns)
+	if err != nil {
+		return err
+	}
+	opts.SchemaMap, err = transfer.ParseSchemaMapFlags(schemaMapFlags)
+	if err != nil {
+		return err
+	}
+	opts.MongoTransforms, err = transfer.ParseMongoTransformFlags(mongoTransforms)
+	if err != nil {
+		return err
+	}
+	if presetName != "" {
+		p, err := preset.Load(preset.DefaultDir, presetName)
+		if err != nil {
+			return err
+		}
+		opts = p.Merge(opts, cmd.Flags().Changed)
+	} else {
+		userSettings, err := loadSettings()
+		if err != nil {
+			return err
+		}
+		opts = userSettings.For(sourceConfig.Database.Type).TransferOptions(opts, cmd.Flags().Changed)
+	}
+
+	return app.RunTransfer(sourceConfig, targetConfig, opts, hooksFromFlags(), outputFormat, junitOut, verbose)
 }
 
 func runBackup(cmd *cobra.Command, args []string) error {
-	cfg, err := config.LoadConfig(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("cannot load config: %w", err)
 	}
+	if cfg, err = withDatabaseFilter(cfg); err != nil {
+		return err
+	}
+
+	format := ""
+	if backupFormat != "" {
+		if format, err = backup.NormalizeFormat(cfg.Database.Type, backupFormat); err != nil {
+			return err
+		}
+	}
+	if dumpJobs > 1 && cfg.Database.Type == "postgres" && format != "" && format != "directory" {
+		return fmt.Errorf("--jobs needs --format directory; pg_dump cannot write %s backups in parallel", format)
+	}
 
-	return app.RunBackup(cfg, verbose)
+	userSettings, err := loadSettings()
+	if err != nil {
+		return err
+	}
+	engineSettings := userSettings.For(cfg.Database.Type)
+	defaults, err := engineSettings.PromptDefaults(cfg.Database.Type)
+	if err != nil {
+		return err
+	}
+	flags := engineSettings.BackupOptions(backup.BackupOptions{
+		Format:         format,
+		ReadPreference: readPreference,
+		ExtraArgs:      extraArgs,
+		StrictVersion:  strictVersion,
+		DumpGlobals:    dumpGlobals,
+		Jobs:           dumpJobs,
+		ExcludeTables:  excludeTables,
+		ExcludeSchemas: excludeSchemas,
+		TableChecksums: tableChecksums,
+
+		FilenameTemplate: filenameTemplate,
+	}, cmd.Flags().Changed)
+	if _, err := backup.RenderFilename(flags.FilenameTemplate, cfg.Database.Database, time.Now(), ""); err != nil {
+		return err
+	}
+
+	return app.RunBackup(cfg, flags, defaults, hooksFromFlags(), junitOut, verbose)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	return app.VerifyBackup(os.Stdout, verifyPath)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		fmt.Printf("[FAIL] load: %v\n", err)
+		return fmt.Errorf("config cannot be loaded")
+	}
+	return app.WriteValidationReport(os.Stdout, cfg.Checks())
+}
+
+func runBackupInspect(cmd *cobra.Command, args []string) error {
+	return app.InspectBackup(inspectFile, outputFormat)
+}
+
+func runBackupDiff(cmd *cobra.Command, args []string) error {
+	return app.DiffBackupChecksums(args[0], args[1], outputFormat)
 }
 
 func runRestore(cmd *cobra.Command, args []string) error {
-	cfg, err := config.LoadConfig(configPath)
+	cfg, err := loadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("cannot load config: %w", err)
 	}
 
-	return app.RunRestore(cfg, verbose)
+	return app.RunRestore(cfg, backup.RestoreOptions{
+		ExtraArgs:     extraArgs,
+		StrictVersion: strictVersion,
+		IfExists:      ifExists,
+		AtomicSwap:    atomicSwap,
+		ApplyGlobals:  applyGlobals,
+		Jobs:          restoreJobs,
+
+		TolerateExisting: tolerateExisting,
+	}, fromRegistry, hooksFromFlags(), verbose)
 }
 
 func runListDatabases(cmd *cobra.Command, args []string) error {
-	cfg, err := config.LoadConfig(configPath)
+	cfg, err := loadCommandConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("cannot load config: %w", err)
 	}
+	if cfg, err = withDatabaseFilter(cfg); err != nil {
+		return err
+	}
 
 	return app.ListDatabases(cfg)
 }
 
+func runDropDatabase(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	return app.DropDatabase(cfg, dropDatabase, assumeYes, os.Stdin, os.Stdout)
+}
+
+func runDescribe(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCommandConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	switch {
+	case describeTable != "" && describeColl != "":
+		return fmt.Errorf("use either --table or --collection, not both")
+	case describeTable != "":
+		return app.DescribeTable(cfg, describeTable, outputFormat)
+	case describeColl != "":
+		return app.DescribeCollection(cfg, describeColl, outputFormat)
+	default:
+		return fmt.Errorf("either --table or --collection is required")
+	}
+}
+
+func addCellFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&nullString, "null-string", format.DefaultNullString, `How NULL values are written, e.g. '\N' for PostgreSQL COPY or '' for empty`)
+	cmd.Flags().StringVar(&boolFormat, "bool-format", format.BoolTrueFalse, "How booleans are written: true/false, t/f, 1/0 or yes/no")
+}
+
+func cellOptionsFromFlags() (format.CellOptions, error) {
+	if err := format.ValidateBoolFormat(boolFormat); err != nil {
+		return format.CellOptions{}, err
+	}
+	return format.CellOptions{NullString: nullString, BoolFormat: boolFormat}, nil
+}
+
+func addOutputBufferFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&flushEvery, "flush-every", app.DefaultFlushEvery, "Flush output after this many rows (0 flushes only when the buffer is full)")
+	cmd.Flags().IntVar(&outBufferSize, "buffer-size", app.DefaultBufferSize, "Output buffer size in bytes (at least 4096)")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCommandConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	cell, err := cellOptionsFromFlags()
+	if err != nil {
+		return err
+	}
+
+	return app.ExportCollection(cfg, exportCollection, app.ExportOptions{
+		Format:     exportFormat,
+		Output:     exportOutput,
+		Limit:      exportLimit,
+		Filter:     exportFilter,
+		LiteralIDs: literalIDs,
+		Cell:       cell,
+		FlushEvery: flushEvery,
+		BufferSize: outBufferSize,
+	})
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCommandConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	statement := ""
+	if len(args) == 1 {
+		statement = args[0]
+	}
+	if statement == "" && cfg.Database.Type == "postgres" {
+		return fmt.Errorf("a SQL statement is required")
+	}
+
+	// Without either flag NULLs and booleans stay JSON null, true and false.
+	var cell *format.CellOptions
+	if cmd.Flags().Changed("null-string") || cmd.Flags().Changed("bool-format") {
+		options, err := cellOptionsFromFlags()
+		if err != nil {
+			return err
+		}
+		cell = &options
+	}
+
+	return app.RunQuery(cfg, statement, app.QueryOptions{
+		Output:     queryOutput,
+		Collection: queryCollection,
+		Limit:      queryLimit,
+		LiteralIDs: literalIDs,
+		Cell:       cell,
+		FlushEvery: flushEvery,
+		BufferSize: outBufferSize,
+	})
+}
+
+func runIndexList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCommandConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	return app.ListIndexes(cfg, indexCollection)
+}
+
+func runIndexCreate(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	return app.CreateIndex(cfg, indexCollection, indexSpec)
+}
+
+func runIndexDrop(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	target := fmt.Sprintf("index %s on %s", args[0], indexCollection)
+	if !assumeYes && !interactive.NewDatabaseSelector(cfg.Database.Type).ConfirmAction("drop", target) {
+		fmt.Println("Operation cancelled.")
+		return nil
+	}
+
+	return app.DropIndex(cfg, indexCollection, args[0])
+}
+
+func runCompareCounts(cmd *cobra.Command, args []string) error {
+	sourceConfig, err := loadConfig(sourceConfigPath)
+	if err != nil {
+		return fmt.Errorf("cannot load source config: %w", err)
+	}
+
+	targetConfig, err := config.LoadConfig(targetConfigPath)
+	if err != nil {
+		return fmt.Errorf("cannot load target config: %w", err)
+	}
+
+	return app.RunCompareCounts(sourceConfig, withApplicationName(targetConfig), estimateCounts, outputFormat)
+}
+
+func runMigratePlan(cmd *cobra.Command, args []string) error {
+	sourceConfig, err := loadConfig(sourceConfigPath)
+	if err != nil {
+		return fmt.Errorf("cannot load source config: %w", err)
+	}
+
+	targetConfig, err := config.LoadConfig(targetConfigPath)
+	if err != nil {
+		return fmt.Errorf("cannot load target config: %w", err)
+	}
+
+	return app.RunMigratePlan(sourceConfig, withApplicationName(targetConfig), identifierCase, migrateOut, verbose)
+}
+
+func runShowDSN(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCommandConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	return app.ShowDSN(cfg)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cfg, err := loadCommandConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	return app.RunDoctor(cfg, verbose)
+}
+
+func runPresetSave(cmd *cobra.Command, args []string) error {
+	if err := preset.Save(preset.DefaultDir, args[0], preset.FromOptions(transferOptionsFromFlags())); err != nil {
+		return fmt.Errorf("cannot save preset: %w", err)
+	}
+
+	fmt.Printf("Preset %s saved to %s\n", args[0], preset.DefaultDir)
+	return nil
+}
+
+func runPresetList(cmd *cobra.Command, args []string) error {
+	names, err := preset.List(preset.DefaultDir)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No presets saved.")
+		return nil
+	}
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func runProfileSetDefault(cmd *cobra.Command, args []string) error {
+	if err := profile.SetDefault(profile.DefaultDir, args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Default profile set to %s\n", args[0])
+	return nil
+}
+
+func runProfileMigrate(cmd *cobra.Command, args []string) error {
+	var paths []string
+	if len(args) == 0 {
+		files, err := profile.Files(profile.DefaultDir)
+		if err != nil {
+			return err
+		}
+		paths = files
+	}
+	for _, name := range args {
+		path, err := profile.Path(profile.DefaultDir, name)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+
+	if len(paths) == 0 {
+		fmt.Println("No saved profiles to migrate.")
+		return nil
+	}
+
+	for _, path := range paths {
+		migrated, err := config.MigrateFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if migrated {
+			fmt.Printf("Migrated %s to version %d\n", path, config.CurrentVersion)
+		} else {
+			fmt.Printf("%s is already at version %d\n", path, config.CurrentVersion)
+		}
+	}
+	return nil
+}
+
+func runProfileCheck(cmd *cobra.Command, args []string) error {
+	statuses, err := profile.Check(profile.DefaultDir)
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		fmt.Println("No saved profiles to check.")
+		return nil
+	}
+
+	invalid := 0
+	for _, status := range statuses {
+		switch {
+		case !status.Valid():
+			fmt.Printf("%s: invalid\n", status.Path)
+			for _, problem := range status.Errors {
+				fmt.Printf("  - %v\n", problem)
+			}
+			if !checkQuarantine {
+				invalid++
+				continue
+			}
+			moved, err := profile.Quarantine(profile.DefaultDir, status.Path)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("  moved to %s\n", moved)
+		case status.Outdated && checkFix:
+			if _, err := config.MigrateFile(status.Path); err != nil {
+				return fmt.Errorf("%s: %w", status.Path, err)
+			}
+			fmt.Printf("%s: ok (migrated to version %d)\n", status.Path, config.CurrentVersion)
+		case status.Outdated:
+			fmt.Printf("%s: ok (older config format; --fix rewrites it)\n", status.Path)
+		default:
+			fmt.Printf("%s: ok\n", status.Path)
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d profiles are invalid (--quarantine moves them aside)", invalid, len(statuses))
+	}
+	return nil
+}
+
+func runProfileSetSecret(cmd *cobra.Command, args []string) error {
+	if secretField != "password" && secretField != "uri" {
+		return fmt.Errorf("invalid --field %q (use password or uri)", secretField)
+	}
+
+	path, err := profile.Path(profile.DefaultDir, args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("profile %q not found: %w", args[0], err)
+	}
+
+	secret, err := readSecret(fmt.Sprintf("%s for %s: ", secretField, args[0]))
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return fmt.Errorf("empty %s, nothing stored", secretField)
+	}
+
+	account := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".yaml"), ".yml")
+	if secretField == "uri" {
+		account += "-uri"
+	}
+	if err := config.StoreSecret(account, secret); err != nil {
+		return err
+	}
+
+	ref := config.KeychainRef(account)
+	if err := config.SetSecretRef(path, secretField, ref); err != nil {
+		return err
+	}
+	fmt.Printf("Stored %s in the keychain; %s now uses %s\n", secretField, path, ref)
+	return nil
+}
+
+// readSecret reads one line from stdin, without echo when it is a terminal.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		return string(secret), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
 func printBanner() {
 	fmt.Print(asciiBanner)
 	fmt.Println(appName)
@@ -11,12 +11,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/engine"
+	"github.com/kadirbelkuyu/DBRTS/internal/hook"
+	"github.com/kadirbelkuyu/DBRTS/internal/profile"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/interactive"
 
 	"gopkg.in/yaml.v3"
 )
 
-const defaultConfigDir = "configs"
+const defaultConfigDir = profile.DefaultDir
 
 type Application struct {
 	reader      *bufio.Reader
@@ -133,7 +139,25 @@ func (a *Application) handleTransfer() error {
 		return err
 	}
 
-	return RunTransfer(sourceCfg, targetCfg, schemaOnlyFlag, dataOnlyFlag, workers, batch, verboseFlag)
+	opts := transfer.Options{
+		SchemaOnly:      schemaOnlyFlag,
+		DataOnly:        dataOnlyFlag,
+		ParallelWorkers: workers,
+		BatchSize:       batch,
+	}
+
+	fmt.Println()
+	fmt.Print(BuildTransferSummary(sourceCfg, targetCfg, opts))
+	proceed, err := a.promptYesNo("Proceed with this transfer?", false)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Println("Transfer cancelled.")
+		return nil
+	}
+
+	return RunTransfer(sourceCfg, targetCfg, opts, hook.Hooks{}, "text", "", verboseFlag)
 }
 
 func (a *Application) handleBackup() error {
@@ -150,7 +174,7 @@ func (a *Application) handleBackup() error {
 		return err
 	}
 
-	return RunBackup(cfg, verboseFlag)
+	return RunBackup(cfg, backup.BackupOptions{}, interactive.BackupDefaults{}, hook.Hooks{}, "", verboseFlag)
 }
 
 func (a *Application) handleRestore() error {
@@ -167,7 +191,7 @@ func (a *Application) handleRestore() error {
 		return err
 	}
 
-	return RunRestore(cfg, verboseFlag)
+	return RunRestore(cfg, backup.RestoreOptions{IfExists: true}, false, hook.Hooks{}, verboseFlag)
 }
 
 func (a *Application) handleList() error {
@@ -273,6 +297,7 @@ func (a *Application) loadOrPromptConfig(label, expectedType string) (*config.Co
 			continue
 		}
 
+		a.warnPortCollisions(cfg)
 		if err := a.persistConfig(cfg); err != nil {
 			fmt.Printf("Warning: failed to save config: %v\n", err)
 		}
@@ -283,6 +308,7 @@ func (a *Application) loadOrPromptConfig(label, expectedType string) (*config.Co
 
 func (a *Application) promptManualConfig(dbType, label string) (*config.Config, error) {
 	cfg := &config.Config{
+		Version: config.CurrentVersion,
 		Database: config.DatabaseConfig{
 			Type: dbType,
 		},
@@ -420,28 +446,19 @@ func (a *Application) promptTransferOptions(dbType string) (bool, bool, int, int
 		err        error
 	)
 
-	if dbType == "mongo" {
-		schemaOnly, err = a.promptYesNo("Transfer indexes only (skip documents)?", false)
-		if err != nil {
-			return false, false, 0, 0, false, err
-		}
-		if !schemaOnly {
-			dataOnly, err = a.promptYesNo("Transfer documents only (skip indexes)?", false)
-			if err != nil {
-				return false, false, 0, 0, false, err
-			}
-		}
-	} else {
-		schemaOnly, err = a.promptYesNo("Transfer schema only?", false)
+	features, err := engine.Capabilities(dbType)
+	if err != nil {
+		return false, false, 0, 0, false, err
+	}
+	schemaOnly, err = a.promptYesNo(fmt.Sprintf("Transfer %s only (skip %s)?", features.SchemaNoun, features.DataNoun), false)
+	if err != nil {
+		return false, false, 0, 0, false, err
+	}
+	if !schemaOnly {
+		dataOnly, err = a.promptYesNo(fmt.Sprintf("Transfer %s only (skip %s)?", features.DataNoun, features.SchemaNoun), false)
 		if err != nil {
 			return false, false, 0, 0, false, err
 		}
-		if !schemaOnly {
-			dataOnly, err = a.promptYesNo("Transfer data only?", false)
-			if err != nil {
-				return false, false, 0, 0, false, err
-			}
-		}
 	}
 
 	workers, err := a.promptInt("Number of parallel workers", 4)
@@ -501,6 +518,7 @@ func (a *Application) readLine() (string, error) {
 type savedConfig struct {
 	path string
 	name string
+	cfg  *config.Config
 }
 
 func (a *Application) selectSavedConfig(expectedType string) *config.Config {
@@ -550,7 +568,7 @@ func (a *Application) findSavedConfigs(expectedType string) []savedConfig {
 
 	var configs []savedConfig
 	for _, entry := range dirEntries {
-		if entry.IsDir() {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
@@ -571,12 +589,32 @@ func (a *Application) findSavedConfigs(expectedType string) []savedConfig {
 		configs = append(configs, savedConfig{
 			path: path,
 			name: entry.Name(),
+			cfg:  cfg,
 		})
 	}
 
 	return configs
 }
 
+// warnPortCollisions flags a new connection whose host and port are already
+// used by another saved profile. It never blocks saving.
+func (a *Application) warnPortCollisions(cfg *config.Config) {
+	var existing []ProfileEndpoint
+	for _, saved := range a.findSavedConfigs("") {
+		existing = append(existing, ProfileEndpoint{
+			Name: saved.name,
+			Type: saved.cfg.Database.Type,
+			Host: saved.cfg.Database.Host,
+			Port: saved.cfg.Database.Port,
+		})
+	}
+
+	candidate := ProfileEndpoint{Type: cfg.Database.Type, Host: cfg.Database.Host, Port: cfg.Database.Port}
+	for _, other := range PortCollisions(existing, candidate) {
+		fmt.Printf("Warning: %s\n", CollisionWarning(candidate, other))
+	}
+}
+
 func (a *Application) persistConfig(cfg *config.Config) error {
 	save, err := a.promptYesNo("Save this configuration for future use?", true)
 	if err != nil || !save {
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// ProfileEndpoint is the server a saved profile points at.
+type ProfileEndpoint struct {
+	Name string
+	Type string
+	Host string
+	Port int
+}
+
+// PortCollisions returns the existing profiles that point at the candidate's
+// host and port under another name or engine. Sharing a port between
+// engines is almost always a copy-paste mistake; the same engine under
+// another name may be intended, so callers only warn.
+func PortCollisions(existing []ProfileEndpoint, candidate ProfileEndpoint) []ProfileEndpoint {
+	if candidate.Host == "" || candidate.Port == 0 {
+		return nil
+	}
+
+	var collisions []ProfileEndpoint
+	for _, other := range existing {
+		if other.Port != candidate.Port || config.NormalizeHost(other.Host) != config.NormalizeHost(candidate.Host) {
+			continue
+		}
+		if other.Name == candidate.Name && other.Type == candidate.Type {
+			continue
+		}
+		collisions = append(collisions, other)
+	}
+	return collisions
+}
+
+// CollisionWarning describes a collision for the person editing the profile.
+func CollisionWarning(candidate, other ProfileEndpoint) string {
+	address := fmt.Sprintf("%s:%d", candidate.Host, candidate.Port)
+	if other.Type != candidate.Type {
+		return fmt.Sprintf("%s is already used by %s profile %q; check the port of this %s profile", address, other.Type, other.Name, candidate.Type)
+	}
+	return fmt.Sprintf("%s is also used by profile %q", address, other.Name)
+}
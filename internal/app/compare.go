@@ -0,0 +1,227 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	CountMatch      = "match"
+	CountMismatch   = "mismatch"
+	CountSourceOnly = "source_only"
+	CountTargetOnly = "target_only"
+)
+
+// CountComparison is one table's row count on each side. A nil count means
+// the table does not exist on that side.
+type CountComparison struct {
+	Table      string `json:"table"`
+	SourceRows *int64 `json:"source_rows"`
+	TargetRows *int64 `json:"target_rows"`
+	Status     string `json:"status"`
+}
+
+// CompareCounts pairs tables by name and classifies each pair, sorted by name.
+func CompareCounts(source, target map[string]int64) []CountComparison {
+	names := make(map[string]bool, len(source)+len(target))
+	for name := range source {
+		names[name] = true
+	}
+	for name := range target {
+		names[name] = true
+	}
+
+	comparisons := make([]CountComparison, 0, len(names))
+	for name := range names {
+		comparison := CountComparison{Table: name}
+		if rows, ok := source[name]; ok {
+			comparison.SourceRows = &rows
+		}
+		if rows, ok := target[name]; ok {
+			comparison.TargetRows = &rows
+		}
+
+		switch {
+		case comparison.TargetRows == nil:
+			comparison.Status = CountSourceOnly
+		case comparison.SourceRows == nil:
+			comparison.Status = CountTargetOnly
+		case *comparison.SourceRows == *comparison.TargetRows:
+			comparison.Status = CountMatch
+		default:
+			comparison.Status = CountMismatch
+		}
+		comparisons = append(comparisons, comparison)
+	}
+
+	sort.Slice(comparisons, func(i, j int) bool { return comparisons[i].Table < comparisons[j].Table })
+	return comparisons
+}
+
+// WriteCountComparison prints the comparison as a table, or as JSON when
+// output is "json", and returns an error when any table differs.
+func WriteCountComparison(w io.Writer, comparisons []CountComparison, output string) error {
+	if output == "json" {
+		if err := writeJSON(w, comparisons); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "TABLE\tSOURCE\tTARGET\tSTATUS")
+		for _, comparison := range comparisons {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+				comparison.Table, countCell(comparison.SourceRows), countCell(comparison.TargetRows), comparison.Status)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	differing := 0
+	for _, comparison := range comparisons {
+		if comparison.Status != CountMatch {
+			differing++
+		}
+	}
+	if differing > 0 {
+		return fmt.Errorf("%d of %d tables differ", differing, len(comparisons))
+	}
+	return nil
+}
+
+func countCell(rows *int64) string {
+	if rows == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *rows)
+}
+
+// RunCompareCounts counts rows on both sides and reports the differences.
+// With estimate, planner statistics (PostgreSQL) or collection metadata
+// (MongoDB) are used instead of scanning.
+func RunCompareCounts(source, target *config.Config, estimate bool, output string) error {
+	if source.Database.Type != target.Database.Type {
+		return fmt.Errorf("cannot compare %s with %s", source.Database.Type, target.Database.Type)
+	}
+
+	sourceCounts, err := countTables(source, estimate)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	targetCounts, err := countTables(target, estimate)
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	return WriteCountComparison(os.Stdout, CompareCounts(sourceCounts, targetCounts), output)
+}
+
+func countTables(cfg *config.Config, estimate bool) (map[string]int64, error) {
+	switch cfg.Database.Type {
+	case "postgres":
+		return countPostgresTables(cfg, estimate)
+	case "mongo":
+		return countMongoCollections(cfg, estimate)
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Database.Type)
+	}
+}
+
+const postgresTableStatsQuery = `
+	SELECT n.nspname, c.relname, GREATEST(c.reltuples, 0)::bigint
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind IN ('r', 'p')
+	AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+	AND n.nspname NOT LIKE 'pg_toast%'
+	ORDER BY n.nspname, c.relname`
+
+func countPostgresTables(cfg *config.Config, estimate bool) (map[string]int64, error) {
+	conn, err := database.NewConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	rows, err := conn.DB.Query(postgresTableStatsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	type table struct{ schema, name string }
+	var tables []table
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var t table
+		var estimated int64
+		if err := rows.Scan(&t.schema, &t.name, &estimated); err != nil {
+			return nil, fmt.Errorf("failed to read table list: %w", err)
+		}
+		tables = append(tables, t)
+		counts[t.schema+"."+t.name] = estimated
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read table list: %w", err)
+	}
+
+	if estimate {
+		return counts, nil
+	}
+
+	for _, t := range tables {
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", schema.QuoteIdentifier(t.schema), schema.QuoteIdentifier(t.name))
+
+		var exact int64
+		if err := conn.DB.QueryRow(query).Scan(&exact); err != nil {
+			return nil, fmt.Errorf("failed to count %s.%s: %w", t.schema, t.name, err)
+		}
+		counts[t.schema+"."+t.name] = exact
+	}
+	return counts, nil
+}
+
+func countMongoCollections(cfg *config.Config, estimate bool) (map[string]int64, error) {
+	client, db, err := connectMongoDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnectMongo(client)
+
+	ctx := context.Background()
+	names, err := db.ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	counts := make(map[string]int64, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") {
+			continue
+		}
+		collection := db.Collection(name)
+
+		var count int64
+		if estimate {
+			count, err = collection.EstimatedDocumentCount(ctx)
+		} else {
+			count, err = collection.CountDocuments(ctx, bson.D{})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", name, err)
+		}
+		counts[name] = count
+	}
+	return counts, nil
+}
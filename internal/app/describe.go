@@ -0,0 +1,260 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/humanize"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const describeSampleSize = 100
+
+type CollectionDescription struct {
+	Name        string             `json:"name"`
+	Documents   int64              `json:"documents"`
+	Size        int64              `json:"size_bytes"`
+	StorageSize int64              `json:"storage_size_bytes"`
+	Fields      []FieldDescription `json:"fields"`
+	Indexes     []CollectionIndex  `json:"indexes"`
+}
+
+type FieldDescription struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+}
+
+type CollectionIndex struct {
+	Name       string `json:"name"`
+	Keys       string `json:"keys"`
+	Unique     bool   `json:"unique"`
+	Sparse     bool   `json:"sparse,omitempty"`
+	TTLSeconds *int32 `json:"ttl_seconds,omitempty"`
+}
+
+func DescribeTable(cfg *config.Config, qualifiedName, output string) error {
+	if cfg.Database.Type != "postgres" {
+		return fmt.Errorf("--table is only supported for PostgreSQL; use --collection for MongoDB")
+	}
+
+	schemaName, tableName := splitQualifiedName(qualifiedName)
+
+	conn, err := database.NewConnection(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	extractor := schema.NewExtractor(conn, logger.NewLogger(false))
+	table, err := extractor.ExtractTable(schemaName, tableName)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		return writeJSON(os.Stdout, table)
+	}
+
+	FormatTableDescription(os.Stdout, *table)
+	return nil
+}
+
+func DescribeCollection(cfg *config.Config, collectionName, output string) error {
+	if cfg.Database.Type != "mongo" {
+		return fmt.Errorf("--collection is only supported for MongoDB; use --table for PostgreSQL")
+	}
+
+	client, db, err := connectMongoDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer disconnectMongo(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := db.Collection(collectionName)
+	description := CollectionDescription{Name: collectionName}
+
+	var stats struct {
+		Count       int64 `bson:"count"`
+		Size        int64 `bson:"size"`
+		StorageSize int64 `bson:"storageSize"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "collStats", Value: collectionName}}).Decode(&stats); err != nil {
+		return fmt.Errorf("failed to read collection stats: %w", err)
+	}
+	description.Documents = stats.Count
+	description.Size = stats.Size
+	description.StorageSize = stats.StorageSize
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetLimit(describeSampleSize))
+	if err != nil {
+		return fmt.Errorf("failed to sample documents: %w", err)
+	}
+	var samples []bson.M
+	if err := cursor.All(ctx, &samples); err != nil {
+		return fmt.Errorf("failed to read sampled documents: %w", err)
+	}
+	description.Fields = InferFields(samples)
+
+	description.Indexes, err = listCollectionIndexes(ctx, collection)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		return writeJSON(os.Stdout, description)
+	}
+
+	FormatCollectionDescription(os.Stdout, description)
+	return nil
+}
+
+// InferFields collects the top-level field names seen across sampled documents
+// together with every BSON type observed for each field.
+func InferFields(documents []bson.M) []FieldDescription {
+	seen := make(map[string]map[string]bool)
+	for _, doc := range documents {
+		for name, value := range doc {
+			if seen[name] == nil {
+				seen[name] = make(map[string]bool)
+			}
+			seen[name][bsonTypeName(value)] = true
+		}
+	}
+
+	fields := make([]FieldDescription, 0, len(seen))
+	for name, types := range seen {
+		field := FieldDescription{Name: name}
+		for typeName := range types {
+			field.Types = append(field.Types, typeName)
+		}
+		sort.Strings(field.Types)
+		fields = append(fields, field)
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+func FormatTableDescription(w io.Writer, table schema.Table) {
+	fmt.Fprintf(w, "Table %s.%s (%d rows)\n", table.Schema, table.Name, table.RowCount)
+	fmt.Fprintln(w, strings.Repeat("=", 36))
+
+	fmt.Fprintln(w, "\nColumns:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tTYPE\tNULLABLE\tDEFAULT")
+	for _, col := range table.Columns {
+		dataType := col.DataType
+		if col.MaxLength != nil {
+			dataType = fmt.Sprintf("%s(%d)", dataType, *col.MaxLength)
+		}
+		defaultValue := ""
+		if col.DefaultValue != nil {
+			defaultValue = *col.DefaultValue
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", col.Name, dataType, yesNo(col.IsNullable), defaultValue)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nPrimary key: %s\n", displayValue(strings.Join(table.PrimaryKeys, ", "), "none"))
+
+	fmt.Fprintln(w, "\nIndexes:")
+	if len(table.Indexes) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, idx := range table.Indexes {
+		flags := idx.IndexType
+		if idx.IsPrimary {
+			flags += ", primary"
+		} else if idx.IsUnique {
+			flags += ", unique"
+		}
+		fmt.Fprintf(w, "  %s (%s) [%s]\n", idx.Name, strings.Join(idx.Columns, ", "), flags)
+	}
+
+	fmt.Fprintln(w, "\nForeign keys:")
+	if len(table.ForeignKeys) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, fk := range table.ForeignKeys {
+		fmt.Fprintf(w, "  %s: %s -> %s.%s(%s)\n", fk.Name, fk.ColumnName, fk.ReferencedSchema, fk.ReferencedTable, fk.ReferencedColumn)
+	}
+}
+
+func FormatCollectionDescription(w io.Writer, description CollectionDescription) {
+	fmt.Fprintf(w, "Collection %s (%d documents, %s, %s on disk)\n",
+		description.Name, description.Documents, humanize.Bytes(description.Size), humanize.Bytes(description.StorageSize))
+	fmt.Fprintln(w, strings.Repeat("=", 36))
+
+	fmt.Fprintf(w, "\nFields (sampled from up to %d documents):\n", describeSampleSize)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, field := range description.Fields {
+		fmt.Fprintf(tw, "  %s\t%s\n", field.Name, strings.Join(field.Types, " | "))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nIndexes:")
+	for _, idx := range description.Indexes {
+		unique := ""
+		if idx.Unique {
+			unique = " [unique]"
+		}
+		fmt.Fprintf(w, "  %s {%s}%s\n", idx.Name, idx.Keys, unique)
+	}
+}
+
+func splitQualifiedName(name string) (string, string) {
+	if schemaName, tableName, ok := strings.Cut(name, "."); ok {
+		return schemaName, tableName
+	}
+	return "public", name
+}
+
+func bsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case int32, int64:
+		return "int"
+	case float64:
+		return "double"
+	case bool:
+		return "bool"
+	case bson.M, bson.D:
+		return "object"
+	case bson.A:
+		return "array"
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", value), "primitive.")
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
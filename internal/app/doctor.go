@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+// DoctorResult is the outcome of checking one external tool against the server.
+type DoctorResult struct {
+	Tool  string
+	Check *backup.VersionCheck
+	Err   error
+}
+
+func (r DoctorResult) OK() bool {
+	return r.Err == nil && !r.Check.Skewed()
+}
+
+// RunDoctor checks every dump/restore tool DBRTS uses for the configured
+// engine against the server version and reports problems.
+func RunDoctor(cfg *config.Config, verboseFlag bool) error {
+	log := logger.NewLogger(verboseFlag)
+
+	service, err := backup.NewService(cfg, log)
+	if err != nil {
+		return fmt.Errorf("failed to initialize backup service: %w", err)
+	}
+	if err := service.Connect(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer service.Close()
+
+	var results []DoctorResult
+	for _, tool := range backup.ToolsFor(cfg.Database.Type) {
+		check, err := service.CheckVersion(tool)
+		results = append(results, DoctorResult{Tool: tool, Check: check, Err: err})
+	}
+
+	return WriteDoctorReport(os.Stdout, results)
+}
+
+// WriteDoctorReport prints one line per tool and returns an error when any
+// check failed or found version skew.
+func WriteDoctorReport(w io.Writer, results []DoctorResult) error {
+	problems := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			problems++
+			fmt.Fprintf(w, "[FAIL] %s: %v\n", result.Tool, result.Err)
+		case result.Check.Skewed():
+			problems++
+			fmt.Fprintf(w, "[WARN] %s\n", result.Check.Message())
+		default:
+			fmt.Fprintf(w, "[ OK ] %s\n", result.Check.Message())
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("%d of %d checks reported problems", problems, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+// DropDatabase drops the named database on the server cfg points at. Unless
+// assumeYes is set, the name must be typed again at a prompt read from in.
+func DropDatabase(cfg *config.Config, name string, assumeYes bool, in io.Reader, out io.Writer) error {
+	if err := backup.CheckDropDatabase(cfg.Database.Type, name); err != nil {
+		return err
+	}
+	if err := ConfirmDrop(name, formatServerLabel(cfg), assumeYes, in, out); err != nil {
+		return err
+	}
+
+	service, err := backup.NewService(cfg.WithPurpose("drop-database"), logger.NewLogger(false))
+	if err != nil {
+		return fmt.Errorf("failed to initialize backup service: %w", err)
+	}
+	// No Connect: the profile's own database may be the one being dropped,
+	// so DropDatabase opens the connection it needs.
+	defer service.Close()
+
+	if err := service.DropDatabase(name); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Dropped database %s on %s.\n", name, formatServerLabel(cfg))
+	return nil
+}
+
+// ConfirmDrop asks for the database name to be typed back, so a drop cannot
+// be confirmed by reflex. Anything else, including no input, refuses.
+func ConfirmDrop(name, server string, assumeYes bool, in io.Reader, out io.Writer) error {
+	if assumeYes {
+		return nil
+	}
+
+	fmt.Fprintf(out, "This permanently drops database %s on %s.\nType the database name to confirm: ", name, server)
+	typed, _ := bufio.NewReader(in).ReadString('\n')
+	if strings.TrimSpace(typed) != name {
+		return fmt.Errorf("confirmation did not match; database %s was not dropped", name)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// ShowDSN prints the connection target DBRTS derives from cfg, with secrets masked.
+func ShowDSN(cfg *config.Config) error {
+	return WriteDSN(os.Stdout, cfg)
+}
+
+// WriteDSN writes the redacted connection string or URI followed by the
+// resolved connection fields.
+func WriteDSN(w io.Writer, cfg *config.Config) error {
+	db := cfg.Database
+
+	switch db.Type {
+	case "postgres":
+		fmt.Fprintf(w, "Type:     postgres\n")
+		fmt.Fprintf(w, "DSN:      %s\n", cfg.RedactedConnectionString())
+		fmt.Fprintf(w, "Host:     %s\n", displayValue(db.Host, "(empty)"))
+		fmt.Fprintf(w, "Port:     %d\n", db.Port)
+		fmt.Fprintf(w, "Database: %s\n", displayValue(db.Database, "(empty)"))
+		fmt.Fprintf(w, "User:     %s\n", displayValue(db.Username, "(empty)"))
+		fmt.Fprintf(w, "SSL mode: %s\n", displayValue(db.SSLMode, "(empty)"))
+	case "mongo":
+		uri := cfg.GetMongoURI()
+		host, database := mongoURITarget(uri)
+		source := "built from host/port fields"
+		if db.URI != "" {
+			source = "uri field"
+		}
+
+		fmt.Fprintf(w, "Type:     mongo\n")
+		fmt.Fprintf(w, "URI:      %s\n", cfg.RedactedMongoURI())
+		fmt.Fprintf(w, "Source:   %s\n", source)
+		fmt.Fprintf(w, "Host:     %s\n", displayValue(host, "(unknown)"))
+		fmt.Fprintf(w, "Database: %s\n", displayValue(database, "(none)"))
+		fmt.Fprintf(w, "Auth DB:  %s\n", displayValue(db.AuthDatabase, "(default)"))
+	default:
+		return fmt.Errorf("unsupported database type: %s", db.Type)
+	}
+
+	return nil
+}
+
+func mongoURITarget(uri string) (host, database string) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", ""
+	}
+	return parsed.Host, strings.TrimPrefix(parsed.Path, "/")
+}
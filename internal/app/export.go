@@ -0,0 +1,330 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/pkg/format"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ExportOptions struct {
+	Format     string
+	Output     string
+	Limit      int64
+	Filter     string
+	LiteralIDs bool
+	Cell       format.CellOptions
+
+	// FlushEvery and BufferSize control output buffering (see RowWriter).
+	FlushEvery int
+	BufferSize int
+}
+
+// ExportCollection writes a MongoDB collection as a flat CSV file. Nested
+// documents become dot-notation columns and the header is the union of keys
+// across every exported document. Since the header depends on every
+// document, they are first spooled to a temporary file as raw BSON rather
+// than held in memory, then read back and written out.
+func ExportCollection(cfg *config.Config, collectionName string, opts ExportOptions) error {
+	if cfg.Database.Type != "mongo" {
+		return fmt.Errorf("--collection is only supported for MongoDB")
+	}
+	if opts.Format != "" && opts.Format != "csv" {
+		return fmt.Errorf("unsupported export format %q (expected csv)", opts.Format)
+	}
+
+	filter, err := ParseMongoFilter(opts.Filter, !opts.LiteralIDs)
+	if err != nil {
+		return err
+	}
+
+	client, db, err := connectMongoDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer disconnectMongo(client)
+
+	ctx := context.Background()
+	findOptions := options.Find()
+	if opts.Limit > 0 {
+		findOptions.SetLimit(opts.Limit)
+	}
+
+	cursor, err := db.Collection(collectionName).Find(ctx, filter, findOptions)
+	if err != nil {
+		return fmt.Errorf("failed to query collection: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	spool, err := os.CreateTemp("", "dbrts-export-*.bson")
+	if err != nil {
+		return fmt.Errorf("failed to create spool file: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	spoolWriter := bufio.NewWriter(spool)
+	keys := make(map[string]bool)
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode document: %w", err)
+		}
+		for key := range FlattenDocument(doc) {
+			keys[key] = true
+		}
+		if _, err := spoolWriter.Write(cursor.Current); err != nil {
+			return fmt.Errorf("failed to write spool file: %w", err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read collection: %w", err)
+	}
+	if err := spoolWriter.Flush(); err != nil {
+		return fmt.Errorf("failed to write spool file: %w", err)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read spool file: %w", err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if opts.Output != "" {
+		file, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer := NewRowWriter(out, opts.BufferSize, opts.FlushEvery)
+	spoolReader := bufio.NewReader(spool)
+	next := func() (map[string]interface{}, error) {
+		raw, err := readSpooledDocument(spoolReader)
+		if err != nil || raw == nil {
+			return nil, err
+		}
+		var doc bson.M
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+		return FlattenDocument(doc), nil
+	}
+
+	count, err := WriteCSVRows(writer, SortKeys(keys), next, opts.Cell)
+	if err != nil {
+		return err
+	}
+	if opts.Output != "" {
+		fmt.Printf("Exported %d documents to %s\n", count, opts.Output)
+	}
+	return nil
+}
+
+// readSpooledDocument reads the next length-prefixed BSON document, or nil at
+// the end of the spool file.
+func readSpooledDocument(r io.Reader) (bson.Raw, error) {
+	var length int32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read spool file: %w", err)
+	}
+	if length < 5 {
+		return nil, fmt.Errorf("spool file contains an invalid document length %d", length)
+	}
+
+	doc := make([]byte, length)
+	binary.LittleEndian.PutUint32(doc, uint32(length))
+	if _, err := io.ReadFull(r, doc[4:]); err != nil {
+		return nil, fmt.Errorf("failed to read spool file: %w", err)
+	}
+	return bson.Raw(doc), nil
+}
+
+// FlattenDocument turns nested documents into dot-notation keys. Arrays are
+// kept as a single JSON-encoded value.
+func FlattenDocument(doc bson.M) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flattenInto(flat, "", doc)
+	return flat
+}
+
+func flattenInto(flat map[string]interface{}, prefix string, doc bson.M) {
+	for key, value := range doc {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case bson.M:
+			flattenInto(flat, name, v)
+		case bson.D:
+			flattenInto(flat, name, documentToMap(v))
+		case bson.A:
+			flat[name] = encodeArray(v)
+		case []interface{}:
+			flat[name] = encodeArray(v)
+		default:
+			flat[name] = exportScalar(v)
+		}
+	}
+}
+
+// UnionKeys returns every key seen across rows, sorted, with _id first.
+func UnionKeys(rows []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for key := range row {
+			seen[key] = true
+		}
+	}
+	return SortKeys(seen)
+}
+
+// SortKeys orders a set of column names as UnionKeys does.
+func SortKeys(seen map[string]bool) []string {
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i] == "_id" || keys[j] == "_id" {
+			return keys[i] == "_id"
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// WriteFlatCSV writes flattened rows under a union-of-keys header. Fields a
+// document lacks are written as the configured NULL string.
+func WriteFlatCSV(w io.Writer, rows []map[string]interface{}, cell format.CellOptions) error {
+	i := 0
+	next := func() (map[string]interface{}, error) {
+		if i == len(rows) {
+			return nil, nil
+		}
+		i++
+		return rows[i-1], nil
+	}
+
+	writer := NewRowWriter(w, DefaultBufferSize, DefaultFlushEvery)
+	_, err := WriteCSVRows(writer, UnionKeys(rows), next, cell)
+	return err
+}
+
+// WriteCSVRows writes the header, then each row next returns until it returns
+// nil, and flushes the writer. It stops calling next at the first write
+// error, so a closed pipe ends the export instead of draining the source. It
+// returns the number of rows written.
+func WriteCSVRows(w *RowWriter, header []string, next func() (map[string]interface{}, error), cell format.CellOptions) (int, error) {
+	// w is at least as large as csv's own buffer, so the CSV writer writes
+	// straight into it and reports its write errors as they happen.
+	writer := csv.NewWriter(w.Writer)
+	if err := writer.Write(header); err != nil {
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	count := 0
+	record := make([]string, len(header))
+	for {
+		row, err := next()
+		if err != nil {
+			return count, err
+		}
+		if row == nil {
+			break
+		}
+
+		for i, key := range header {
+			record[i] = cell.Cell(row[key])
+		}
+		if err := writer.Write(record); err != nil {
+			return count, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+		count++
+		if err := w.EndRow(); err != nil {
+			return count, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return count, fmt.Errorf("failed to write CSV output: %w", err)
+	}
+	return count, nil
+}
+
+func encodeArray(values []interface{}) string {
+	normalized := make([]interface{}, len(values))
+	for i, value := range values {
+		normalized[i] = normalizeForJSON(value)
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	return string(data)
+}
+
+func normalizeForJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalizeForJSON(item)
+		}
+		return out
+	case bson.D:
+		return normalizeForJSON(documentToMap(v))
+	case bson.A:
+		return normalizeForJSON([]interface{}(v))
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeForJSON(item)
+		}
+		return out
+	default:
+		return exportScalar(v)
+	}
+}
+
+func documentToMap(doc bson.D) bson.M {
+	m := make(bson.M, len(doc))
+	for _, elem := range doc {
+		m[elem.Key] = elem.Value
+	}
+	return m
+}
+
+func exportScalar(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC()
+	case primitive.Decimal128:
+		return v.String()
+	case primitive.Binary:
+		return fmt.Sprintf("%x", v.Data)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var objectIDHex = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+
+// ParseMongoFilter decodes a query filter written as MongoDB extended JSON.
+// When coerceIDs is set, bare 24-character hex strings compared against _id
+// (directly or via $eq, $ne, $in, $nin, including inside $and/$or/$nor) are
+// turned into ObjectIDs, since typing {"$oid": ...} by hand is easy to forget.
+// Values that are already typed, such as {"$oid": ...}, are left alone.
+func ParseMongoFilter(text string, coerceIDs bool) (bson.D, error) {
+	if strings.TrimSpace(text) == "" {
+		return bson.D{}, nil
+	}
+
+	var filter bson.D
+	if err := bson.UnmarshalExtJSON([]byte(text), false, &filter); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	if coerceIDs {
+		filter = coerceFilterIDs(filter)
+	}
+	return filter, nil
+}
+
+func coerceFilterIDs(filter bson.D) bson.D {
+	for i, elem := range filter {
+		switch elem.Key {
+		case "_id":
+			filter[i].Value = coerceIDValue(elem.Value)
+		case "$and", "$or", "$nor":
+			if clauses, ok := elem.Value.(bson.A); ok {
+				for j, clause := range clauses {
+					if doc, ok := clause.(bson.D); ok {
+						clauses[j] = coerceFilterIDs(doc)
+					}
+				}
+			}
+		}
+	}
+	return filter
+}
+
+func coerceIDValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return toObjectID(v)
+	case bson.D:
+		for i, elem := range v {
+			switch elem.Key {
+			case "$eq", "$ne":
+				if s, ok := elem.Value.(string); ok {
+					v[i].Value = toObjectID(s)
+				}
+			case "$in", "$nin":
+				if values, ok := elem.Value.(bson.A); ok {
+					for j, item := range values {
+						if s, ok := item.(string); ok {
+							values[j] = toObjectID(s)
+						}
+					}
+				}
+			}
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func toObjectID(value string) interface{} {
+	if !objectIDHex.MatchString(value) {
+		return value
+	}
+	id, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return value
+	}
+	return id
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IndexSpec describes an index to create on a MongoDB collection. Keys use the
+// form "field:direction" separated by commas, e.g. "email:1,created_at:-1".
+type IndexSpec struct {
+	Name       string
+	Keys       string
+	Unique     bool
+	Sparse     bool
+	TTLSeconds int32
+}
+
+// ParseIndexKeys converts "field:direction" pairs into an ordered key document.
+// A missing direction means ascending; text, hashed, 2d and 2dsphere are kept
+// as strings.
+func ParseIndexKeys(keys string) (bson.D, error) {
+	var doc bson.D
+	for _, part := range strings.Split(keys, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field, direction, _ := strings.Cut(part, ":")
+		field = strings.TrimSpace(field)
+		direction = strings.TrimSpace(direction)
+		if field == "" {
+			return nil, fmt.Errorf("index key %q has no field name", part)
+		}
+
+		switch direction {
+		case "", "1":
+			doc = append(doc, bson.E{Key: field, Value: int32(1)})
+		case "-1":
+			doc = append(doc, bson.E{Key: field, Value: int32(-1)})
+		case "text", "hashed", "2d", "2dsphere":
+			doc = append(doc, bson.E{Key: field, Value: direction})
+		default:
+			return nil, fmt.Errorf("unsupported direction %q for index key %s", direction, field)
+		}
+	}
+
+	if len(doc) == 0 {
+		return nil, fmt.Errorf("at least one index key is required")
+	}
+	return doc, nil
+}
+
+// BuildIndexModel translates an IndexSpec into the driver's index model.
+func BuildIndexModel(spec IndexSpec) (mongo.IndexModel, error) {
+	keys, err := ParseIndexKeys(spec.Keys)
+	if err != nil {
+		return mongo.IndexModel{}, err
+	}
+
+	opts := options.Index()
+	if spec.Name != "" {
+		opts.SetName(spec.Name)
+	}
+	if spec.Unique {
+		opts.SetUnique(true)
+	}
+	if spec.Sparse {
+		opts.SetSparse(true)
+	}
+	if spec.TTLSeconds < 0 {
+		return mongo.IndexModel{}, fmt.Errorf("TTL must not be negative")
+	}
+	if spec.TTLSeconds > 0 {
+		if len(keys) != 1 {
+			return mongo.IndexModel{}, fmt.Errorf("TTL indexes must have exactly one key")
+		}
+		opts.SetExpireAfterSeconds(spec.TTLSeconds)
+	}
+
+	return mongo.IndexModel{Keys: keys, Options: opts}, nil
+}
+
+func ListIndexes(cfg *config.Config, collectionName string) error {
+	return withMongoCollection(cfg, collectionName, func(ctx context.Context, collection *mongo.Collection) error {
+		indexes, err := listCollectionIndexes(ctx, collection)
+		if err != nil {
+			return err
+		}
+		FormatIndexes(os.Stdout, indexes)
+		return nil
+	})
+}
+
+func CreateIndex(cfg *config.Config, collectionName string, spec IndexSpec) error {
+	model, err := BuildIndexModel(spec)
+	if err != nil {
+		return err
+	}
+
+	return withMongoCollection(cfg, collectionName, func(ctx context.Context, collection *mongo.Collection) error {
+		name, err := collection.Indexes().CreateOne(ctx, model)
+		if err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+		fmt.Printf("Created index %s on %s\n", name, collectionName)
+		return nil
+	})
+}
+
+func DropIndex(cfg *config.Config, collectionName, indexName string) error {
+	if indexName == "_id_" {
+		return fmt.Errorf("the _id_ index cannot be dropped")
+	}
+
+	return withMongoCollection(cfg, collectionName, func(ctx context.Context, collection *mongo.Collection) error {
+		if _, err := collection.Indexes().DropOne(ctx, indexName); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", indexName, err)
+		}
+		fmt.Printf("Dropped index %s from %s\n", indexName, collectionName)
+		return nil
+	})
+}
+
+func FormatIndexes(w io.Writer, indexes []CollectionIndex) {
+	for _, idx := range indexes {
+		var flags []string
+		if idx.Unique {
+			flags = append(flags, "unique")
+		}
+		if idx.Sparse {
+			flags = append(flags, "sparse")
+		}
+		if idx.TTLSeconds != nil {
+			flags = append(flags, "ttl "+strconv.Itoa(int(*idx.TTLSeconds))+"s")
+		}
+
+		suffix := ""
+		if len(flags) > 0 {
+			suffix = " [" + strings.Join(flags, ", ") + "]"
+		}
+		fmt.Fprintf(w, "%s {%s}%s\n", idx.Name, idx.Keys, suffix)
+	}
+}
+
+func withMongoCollection(cfg *config.Config, collectionName string, fn func(context.Context, *mongo.Collection) error) error {
+	if cfg.Database.Type != "mongo" {
+		return fmt.Errorf("index management is only supported for MongoDB")
+	}
+
+	client, db, err := connectMongoDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer disconnectMongo(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return fn(ctx, db.Collection(collectionName))
+}
+
+func listCollectionIndexes(ctx context.Context, collection *mongo.Collection) ([]CollectionIndex, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+
+	var raw []struct {
+		Name               string `bson:"name"`
+		Key                bson.D `bson:"key"`
+		Unique             bool   `bson:"unique"`
+		Sparse             bool   `bson:"sparse"`
+		ExpireAfterSeconds *int32 `bson:"expireAfterSeconds"`
+	}
+	if err := cursor.All(ctx, &raw); err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+
+	indexes := make([]CollectionIndex, 0, len(raw))
+	for _, idx := range raw {
+		keys := make([]string, len(idx.Key))
+		for i, key := range idx.Key {
+			keys[i] = fmt.Sprintf("%s:%v", key.Key, key.Value)
+		}
+		indexes = append(indexes, CollectionIndex{
+			Name:       idx.Name,
+			Keys:       strings.Join(keys, ", "),
+			Unique:     idx.Unique,
+			Sparse:     idx.Sparse,
+			TTLSeconds: idx.ExpireAfterSeconds,
+		})
+	}
+	return indexes, nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+)
+
+// InspectBackup prints what a backup contains without restoring it: the
+// pg_restore table of contents of a pg_dump archive, or the collections in a
+// mongodump archive.
+func InspectBackup(path, output string) error {
+	kind, err := backup.DetectArchive(path)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case backup.ArchivePostgres:
+		toc, err := backup.ListPostgresArchive(path)
+		if err != nil {
+			return err
+		}
+		if output == "json" {
+			return writeJSON(os.Stdout, toc)
+		}
+		return WritePostgresTOC(os.Stdout, toc)
+	case backup.ArchiveMongo:
+		archive, err := backup.InspectMongoArchive(path)
+		if err != nil {
+			return err
+		}
+		if output == "json" {
+			return writeJSON(os.Stdout, archive)
+		}
+		return WriteMongoArchive(os.Stdout, archive)
+	default:
+		return fmt.Errorf("%s looks like a plain SQL dump, which has no table of contents; read it directly", path)
+	}
+}
+
+// WritePostgresTOC prints the archive header, a count per object type and
+// every entry.
+func WritePostgresTOC(w io.Writer, toc *backup.PostgresTOC) error {
+	fmt.Fprintf(w, "Database: %s\n", displayValue(toc.Database, "n/a"))
+	fmt.Fprintf(w, "Format: %s, dumped from PostgreSQL %s by pg_dump %s\n",
+		displayValue(toc.Format, "n/a"), displayValue(toc.ServerVersion, "n/a"), displayValue(toc.DumpVersion, "n/a"))
+
+	counts := make(map[string]int)
+	for _, entry := range toc.Entries {
+		counts[entry.Type]++
+	}
+	types := make([]string, 0, len(counts))
+	for objectType := range counts {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+	summary := make([]string, len(types))
+	for i, objectType := range types {
+		summary[i] = fmt.Sprintf("%d %s", counts[objectType], objectType)
+	}
+	fmt.Fprintf(w, "Entries: %d (%s)\n\n", len(toc.Entries), strings.Join(summary, ", "))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTYPE\tSCHEMA\tNAME\tOWNER")
+	for _, entry := range toc.Entries {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n",
+			entry.ID, entry.Type, displayValue(entry.Schema, "-"), entry.Name, displayValue(entry.Owner, "-"))
+	}
+	return tw.Flush()
+}
+
+// WriteMongoArchive prints the archive header and its collections.
+func WriteMongoArchive(w io.Writer, archive *backup.MongoArchive) error {
+	fmt.Fprintf(w, "mongodump archive (format %s), dumped from MongoDB %s by mongodump %s\n",
+		displayValue(archive.FormatVersion, "n/a"), displayValue(archive.ServerVersion, "n/a"), displayValue(archive.ToolVersion, "n/a"))
+
+	databases := make(map[string]bool)
+	for _, collection := range archive.Collections {
+		databases[collection.Database] = true
+	}
+	fmt.Fprintf(w, "Databases: %d, collections: %d\n\n", len(databases), len(archive.Collections))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATABASE\tCOLLECTION\tTYPE")
+	for _, collection := range archive.Collections {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n",
+			collection.Database, collection.Collection, displayValue(collection.Type, "collection"))
+	}
+	return tw.Flush()
+}
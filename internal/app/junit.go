@@ -0,0 +1,164 @@
+package app
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+const (
+	junitTransferClass = "dbrts.transfer"
+	junitBackupClass   = "dbrts.backup"
+)
+
+// JUnitCase is one table or step of a run, reported to CI as a test case.
+type JUnitCase struct {
+	ClassName string
+	Name      string
+	Duration  time.Duration
+	Failure   string
+	Skipped   bool
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit renders cases as a single JUnit test suite, the format Jenkins
+// and GitLab read for test reports. The suite time is the sum of the cases.
+func WriteJUnit(w io.Writer, suite string, cases []JUnitCase) error {
+	out := junitTestSuite{Name: suite, Tests: len(cases), Cases: make([]junitTestCase, 0, len(cases))}
+
+	var total time.Duration
+	for _, c := range cases {
+		total += c.Duration
+		testCase := junitTestCase{ClassName: c.ClassName, Name: c.Name, Time: junitSeconds(c.Duration)}
+		switch {
+		case c.Failure != "":
+			out.Failures++
+			testCase.Failure = &junitFailure{Message: c.Failure, Text: c.Failure}
+		case c.Skipped:
+			out.Skipped++
+			testCase.Skipped = &struct{}{}
+		}
+		out.Cases = append(out.Cases, testCase)
+	}
+	out.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{out}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// TransferJUnitCases turns each table of a transfer report into a case. A
+// transfer that failed without recording a failed table, e.g. while creating
+// the schema, gets one extra failing case so CI still sees the error.
+func TransferJUnitCases(report *transfer.TransferReport, err error) []JUnitCase {
+	var cases []JUnitCase
+	failed := false
+	if report != nil {
+		for _, result := range report.Results() {
+			c := JUnitCase{ClassName: junitTransferClass, Name: result.QualifiedName(), Duration: result.Duration}
+			switch result.Status {
+			case transfer.StatusFailed:
+				failed = true
+				c.Failure = result.FirstError
+				if c.Failure == "" {
+					c.Failure = "transfer failed"
+				}
+			case transfer.StatusSkipped:
+				c.Skipped = true
+			}
+			cases = append(cases, c)
+		}
+	}
+
+	if err != nil && !failed {
+		cases = append(cases, JUnitCase{ClassName: junitTransferClass, Name: "transfer", Failure: err.Error()})
+	}
+	return cases
+}
+
+// junitSteps times the steps of a run that has no per-table report.
+type junitSteps struct {
+	className string
+	cases     []JUnitCase
+}
+
+func (s *junitSteps) run(name string, fn func() error) error {
+	started := time.Now()
+	err := fn()
+
+	c := JUnitCase{ClassName: s.className, Name: name, Duration: time.Since(started)}
+	if err != nil {
+		c.Failure = err.Error()
+	}
+	s.cases = append(s.cases, c)
+	return err
+}
+
+func (s *junitSteps) skip(name string) {
+	s.cases = append(s.cases, JUnitCase{ClassName: s.className, Name: name, Skipped: true})
+}
+
+// writeTransferJUnit writes the --junit-out report of a transfer. A report
+// that cannot be written is logged rather than failing the transfer.
+func writeTransferJUnit(path string, report *transfer.TransferReport, err error, log *logger.Logger) {
+	if path == "" {
+		return
+	}
+	if writeErr := writeJUnitFile(path, "dbrts transfer", TransferJUnitCases(report, err)); writeErr != nil {
+		log.Warnf("%v", writeErr)
+	}
+}
+
+func writeJUnitFile(path, suite string, cases []JUnitCase) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := WriteJUnit(file, suite, cases); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return file.Close()
+}
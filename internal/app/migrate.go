@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+// RunMigratePlan compares the source schema with the target and writes the
+// DDL that would bring the target in line to out, or stdout when out is
+// empty or "-". Nothing is executed on either side.
+func RunMigratePlan(source, target *config.Config, identifierCase, out string, verboseFlag bool) error {
+	if source.Database.Type != "postgres" || target.Database.Type != "postgres" {
+		return fmt.Errorf("migrate-plan is only supported between PostgreSQL databases")
+	}
+	if err := schema.ValidateIdentifierCase(identifierCase); err != nil {
+		return err
+	}
+	log := logger.NewLogger(verboseFlag)
+	if out == "" || out == "-" {
+		// Keep stdout clean for the script.
+		log.SetOutput(os.Stderr)
+	}
+
+	sourceObjects, err := extractSchemaObjects(source.WithPurpose("migrate-plan-source"), log, true)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	targetObjects, err := extractSchemaObjects(target.WithPurpose("migrate-plan-target"), log, false)
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	diff := schema.DiffSchema(sourceObjects, targetObjects, identifierCase)
+	creator := schema.NewCreator(nil, log, schema.CreateOptions{IdentifierCase: identifierCase})
+
+	var w io.Writer = os.Stdout
+	if out != "" && out != "-" {
+		file, err := os.Create(out)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	fmt.Fprintf(w, "-- Migration plan from %s/%s to %s/%s, generated by dbrts migrate-plan at %s.\n",
+		formatServerLabel(source), source.Database.Database, formatServerLabel(target), target.Database.Database,
+		time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintln(w, "-- Review it before applying, e.g. psql -v ON_ERROR_STOP=1 -f <file>.")
+	if err := creator.WriteMigrationScript(w, diff); err != nil {
+		return fmt.Errorf("failed to write migration plan: %w", err)
+	}
+
+	if w != os.Stdout {
+		log.Infof("Wrote migration plan to %s: %d new tables, %d added columns, %d new indexes, %d new foreign keys",
+			out, len(diff.NewTables), len(diff.AddedColumns), len(diff.NewIndexes), len(diff.NewForeignKeys))
+	}
+	return nil
+}
+
+// extractSchemaObjects reads the domains and tables of a database, and its
+// extensions and sequences when isSource is set.
+func extractSchemaObjects(cfg *config.Config, log *logger.Logger, isSource bool) (schema.Objects, error) {
+	conn, err := database.NewConnection(cfg)
+	if err != nil {
+		return schema.Objects{}, err
+	}
+	defer conn.Close()
+
+	extractor := schema.NewExtractor(conn, log)
+	var objects schema.Objects
+	if isSource {
+		if objects.Extensions, err = extractor.ExtractExtensions(); err != nil {
+			return schema.Objects{}, fmt.Errorf("failed to extract extensions: %w", err)
+		}
+		if objects.Sequences, err = extractor.ExtractSequences(); err != nil {
+			return schema.Objects{}, fmt.Errorf("failed to extract sequences: %w", err)
+		}
+	}
+	if objects.Domains, err = extractor.ExtractDomains(); err != nil {
+		return schema.Objects{}, fmt.Errorf("failed to extract domains: %w", err)
+	}
+	if objects.Tables, err = extractor.ExtractTables(""); err != nil {
+		return schema.Objects{}, fmt.Errorf("failed to extract tables: %w", err)
+	}
+	return objects, nil
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func connectMongoDatabase(cfg *config.Config) (*mongo.Client, *mongo.Database, error) {
+	if cfg.MongoDatabase() == "" {
+		return nil, nil, fmt.Errorf("a database name is required for MongoDB operations (set it in the config or pass --mongo-db)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := database.ConnectMongo(ctx, cfg, database.PingMongo, logger.NewLogger(false))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	return client, SelectMongoDatabase(client, cfg), nil
+}
+
+// SelectMongoDatabase returns the database the config's MongoDB operations
+// use, honouring a --mongo-db override.
+func SelectMongoDatabase(client *mongo.Client, cfg *config.Config) *mongo.Database {
+	return client.Database(cfg.MongoDatabase())
+}
+
+func disconnectMongo(client *mongo.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = client.Disconnect(ctx)
+}
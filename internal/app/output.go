@@ -0,0 +1,48 @@
+package app
+
+import (
+	"bufio"
+	"io"
+)
+
+// Output buffering defaults for export and query.
+const (
+	DefaultFlushEvery = 1000
+	DefaultBufferSize = 64 * 1024
+
+	// minBufferSize is the buffer encoding/csv allocates for itself. A
+	// RowWriter at least this large is shared with the CSV writer instead of
+	// being wrapped in a second buffer.
+	minBufferSize = 4096
+)
+
+// RowWriter buffers row output and flushes it every flushEvery rows, so a
+// reader on the other end of a pipe sees rows arrive steadily and memory use
+// stays at one buffer however large the result set. The buffer's errors are
+// sticky: once a write to the underlying writer fails, every later write
+// returns that error and the caller stops reading rows.
+type RowWriter struct {
+	*bufio.Writer
+	flushEvery int
+	rows       int
+}
+
+// NewRowWriter wraps w in a buffer of bufferSize bytes (at least 4 KiB). A
+// flushEvery of zero or less flushes only when the buffer is full.
+func NewRowWriter(w io.Writer, bufferSize, flushEvery int) *RowWriter {
+	if bufferSize < minBufferSize {
+		bufferSize = minBufferSize
+	}
+	return &RowWriter{Writer: bufio.NewWriterSize(w, bufferSize), flushEvery: flushEvery}
+}
+
+// EndRow marks the end of a row and flushes when flushEvery rows have been
+// written since the last flush.
+func (w *RowWriter) EndRow() error {
+	w.rows++
+	if w.flushEvery <= 0 || w.rows < w.flushEvery {
+		return nil
+	}
+	w.rows = 0
+	return w.Flush()
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/pkg/format"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type QueryOptions struct {
+	Output     string
+	Collection string
+	Limit      int64
+	LiteralIDs bool
+	// Cell, when set, writes PostgreSQL NULLs and booleans as the strings it
+	// formats instead of JSON null, true and false.
+	Cell *format.CellOptions
+
+	// FlushEvery and BufferSize control output buffering (see RowWriter).
+	FlushEvery int
+	BufferSize int
+}
+
+// RunQuery streams the result of a SQL statement, or a MongoDB find when a
+// collection is given, to stdout as one JSON object per line. A failed write,
+// such as a closed pipe, stops the query and is returned.
+func RunQuery(cfg *config.Config, statement string, opts QueryOptions) error {
+	if opts.Output != "" && opts.Output != "ndjson" {
+		return fmt.Errorf("unsupported query output %q (expected ndjson)", opts.Output)
+	}
+
+	out := NewRowWriter(os.Stdout, opts.BufferSize, opts.FlushEvery)
+
+	var err error
+	switch cfg.Database.Type {
+	case "postgres":
+		err = queryPostgres(cfg, statement, opts.Cell, out)
+	case "mongo":
+		if opts.Collection == "" {
+			return fmt.Errorf("--collection is required for MongoDB queries")
+		}
+		if opts.Cell != nil {
+			return fmt.Errorf("--null-string and --bool-format only apply to PostgreSQL queries")
+		}
+		err = queryMongo(cfg, statement, opts, out)
+	default:
+		return fmt.Errorf("unsupported database type: %s", cfg.Database.Type)
+	}
+	if flushErr := out.Flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("failed to write output: %w", flushErr)
+	}
+	return err
+}
+
+func queryPostgres(cfg *config.Config, statement string, cell *format.CellOptions, out *RowWriter) error {
+	conn, err := database.NewConnection(cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows, err := conn.DB.Query(statement)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	return StreamRowsNDJSON(out, rows, cell)
+}
+
+// StreamRowsNDJSON writes each row as soon as it is scanned, so memory use
+// does not grow with the size of the result set. It stops reading rows at the
+// first write error. With cell set, NULLs and booleans are formatted by it.
+func StreamRowsNDJSON(w *RowWriter, rows *sql.Rows, cell *format.CellOptions) error {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return fmt.Errorf("failed to read column metadata: %w", err)
+	}
+
+	columns := make([]string, len(columnTypes))
+	types := make([]string, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = ct.Name()
+		types[i] = ct.DatabaseTypeName()
+	}
+
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if cell != nil {
+			FormatNullsAndBools(values, *cell)
+		}
+		if err := WriteNDJSONRow(w, columns, types, values); err != nil {
+			return err
+		}
+		if err := w.EndRow(); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return rows.Err()
+}
+
+// FormatNullsAndBools replaces the NULL and boolean values of a row with the
+// strings cell renders them as, leaving every other value to the JSON encoder.
+func FormatNullsAndBools(values []interface{}, cell format.CellOptions) {
+	for i, value := range values {
+		switch value.(type) {
+		case nil, bool:
+			values[i] = cell.Cell(value)
+		}
+	}
+}
+
+// WriteNDJSONRow writes one row as a JSON object on its own line, keeping the
+// column order of the result set. NULL becomes null, bytea is written in
+// PostgreSQL's \x hex form, and numeric stays a JSON number.
+func WriteNDJSONRow(w io.Writer, columns, types []string, values []interface{}) error {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(column)
+		b.Write(key)
+		b.WriteByte(':')
+
+		typeName := ""
+		if i < len(types) {
+			typeName = types[i]
+		}
+		encoded, err := json.Marshal(ndjsonValue(values[i], typeName))
+		if err != nil {
+			return fmt.Errorf("failed to encode column %s: %w", column, err)
+		}
+		b.Write(encoded)
+	}
+	b.WriteString("}\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+func ndjsonValue(value interface{}, typeName string) interface{} {
+	raw, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+
+	switch strings.ToUpper(typeName) {
+	case "BYTEA":
+		return `\x` + hex.EncodeToString(raw)
+	case "NUMERIC":
+		return json.Number(raw)
+	case "JSON", "JSONB":
+		if json.Valid(raw) {
+			return json.RawMessage(raw)
+		}
+	}
+	return string(raw)
+}
+
+func queryMongo(cfg *config.Config, filterText string, opts QueryOptions, out *RowWriter) error {
+	filter, err := ParseMongoFilter(filterText, !opts.LiteralIDs)
+	if err != nil {
+		return err
+	}
+
+	client, db, err := connectMongoDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer disconnectMongo(client)
+
+	findOptions := options.Find()
+	if opts.Limit > 0 {
+		findOptions.SetLimit(opts.Limit)
+	}
+
+	ctx := context.Background()
+	cursor, err := db.Collection(opts.Collection).Find(ctx, filter, findOptions)
+	if err != nil {
+		return fmt.Errorf("failed to query collection: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		if err := WriteExtJSONLine(out, cursor.Current); err != nil {
+			return err
+		}
+		if err := out.EndRow(); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return cursor.Err()
+}
+
+// WriteExtJSONLine writes a raw BSON document as relaxed extended JSON on a
+// single line, so types such as ObjectId and dates survive the round trip.
+func WriteExtJSONLine(w io.Writer, doc bson.Raw) error {
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+)
+
+type transferReportJSON struct {
+	Tables []transfer.TableResult `json:"tables"`
+	Totals transfer.TableResult   `json:"totals"`
+}
+
+// WriteTransferReport prints the per-table outcome of a transfer as an
+// aligned table, or as JSON when output is "json".
+func WriteTransferReport(w io.Writer, report *transfer.TransferReport, output string) error {
+	if report == nil {
+		return nil
+	}
+
+	results := report.Results()
+	if output == "json" {
+		if results == nil {
+			results = []transfer.TableResult{}
+		}
+		return writeJSON(w, transferReportJSON{Tables: results, Totals: report.Totals()})
+	}
+
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No table data was transferred.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tSTATUS\tATTEMPTED\tSUCCEEDED\tFAILED\tROWS/S\tERROR")
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\t%s\n",
+			result.QualifiedName(), result.Status, result.RowsAttempted, result.RowsSucceeded, result.RowsFailed, rateCell(result), result.FirstError)
+	}
+	totals := report.Totals()
+	fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\t\n", "TOTAL", totals.Status, totals.RowsAttempted, totals.RowsSucceeded, totals.RowsFailed, rateCell(totals))
+	return tw.Flush()
+}
+
+func rateCell(result transfer.TableResult) string {
+	if result.Duration <= 0 {
+		return "-"
+	}
+	return transfer.FormatRate(result.RowsPerSecond)
+}
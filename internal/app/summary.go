@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+)
+
+// BuildTransferSummary renders the pre-flight description of a transfer that
+// is shown to the user before anything is written to the target.
+func BuildTransferSummary(sourceCfg, targetCfg *config.Config, opts transfer.Options) string {
+	var b strings.Builder
+
+	fmt.Fprintln(&b, "Transfer summary")
+	fmt.Fprintln(&b, strings.Repeat("-", 36))
+	fmt.Fprintf(&b, "Engine:  %s\n", sourceCfg.Database.Type)
+	fmt.Fprintf(&b, "Source:  %s/%s\n", formatServerLabel(sourceCfg), sourceCfg.Database.Database)
+	fmt.Fprintf(&b, "Target:  %s/%s\n", formatServerLabel(targetCfg), targetCfg.Database.Database)
+	fmt.Fprintf(&b, "Mode:    %s\n", transferMode(opts))
+	fmt.Fprintf(&b, "Workers: %d\n", opts.ParallelWorkers)
+	fmt.Fprintf(&b, "Batch:   %d\n", opts.BatchSize)
+
+	if opts.SplitThreshold > 0 {
+		fmt.Fprintf(&b, "Split tables above: %d rows\n", opts.SplitThreshold)
+	}
+	if opts.IdentifierCase != "" && opts.IdentifierCase != "preserve" {
+		fmt.Fprintf(&b, "Identifier case: %s\n", opts.IdentifierCase)
+	}
+
+	if opts.DisableTriggers {
+		fmt.Fprintln(&b, "Target triggers: disabled during load")
+	}
+	if len(opts.ExcludeColumns) > 0 {
+		fmt.Fprintf(&b, "Excluded columns: %s\n", strings.Join(opts.ExcludeColumns, ", "))
+	}
+	if len(opts.Transforms) > 0 {
+		fmt.Fprintf(&b, "Transforms: %s\n", strings.Join(transformSpecs(opts.Transforms), ", "))
+	}
+	if len(opts.MongoTransforms) > 0 {
+		fmt.Fprintf(&b, "Field transforms: %s\n", strings.Join(transformSpecs(opts.MongoTransforms), ", "))
+	}
+	if flags := enabledOptions(opts); len(flags) > 0 {
+		fmt.Fprintf(&b, "Options: %s\n", strings.Join(flags, ", "))
+	}
+
+	if warning := overwriteWarning(sourceCfg.Database.Type, opts); warning != "" {
+		fmt.Fprintf(&b, "\nWARNING: %s\n", warning)
+	}
+
+	return b.String()
+}
+
+func transferMode(opts transfer.Options) string {
+	switch {
+	case opts.SchemaOnly && !opts.DataOnly:
+		return "schema only"
+	case opts.DataOnly && !opts.SchemaOnly:
+		return "data only"
+	default:
+		return "full (schema + data)"
+	}
+}
+
+// enabledOptions lists the boolean options that change what is written.
+func enabledOptions(opts transfer.Options) []string {
+	var flags []string
+	for _, option := range []struct {
+		enabled bool
+		name    string
+	}{
+		{opts.ViaDump, "via pg_dump | pg_restore"},
+		{opts.SchemaDiff, "schema diff"},
+		{opts.PreserveStorage, "preserve storage"},
+		{opts.ConsistentSnapshot, "consistent snapshot"},
+		{opts.Append, "append"},
+		{opts.RegenerateIDs, "regenerate _ids"},
+		{opts.VerifyChecksums, "verify checksums"},
+	} {
+		if option.enabled {
+			flags = append(flags, option.name)
+		}
+	}
+	return flags
+}
+
+func transformSpecs(transforms map[string]string) []string {
+	specs := make([]string, 0, len(transforms))
+	for column, spec := range transforms {
+		specs = append(specs, column+":"+spec)
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+func overwriteWarning(dbType string, opts transfer.Options) string {
+	if dbType == "mongo" {
+		if opts.Append {
+			return "documents are added to existing target collections; duplicate _ids are skipped."
+		}
+		return "existing target collections with the same names will be dropped before copying."
+	}
+	if !opts.SchemaOnly {
+		return "rows are inserted into existing target tables; conflicting rows are skipped."
+	}
+	return ""
+}
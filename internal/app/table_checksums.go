@@ -0,0 +1,137 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+// ComputeTableChecksums digests every table of a PostgreSQL database the way
+// transfer --verify-checksums does. Tables the dump excluded are left out.
+// The rows are read after the dump, so the checksums match the backup only
+// when nothing wrote to the database in between.
+func ComputeTableChecksums(cfg *config.Config, databaseName string, options backup.BackupOptions, log *logger.Logger) (*backup.TableChecksums, error) {
+	dbCfg := *cfg
+	dbCfg.Database.Database = databaseName
+
+	conn, err := database.NewConnection(&dbCfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tables, err := schema.NewExtractor(conn, log).ExtractTables("")
+	if err != nil {
+		return nil, err
+	}
+
+	checksums := &backup.TableChecksums{
+		Database:  databaseName,
+		CreatedAt: time.Now().UTC(),
+		Tables:    make(map[string]backup.TableChecksum),
+	}
+	for _, table := range tables {
+		if excludedFromDump(table, options) {
+			continue
+		}
+		name := table.Schema + "." + table.Name
+
+		key, _ := transfer.OrderKey(table)
+		if len(key) == 0 {
+			log.Warnf("Checksum skipped for %s: no primary key or unique index to order rows by", name)
+			checksums.Skipped = append(checksums.Skipped, name)
+			continue
+		}
+		table.PrimaryKeys = key
+
+		sum, rows, err := transfer.TableChecksum(conn, transfer.BuildChecksumQuery(table, ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum table %s: %w", name, err)
+		}
+		checksums.Tables[name] = backup.TableChecksum{Rows: rows, Checksum: sum}
+	}
+	return checksums, nil
+}
+
+// excludedFromDump approximates pg_dump's --exclude-table and
+// --exclude-schema matching with shell-style wildcards. A table pattern
+// without a schema matches the table in any schema.
+func excludedFromDump(table schema.Table, options backup.BackupOptions) bool {
+	for _, pattern := range options.ExcludeSchemas {
+		if matched, _ := path.Match(pattern, table.Schema); matched {
+			return true
+		}
+	}
+	for _, pattern := range options.ExcludeTables {
+		name := table.Name
+		if strings.Contains(pattern, ".") {
+			name = table.Schema + "." + table.Name
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffBackupChecksums prints the tables whose content differs between two
+// backups taken with --table-checksums. Each argument is a backup or its
+// .checksums.json file.
+func DiffBackupChecksums(olderPath, newerPath, output string) error {
+	older, err := backup.ReadTableChecksums(checksumsFileFor(olderPath))
+	if err != nil {
+		return err
+	}
+	newer, err := backup.ReadTableChecksums(checksumsFileFor(newerPath))
+	if err != nil {
+		return err
+	}
+
+	changes := backup.DiffTableChecksums(older, newer)
+	if output == "json" {
+		if changes == nil {
+			changes = []backup.TableChange{}
+		}
+		return writeJSON(os.Stdout, changes)
+	}
+	return WriteTableChanges(os.Stdout, changes)
+}
+
+func checksumsFileFor(backupPath string) string {
+	if strings.HasSuffix(backupPath, ".checksums.json") {
+		return backupPath
+	}
+	return backup.ChecksumsPath(strings.TrimSuffix(backupPath, string(os.PathSeparator)))
+}
+
+func WriteTableChanges(w io.Writer, changes []backup.TableChange) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No table content changed.")
+		return err
+	}
+
+	rowCount := func(rows int64) string {
+		if rows < 0 {
+			return "-"
+		}
+		return fmt.Sprint(rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tCHANGE\tOLD ROWS\tNEW ROWS")
+	for _, change := range changes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", change.Table, change.Change, rowCount(change.OldRows), rowCount(change.NewRows))
+	}
+	return tw.Flush()
+}
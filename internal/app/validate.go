@@ -0,0 +1,30 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// WriteValidationReport prints one line per config check and returns an
+// error when a required check failed. Failed optional checks are warnings.
+func WriteValidationReport(w io.Writer, checks []config.ValidationCheck) error {
+	failed := 0
+	for _, check := range checks {
+		switch {
+		case check.Passed():
+			fmt.Fprintf(w, "[ OK ] %s: %s\n", check.Field, check.Detail)
+		case check.Required:
+			failed++
+			fmt.Fprintf(w, "[FAIL] %s: %v\n", check.Field, check.Err)
+		default:
+			fmt.Fprintf(w, "[WARN] %s: %v\n", check.Field, check.Err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/pkg/humanize"
+)
+
+// VerifyBackup checks a backup against the checksum in its sidecar and
+// prints the outcome. The returned error wraps backup.ErrChecksumMismatch or
+// backup.ErrBackupMissing so the caller can choose an exit code.
+func VerifyBackup(w io.Writer, path string) error {
+	result, err := backup.VerifyBackup(path)
+	if result == nil {
+		return err
+	}
+	return WriteVerifyResult(w, result, err)
+}
+
+// WriteVerifyResult prints one line for the backup and, for a directory
+// backup that changed, one line per affected file.
+func WriteVerifyResult(w io.Writer, result *backup.VerifyResult, err error) error {
+	if result.OK() {
+		fmt.Fprintf(w, "[ OK ] %s: checksum matches (sha256 %s, %s)\n", result.Path, shortChecksum(result.Actual), humanize.Bytes(result.Size))
+		return err
+	}
+
+	fmt.Fprintf(w, "[FAIL] %s: checksum mismatch (recorded %s, now %s)\n", result.Path, shortChecksum(result.Expected), shortChecksum(result.Actual))
+	for _, name := range result.Changed {
+		fmt.Fprintf(w, "       changed: %s\n", name)
+	}
+	for _, name := range result.Missing {
+		fmt.Fprintf(w, "       missing: %s\n", name)
+	}
+	for _, name := range result.Added {
+		fmt.Fprintf(w, "       added:   %s\n", name)
+	}
+	return err
+}
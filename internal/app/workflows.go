@@ -2,98 +2,189 @@ package app
 
 import (
 	"fmt"
+	"os"
 	"strings"
-	"time"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/backup"
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/hook"
 	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/humanize"
 	"github.com/kadirbelkuyu/DBRTS/pkg/interactive"
 	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+	"github.com/kadirbelkuyu/DBRTS/pkg/progress"
 )
 
-func RunTransfer(sourceCfg, targetCfg *config.Config, schemaOnly, dataOnly bool, workers, batch int, verboseFlag bool) error {
-	if schemaOnly && dataOnly {
+// RunTransfer copies source into target. When junitOut is set, the per-table
+// outcome is also written there as a JUnit XML report.
+func RunTransfer(sourceCfg, targetCfg *config.Config, opts transfer.Options, hooks hook.Hooks, output, junitOut string, verboseFlag bool) error {
+	if opts.SchemaOnly && opts.DataOnly {
 		fmt.Println("Both schema-only and data-only were selected. Running a full transfer instead.")
-		schemaOnly = false
-		dataOnly = false
+		opts.SchemaOnly = false
+		opts.DataOnly = false
 	}
 
 	log := logger.NewLogger(verboseFlag)
+	if output == "json" {
+		// Keep stdout clean for the JSON report.
+		log.SetOutput(os.Stderr)
+		progress.SetOutput(os.Stderr)
+	}
 	log.Logger.Info("Starting data transfer...")
 
-	opts := transfer.Options{
-		SchemaOnly:      schemaOnly,
-		DataOnly:        dataOnly,
-		ParallelWorkers: workers,
-		BatchSize:       batch,
-		Logger:          log,
-	}
+	opts.Logger = log
+	hooks.Logger = log
 
-	service, err := transfer.NewService(sourceCfg, targetCfg, opts)
+	service, err := transfer.NewService(sourceCfg.WithPurpose("transfer-source"), targetCfg.WithPurpose("transfer-target"), opts)
 	if err != nil {
+		writeTransferJUnit(junitOut, nil, err, log)
 		return fmt.Errorf("failed to initialize transfer service: %w", err)
 	}
 
-	if err := service.Execute(); err != nil {
+	var report *transfer.TransferReport
+	err = hooks.Around("transfer", targetCfg.Database.Database, func() (string, error) {
+		var execErr error
+		report, execErr = service.Execute()
+		return "", execErr
+	})
+
+	if reportErr := WriteTransferReport(os.Stdout, report, output); reportErr != nil {
+		log.Warnf("failed to write transfer report: %v", reportErr)
+	}
+	writeTransferJUnit(junitOut, report, err, log)
+	if err != nil {
 		return fmt.Errorf("transfer execution failed: %w", err)
 	}
 
+	log.Logger.Info(transfer.Summary(report.Totals()))
 	log.Logger.Info("Data transfer completed successfully!")
 	return nil
 }
 
-func RunBackup(cfg *config.Config, verboseFlag bool) error {
+// RunBackup backs up a database chosen interactively; defaults are the format
+// and compression its prompt suggests. When junitOut is set, its steps are
+// also written there as a JUnit XML report.
+func RunBackup(cfg *config.Config, flags backup.BackupOptions, defaults interactive.BackupDefaults, hooks hook.Hooks, junitOut string, verboseFlag bool) error {
+	if flags.TableChecksums && cfg.Database.Type != "postgres" {
+		return fmt.Errorf("--table-checksums is only supported for PostgreSQL")
+	}
+
 	log := logger.NewLogger(verboseFlag)
+	hooks.Logger = log
 	log.Logger.Info("Starting backup...")
 
-	service, err := backup.NewService(cfg, log)
+	steps := &junitSteps{className: junitBackupClass}
+	if junitOut != "" {
+		defer func() {
+			if err := writeJUnitFile(junitOut, "dbrts backup", steps.cases); err != nil {
+				log.Warnf("%v", err)
+			}
+		}()
+	}
+
+	service, err := backup.NewService(cfg.WithPurpose("backup"), log)
 	if err != nil {
 		return fmt.Errorf("failed to initialize backup service: %w", err)
 	}
-	if err := service.Connect(); err != nil {
+	if err := steps.run("connect", service.Connect); err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer service.Close()
 
-	databases, err := service.ListDatabases()
+	var databases []backup.DatabaseInfo
+	err = steps.run("list databases", func() error {
+		databases, err = service.ListDatabases()
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list databases: %w", err)
 	}
 
-	selector := interactive.NewDatabaseSelector(cfg.Database.Type)
+	selector := interactive.NewDatabaseSelector(cfg.Database.Type).WithFormat(flags.Format).WithDefaults(defaults)
 	selected, err := selector.SelectDatabase(databases)
 	if err != nil {
 		return fmt.Errorf("database selection failed: %w", err)
 	}
 
 	if !selector.ConfirmAction("Backup", selected.Name) {
+		steps.skip("backup " + selected.Name)
 		log.Logger.Info("Operation cancelled by user.")
 		return nil
 	}
 
 	options := selector.GetBackupOptions(cfg.Database.Type)
+	applyBackupFlags(&options, flags)
 
-	metadata, err := service.CreateBackup(selected.Name, options)
+	var metadata *backup.BackupMetadata
+	err = steps.run("backup "+selected.Name, func() error {
+		return hooks.Around("backup", selected.Name, func() (string, error) {
+			var backupErr error
+			metadata, backupErr = service.CreateBackup(selected.Name, options)
+			if backupErr != nil {
+				return "", backupErr
+			}
+			return metadata.Location, nil
+		})
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
+	if options.TableChecksums {
+		err = steps.run("table checksums", func() error {
+			checksums, err := ComputeTableChecksums(cfg.WithPurpose("backup"), selected.Name, options, log)
+			if err != nil {
+				return err
+			}
+			path := backup.ChecksumsPath(metadata.Location)
+			if err := backup.WriteTableChecksums(path, checksums); err != nil {
+				return err
+			}
+			metadata.ChecksumsPath = path
+			// Record the checksum file in the backup's sidecar too.
+			return backup.WriteMetadata(backup.MetadataPath(metadata.Location), metadata)
+		})
+		if err != nil {
+			return fmt.Errorf("backup written to %s but table checksums failed: %w", metadata.Location, err)
+		}
+	}
+
+	entry := backup.RegistryEntry{
+		Engine:    cfg.Database.Type,
+		Database:  selected.Name,
+		Path:      metadata.Location,
+		Size:      metadata.BackupSize,
+		Checksum:  metadata.Checksum,
+		CreatedAt: metadata.CompletedAt,
+		Globals:   metadata.GlobalsPath,
+		Checksums: metadata.ChecksumsPath,
+	}
+	if err := backup.RecordBackup(backup.DefaultRegistryPath, entry); err != nil {
+		log.Logger.Warnf("Backup finished but could not be recorded in the registry: %v", err)
+	}
+
 	fmt.Println()
 	fmt.Println("Backup completed successfully.")
 	fmt.Printf("File: %s\n", metadata.Location)
-	fmt.Printf("Size: %d bytes\n", metadata.BackupSize)
+	if metadata.GlobalsPath != "" {
+		fmt.Printf("Globals: %s\n", metadata.GlobalsPath)
+	}
+	if metadata.ChecksumsPath != "" {
+		fmt.Printf("Table checksums: %s\n", metadata.ChecksumsPath)
+	}
+	fmt.Printf("Size: %s\n", humanize.Bytes(metadata.BackupSize))
 	fmt.Printf("Checksum: %s\n", shortChecksum(metadata.Checksum))
-	fmt.Printf("Duration: %s\n", metadata.CompletedAt.Sub(metadata.StartedAt).Round(time.Second))
+	fmt.Printf("Duration: %s\n", humanize.Duration(metadata.CompletedAt.Sub(metadata.StartedAt)))
 
 	return nil
 }
 
-func RunRestore(cfg *config.Config, verboseFlag bool) error {
+func RunRestore(cfg *config.Config, flags backup.RestoreOptions, fromRegistry bool, hooks hook.Hooks, verboseFlag bool) error {
 	log := logger.NewLogger(verboseFlag)
+	hooks.Logger = log
 	log.Logger.Info("Starting restore...")
 
-	service, err := backup.NewService(cfg, log)
+	service, err := backup.NewService(cfg.WithPurpose("restore"), log)
 	if err != nil {
 		return fmt.Errorf("failed to initialize backup service: %w", err)
 	}
@@ -103,14 +194,31 @@ func RunRestore(cfg *config.Config, verboseFlag bool) error {
 	defer service.Close()
 
 	selector := interactive.NewDatabaseSelector(cfg.Database.Type)
-	options := selector.GetRestoreOptions(cfg.Database.Type)
+
+	var chosen *backup.RegistryEntry
+	if fromRegistry {
+		entries, err := backup.ListBackups(backup.DefaultRegistryPath, cfg.Database.Type)
+		if err != nil {
+			return err
+		}
+		chosen, err = selector.SelectBackup(entries)
+		if err != nil {
+			return fmt.Errorf("backup selection failed: %w", err)
+		}
+	}
+
+	options := selector.GetRestoreOptionsFrom(cfg.Database.Type, chosen)
+	applyRestoreFlags(&options, flags)
 
 	if !selector.ConfirmAction("Restore", options.TargetDatabase) {
 		log.Logger.Info("Operation cancelled by user.")
 		return nil
 	}
 
-	if err := service.RestoreBackup(options); err != nil {
+	err = hooks.Around("restore", options.TargetDatabase, func() (string, error) {
+		return options.BackupPath, service.RestoreBackup(options)
+	})
+	if err != nil {
 		return fmt.Errorf("restore failed: %w", err)
 	}
 
@@ -159,6 +267,40 @@ func ListDatabases(cfg *config.Config) error {
 	return nil
 }
 
+// applyBackupFlags copies options that are only configurable through CLI flags
+// onto the interactively collected backup options.
+func applyBackupFlags(options *backup.BackupOptions, flags backup.BackupOptions) {
+	if flags.ReadPreference != "" {
+		options.ReadPreference = flags.ReadPreference
+	}
+	options.ExtraArgs = flags.ExtraArgs
+	options.StrictVersion = flags.StrictVersion
+	options.DumpGlobals = flags.DumpGlobals
+	options.FilenameTemplate = flags.FilenameTemplate
+	if flags.Jobs > 0 {
+		options.Jobs = flags.Jobs
+	}
+	if len(flags.ExcludeTables) > 0 {
+		options.ExcludeTables = flags.ExcludeTables
+	}
+	if len(flags.ExcludeSchemas) > 0 {
+		options.ExcludeSchemas = flags.ExcludeSchemas
+	}
+	options.TableChecksums = flags.TableChecksums
+}
+
+// applyRestoreFlags copies options that are only configurable through CLI
+// flags onto the interactively collected restore options.
+func applyRestoreFlags(options *backup.RestoreOptions, flags backup.RestoreOptions) {
+	options.ExtraArgs = flags.ExtraArgs
+	options.StrictVersion = flags.StrictVersion
+	options.IfExists = flags.IfExists
+	options.ApplyGlobals = flags.ApplyGlobals
+	options.AtomicSwap = flags.AtomicSwap
+	options.TolerateExisting = flags.TolerateExisting
+	options.Jobs = flags.Jobs
+}
+
 func shortChecksum(checksum string) string {
 	if len(checksum) <= 16 {
 		return checksum
@@ -170,7 +312,7 @@ func formatServerLabel(cfg *config.Config) string {
 	host := strings.TrimSpace(cfg.Database.Host)
 	if host == "" {
 		if cfg.Database.URI != "" {
-			return cfg.Database.URI
+			return config.RedactURI(cfg.Database.URI)
 		}
 		host = "localhost"
 	}
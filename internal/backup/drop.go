@@ -0,0 +1,23 @@
+package backup
+
+import "fmt"
+
+// protectedDatabases are never dropped: the databases DBRTS and the server
+// itself connect to for administration.
+var protectedDatabases = map[string][]string{
+	"postgres": {"postgres", "template0", "template1"},
+	"mongo":    {"admin", "local", "config"},
+}
+
+// CheckDropDatabase refuses to drop an unnamed or system database.
+func CheckDropDatabase(dbType, name string) error {
+	if name == "" {
+		return fmt.Errorf("no database named to drop")
+	}
+	for _, protected := range protectedDatabases[dbType] {
+		if name == protected {
+			return fmt.Errorf("refusing to drop the %s system database %s", dbType, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// managedFlags lists the options DBRTS always sets for each tool. Passing them
+// again through ExtraArgs would fight the generated values, so they are rejected.
+var managedFlags = map[string][]string{
+	"pg_dump":      {"--host", "-h", "--port", "-p", "--username", "-U", "--dbname", "-d", "--file", "-f", "--format", "-F"},
+	"pg_restore":   {"--host", "-h", "--port", "-p", "--username", "-U", "--dbname", "-d"},
+	"psql":         {"--host", "-h", "--port", "-p", "--username", "-U", "--dbname", "-d", "--file", "-f"},
+	"mongodump":    {"--uri", "--archive", "--db", "-d", "--host", "--port", "--username", "-u", "--password", "-p"},
+	"mongorestore": {"--uri", "--archive", "--host", "--port", "--username", "-u", "--password", "-p"},
+}
+
+// ValidateExtraArgs rejects extra arguments that would override connection or
+// output flags DBRTS generates for tool. Everything else is appended verbatim
+// after the generated arguments, so for repeated options the extra value wins.
+func ValidateExtraArgs(tool string, args []string) error {
+	for _, arg := range args {
+		for _, flag := range managedFlags[tool] {
+			if matchesFlag(arg, flag) {
+				return fmt.Errorf("extra argument %q conflicts with %s, which DBRTS sets for %s", arg, flag, tool)
+			}
+		}
+	}
+	return nil
+}
+
+func matchesFlag(arg, flag string) bool {
+	if arg == flag || strings.HasPrefix(arg, flag+"=") {
+		return true
+	}
+	// Short options also accept an attached value, e.g. -h127.0.0.1.
+	return !strings.HasPrefix(flag, "--") && !strings.HasPrefix(arg, "--") && strings.HasPrefix(arg, flag)
+}
@@ -0,0 +1,55 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultFilenameTemplate produces the historical names, e.g.
+// shop_20240102_150405.dump.
+const DefaultFilenameTemplate = "{db}_20060102_150405"
+
+// filenameDBToken is replaced with the database name. Everything else in a
+// template is a Go time layout, so literal text must avoid layout elements
+// such as 2006, 01 or Jan.
+const filenameDBToken = "{db}"
+
+// RenderFilename expands a filename template for a backup of databaseName
+// taken at at, and appends the format's extension. An empty template uses
+// DefaultFilenameTemplate. The result must be a plain, portable file name.
+func RenderFilename(template, databaseName string, at time.Time, extension string) (string, error) {
+	if template == "" {
+		template = DefaultFilenameTemplate
+	}
+
+	// The database name is substituted after formatting so that names
+	// containing layout elements are not rewritten.
+	parts := strings.Split(template, filenameDBToken)
+	for i, part := range parts {
+		parts[i] = at.Format(part)
+	}
+	name := strings.Join(parts, databaseName) + extension
+
+	if err := validateFilename(name); err != nil {
+		return "", fmt.Errorf("invalid filename template %q: %w", template, err)
+	}
+	return name, nil
+}
+
+// validateFilename rejects names that are not safe on every platform the
+// backups may be copied to.
+func validateFilename(name string) error {
+	if strings.TrimSpace(name) == "" || name == "." || name == ".." {
+		return fmt.Errorf("renders an empty file name")
+	}
+	if strings.HasPrefix(name, "-") {
+		return fmt.Errorf("%q starts with '-', which the dump tools would read as an option", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return fmt.Errorf("%q contains %q, which is not allowed in file names", name, r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package backup
+
+import "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+// FilterDatabases keeps the databases whose names pass the filter's patterns,
+// in their original order. ConnectableOnly is applied by the server query.
+func FilterDatabases(databases []DatabaseInfo, filter config.DatabaseFilter) []DatabaseInfo {
+	filtered := make([]DatabaseInfo, 0, len(databases))
+	for _, db := range databases {
+		if filter.Match(db.Name) {
+			filtered = append(filtered, db)
+		}
+	}
+	return filtered
+}
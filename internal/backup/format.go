@@ -0,0 +1,36 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/engine"
+)
+
+// formatAliases maps accepted spellings to the canonical format name.
+var formatAliases = map[string]string{
+	"plain": "sql",
+}
+
+// NormalizeFormat returns the canonical backup format for an engine. An empty
+// format selects the engine's default; anything outside the engine's set is
+// an error rather than a silent fallback.
+func NormalizeFormat(dbType, format string) (string, error) {
+	features, err := engine.Capabilities(dbType)
+	if err != nil {
+		return "", err
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		return features.DefaultFormat, nil
+	}
+	if alias, ok := formatAliases[format]; ok {
+		format = alias
+	}
+
+	if !features.SupportsFormat(format) {
+		return "", fmt.Errorf("unsupported %s backup format %q (expected %s)", dbType, format, strings.Join(features.Formats, ", "))
+	}
+	return format, nil
+}
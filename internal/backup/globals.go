@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// GlobalsPath returns the companion file that holds cluster-wide objects
+// (roles, role grants, tablespaces) for a PostgreSQL backup.
+func GlobalsPath(backupPath string) string {
+	return strings.TrimSuffix(backupPath, filepath.Ext(backupPath)) + ".globals.sql"
+}
+
+// PgDumpallGlobalsArgs assembles the pg_dumpall invocation that writes the
+// cluster globals to outputPath. Reading role passwords requires superuser.
+func PgDumpallGlobalsArgs(cfg *config.Config, outputPath string) []string {
+	return []string{
+		fmt.Sprintf("--host=%s", cfg.Database.Host),
+		fmt.Sprintf("--port=%d", cfg.Database.Port),
+		fmt.Sprintf("--username=%s", cfg.Database.Username),
+		"--globals-only",
+		"--file=" + outputPath,
+	}
+}
+
+// PsqlGlobalsArgs replays a globals file against the maintenance database.
+// ON_ERROR_STOP is left off on purpose: CREATE ROLE fails for roles that
+// already exist in the target cluster, and the remaining statements should
+// still run.
+func PsqlGlobalsArgs(cfg *config.Config, globalsPath string) []string {
+	return []string{
+		fmt.Sprintf("--host=%s", cfg.Database.Host),
+		fmt.Sprintf("--port=%d", cfg.Database.Port),
+		fmt.Sprintf("--username=%s", cfg.Database.Username),
+		"--dbname=postgres",
+		"--file=" + globalsPath,
+	}
+}
+
+// RestoreStep is one external tool invocation in a restore.
+type RestoreStep struct {
+	Tool string
+	Args []string
+}
+
+// PostgresRestoreSteps lists the tool invocations of a restore in the order
+// they run. Globals come first so roles the dump assigns ownership to exist.
+func PostgresRestoreSteps(cfg *config.Config, options RestoreOptions) []RestoreStep {
+	var steps []RestoreStep
+	if options.ApplyGlobals {
+		steps = append(steps, RestoreStep{Tool: "psql", Args: PsqlGlobalsArgs(cfg, GlobalsPath(options.BackupPath))})
+	}
+
+	if backupFormat(options) == "plain" {
+		steps = append(steps, RestoreStep{Tool: "psql", Args: PsqlRestoreArgs(cfg, options)})
+	} else {
+		steps = append(steps, RestoreStep{Tool: "pg_restore", Args: PostgresRestoreArgs(cfg, options)})
+	}
+	return steps
+}
+
+// backupFormat is the pg_dump format of the backup being restored: the one
+// recorded in its metadata, or else the one its name suggests.
+func backupFormat(options RestoreOptions) string {
+	if options.Format != "" {
+		return mapFormat(options.Format)
+	}
+	return mapFormat(DetectFormat(options.BackupPath))
+}
+
+func (s *postgresService) dumpGlobals(outputPath string, verbose bool) (string, error) {
+	globalsPath := GlobalsPath(outputPath)
+	s.log.Warn("Dumping globals with pg_dumpall requires superuser privileges")
+
+	if err := s.runCommand("pg_dumpall", PgDumpallGlobalsArgs(s.cfg, globalsPath), verbose); err != nil {
+		return "", err
+	}
+	return globalsPath, nil
+}
+
+func (s *postgresService) applyGlobals(step RestoreStep, options RestoreOptions) error {
+	globalsPath := GlobalsPath(options.BackupPath)
+	if _, err := os.Stat(globalsPath); err != nil {
+		return fmt.Errorf("globals file not found (was the backup taken with --dump-globals?): %w", err)
+	}
+
+	s.log.Infof("Applying globals from %s; errors for roles that already exist are expected", globalsPath)
+	return s.runCommand(step.Tool, step.Args, options.Verbose)
+}
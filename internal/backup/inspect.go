@@ -0,0 +1,338 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Backup file kinds recognised by DetectArchive.
+const (
+	ArchivePostgres = "postgres"
+	ArchiveMongo    = "mongo"
+	ArchivePlainSQL = "sql"
+)
+
+// mongoArchiveMagic starts every mongodump --archive file (little-endian).
+const mongoArchiveMagic = 0x8199e26d
+
+// DetectArchive tells what wrote a backup from its first bytes: pg_dump's
+// custom, tar and directory formats, a mongodump archive, or plain SQL.
+func DetectArchive(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("backup file not found: %w", err)
+	}
+	if info.IsDir() {
+		if _, err := os.Stat(filepath.Join(path, "toc.dat")); err != nil {
+			return "", fmt.Errorf("%s is not a pg_dump directory backup (no toc.dat)", path)
+		}
+		return ArchivePostgres, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(file, header)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("PGDMP")):
+		return ArchivePostgres, nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return ArchivePostgres, nil
+	case len(header) >= 4 && binary.LittleEndian.Uint32(header) == mongoArchiveMagic:
+		return ArchiveMongo, nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return ArchiveMongo, nil
+	default:
+		return ArchivePlainSQL, nil
+	}
+}
+
+// TOCEntry is one line of pg_restore --list output.
+type TOCEntry struct {
+	ID     int    `json:"id"`
+	Type   string `json:"type"`
+	Schema string `json:"schema,omitempty"`
+	Name   string `json:"name"`
+	Owner  string `json:"owner,omitempty"`
+}
+
+// PostgresTOC is the table of contents of a pg_dump archive.
+type PostgresTOC struct {
+	Database      string     `json:"database,omitempty"`
+	Format        string     `json:"format,omitempty"`
+	ServerVersion string     `json:"server_version,omitempty"`
+	DumpVersion   string     `json:"pg_dump_version,omitempty"`
+	Entries       []TOCEntry `json:"entries"`
+}
+
+// tocTypes are the multi-word object types pg_restore --list prints. They are
+// matched before the single-word types so the schema is not mistaken for part
+// of the type; longer types come first.
+var tocTypes = []string{
+	"PUBLICATION TABLES IN SCHEMA",
+	"TEXT SEARCH CONFIGURATION",
+	"TEXT SEARCH DICTIONARY",
+	"MATERIALIZED VIEW DATA",
+	"FOREIGN DATA WRAPPER",
+	"TEXT SEARCH TEMPLATE",
+	"PROCEDURAL LANGUAGE",
+	"TEXT SEARCH PARSER",
+	"SEQUENCE OWNED BY",
+	"MATERIALIZED VIEW",
+	"PUBLICATION TABLE",
+	"CHECK CONSTRAINT",
+	"OPERATOR FAMILY",
+	"OPERATOR CLASS",
+	"BLOB METADATA",
+	"EVENT TRIGGER",
+	"FOREIGN TABLE",
+	"ACCESS METHOD",
+	"FK CONSTRAINT",
+	"LARGE OBJECT",
+	"ROW SECURITY",
+	"SEQUENCE SET",
+	"TABLE ATTACH",
+	"INDEX ATTACH",
+	"USER MAPPING",
+	"DEFAULT ACL",
+	"SHELL TYPE",
+	"TABLE DATA",
+}
+
+// ParsePostgresTOC reads pg_restore --list output. Entry lines look like
+// "215; 1259 16385 TABLE public users app": the dump ID, the catalog table
+// and object OIDs, then the type, schema ("-" for none), name and owner.
+func ParsePostgresTOC(r io.Reader) (*PostgresTOC, error) {
+	toc := &PostgresTOC{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ";") {
+			toc.readHeader(strings.TrimSpace(strings.TrimPrefix(line, ";")))
+			continue
+		}
+
+		entry, err := parseTOCEntry(line)
+		if err != nil {
+			return nil, err
+		}
+		toc.Entries = append(toc.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read table of contents: %w", err)
+	}
+	return toc, nil
+}
+
+func (t *PostgresTOC) readHeader(comment string) {
+	key, value, ok := strings.Cut(comment, ":")
+	if !ok {
+		return
+	}
+	value = strings.TrimSpace(value)
+	switch key {
+	case "dbname":
+		t.Database = value
+	case "Format":
+		t.Format = value
+	case "Dumped from database version":
+		t.ServerVersion = value
+	case "Dumped by pg_dump version":
+		t.DumpVersion = value
+	}
+}
+
+func parseTOCEntry(line string) (TOCEntry, error) {
+	id, rest, ok := strings.Cut(line, "; ")
+	if !ok {
+		return TOCEntry{}, fmt.Errorf("unexpected table of contents line %q", line)
+	}
+
+	var entry TOCEntry
+	if _, err := fmt.Sscanf(id, "%d", &entry.ID); err != nil {
+		return TOCEntry{}, fmt.Errorf("unexpected table of contents line %q", line)
+	}
+
+	// Skip the two OIDs.
+	fields := strings.SplitN(rest, " ", 3)
+	if len(fields) < 3 {
+		return TOCEntry{}, fmt.Errorf("unexpected table of contents line %q", line)
+	}
+	rest = fields[2]
+
+	entry.Type = strings.SplitN(rest, " ", 2)[0]
+	for _, candidate := range tocTypes {
+		if strings.HasPrefix(rest, candidate+" ") {
+			entry.Type = candidate
+			break
+		}
+	}
+	rest = strings.TrimPrefix(rest, entry.Type+" ")
+
+	// The owner is empty for some entries (ENCODING, STDSTRINGS), leaving a
+	// trailing space, so split on single spaces rather than fields.
+	parts := strings.Split(rest, " ")
+	if len(parts) < 3 {
+		return TOCEntry{}, fmt.Errorf("unexpected table of contents line %q", line)
+	}
+	if parts[0] != "-" {
+		entry.Schema = parts[0]
+	}
+	entry.Owner = parts[len(parts)-1]
+	entry.Name = strings.Join(parts[1:len(parts)-1], " ")
+	return entry, nil
+}
+
+// ListPostgresArchive runs pg_restore --list on a custom, tar or directory
+// format backup. No database connection is needed.
+func ListPostgresArchive(path string) (*PostgresTOC, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("pg_restore", "--list", path)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("pg_restore --list failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return ParsePostgresTOC(bytes.NewReader(output))
+}
+
+// MongoArchiveCollection is one namespace in a mongodump archive.
+type MongoArchiveCollection struct {
+	Database   string `json:"database"`
+	Collection string `json:"collection"`
+	Type       string `json:"type,omitempty"`
+}
+
+// MongoArchive is the prelude of a mongodump archive.
+type MongoArchive struct {
+	FormatVersion string                   `json:"format_version,omitempty"`
+	ServerVersion string                   `json:"server_version,omitempty"`
+	ToolVersion   string                   `json:"tool_version,omitempty"`
+	Collections   []MongoArchiveCollection `json:"collections"`
+}
+
+// ReadMongoArchive reads the prelude at the start of a mongodump archive: the
+// magic number, a header document, one metadata document per collection and
+// a -1 terminator. Documents are not read, so this is quick even for large
+// archives. Archives gzipped as a whole are unwrapped first.
+func ReadMongoArchive(r io.Reader) (*MongoArchive, error) {
+	buffered := bufio.NewReader(r)
+	if start, err := buffered.Peek(2); err == nil && start[0] == 0x1f && start[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer gz.Close()
+		buffered = bufio.NewReader(gz)
+	}
+
+	var magic uint32
+	if err := binary.Read(buffered, binary.LittleEndian, &magic); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if magic != mongoArchiveMagic {
+		return nil, fmt.Errorf("not a mongodump archive")
+	}
+
+	var header struct {
+		FormatVersion string `bson:"version"`
+		ServerVersion string `bson:"server_version"`
+		ToolVersion   string `bson:"tool_version"`
+	}
+	doc, err := readArchiveDocument(buffered)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("archive has no header")
+	}
+	if err := bson.Unmarshal(doc, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode archive header: %w", err)
+	}
+
+	archive := &MongoArchive{
+		FormatVersion: header.FormatVersion,
+		ServerVersion: header.ServerVersion,
+		ToolVersion:   header.ToolVersion,
+	}
+	for {
+		doc, err := readArchiveDocument(buffered)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			return archive, nil
+		}
+
+		var metadata struct {
+			Database   string `bson:"db"`
+			Collection string `bson:"collection"`
+			Type       string `bson:"type"`
+		}
+		if err := bson.Unmarshal(doc, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode collection metadata: %w", err)
+		}
+		archive.Collections = append(archive.Collections, MongoArchiveCollection{
+			Database:   metadata.Database,
+			Collection: metadata.Collection,
+			Type:       metadata.Type,
+		})
+	}
+}
+
+// readArchiveDocument reads one BSON document, or returns nil at the -1
+// terminator that ends the prelude.
+func readArchiveDocument(r io.Reader) (bson.Raw, error) {
+	var length int32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("archive prelude ends early")
+		}
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if length == -1 {
+		return nil, nil
+	}
+	if length < 5 || length > 16*1024*1024 {
+		return nil, fmt.Errorf("archive contains an invalid document length %d", length)
+	}
+
+	doc := make([]byte, length)
+	binary.LittleEndian.PutUint32(doc, uint32(length))
+	if _, err := io.ReadFull(r, doc[4:]); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return bson.Raw(doc), nil
+}
+
+// InspectMongoArchive reads the collection list of a mongodump archive file.
+func InspectMongoArchive(path string) (*MongoArchive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+	return ReadMongoArchive(file)
+}
@@ -0,0 +1,98 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+// MetadataPath returns the sidecar that describes a backup. Unlike the
+// globals and checksum files it keeps the backup's extension, so
+// orders.dump and orders.sql never share one.
+func MetadataPath(backupPath string) string {
+	return strings.TrimRight(backupPath, `/\`) + ".meta.json"
+}
+
+// WriteMetadata writes m to the sidecar file at path.
+func WriteMetadata(path string, m *BackupMetadata) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup metadata: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write backup metadata: %w", err)
+	}
+	return nil
+}
+
+// ReadMetadata reads the sidecar file at path.
+func ReadMetadata(path string) (*BackupMetadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup metadata: %w", err)
+	}
+
+	var m BackupMetadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse backup metadata %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// describeBackup fills in what the sidecar records about how a backup was
+// taken. The tool version is left out when the tool cannot report it.
+func describeBackup(m *BackupMetadata, cfg *config.Config, tool, databaseName string, options BackupOptions) {
+	m.Database = databaseName
+	m.Engine = cfg.Database.Type
+	m.Format = options.Format
+	m.Compression = options.Compression
+	if version, err := clientToolVersion(cfg, tool); err == nil {
+		m.ToolVersion = tool + " " + version.String()
+	}
+}
+
+// writeSidecar writes the metadata of a finished backup next to it. The
+// backup itself is complete, so a failure is only logged.
+func writeSidecar(log *logger.Logger, m *BackupMetadata) {
+	if err := WriteMetadata(MetadataPath(m.Location), m); err != nil {
+		log.Warnf("Backup finished but %v", err)
+	}
+}
+
+// checkBackupMetadata reads the sidecar of a backup about to be restored. It
+// returns nil when there is none, fails when the backup was taken from
+// another engine, and warns when the backup no longer matches its recorded
+// checksum.
+func checkBackupMetadata(log *logger.Logger, engine, backupPath string) (*BackupMetadata, error) {
+	path := MetadataPath(backupPath)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	m, err := ReadMetadata(path)
+	if err != nil {
+		log.Warnf("Ignoring backup metadata: %v", err)
+		return nil, nil
+	}
+	if m.Engine != "" && m.Engine != engine {
+		return nil, fmt.Errorf("%s is a %s backup and cannot be restored into %s", backupPath, m.Engine, engine)
+	}
+
+	if m.Checksum != "" {
+		checksum, _, err := pathChecksum(backupPath)
+		switch {
+		case err != nil:
+			log.Warnf("Unable to verify the backup checksum: %v", err)
+		case checksum != m.Checksum:
+			log.Warnf("%s does not match the checksum recorded in %s; it may have been modified or corrupted", backupPath, path)
+		default:
+			log.Debugf("Backup checksum matches %s", path)
+		}
+	}
+	return m, nil
+}
@@ -3,6 +3,8 @@ package backup
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,12 +12,18 @@ import (
 	"time"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/pkg/humanize"
 	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	listDatabasesDeadline  = 30 * time.Second
+	collectionCountTimeout = 5 * time.Second
 )
 
 type mongoService struct {
@@ -35,15 +43,11 @@ func (s *mongoService) Connect() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(s.cfg.GetMongoURI()))
+	client, err := database.ConnectMongo(ctx, s.cfg, database.PingMongo, s.log)
 	if err != nil {
 		return fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
-	if err := client.Ping(ctx, readpref.Primary()); err != nil {
-		return fmt.Errorf("failed to ping MongoDB: %w", err)
-	}
-
 	s.client = client
 	return nil
 }
@@ -58,6 +62,33 @@ func (s *mongoService) Close() error {
 	return s.client.Disconnect(ctx)
 }
 
+func (s *mongoService) DropDatabase(name string) error {
+	if err := CheckDropDatabase("mongo", name); err != nil {
+		return err
+	}
+	if s.client == nil {
+		if err := s.Connect(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	names, err := s.client.ListDatabaseNames(ctx, bson.D{{Key: "name", Value: name}})
+	if err != nil {
+		return fmt.Errorf("failed to list MongoDB databases: %w", err)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("database %s does not exist", name)
+	}
+
+	if err := s.client.Database(name).Drop(ctx); err != nil {
+		return fmt.Errorf("failed to drop database %s: %w", name, err)
+	}
+	return nil
+}
+
 func (s *mongoService) ListDatabases() ([]DatabaseInfo, error) {
 	if s.client == nil {
 		if err := s.Connect(); err != nil {
@@ -68,7 +99,11 @@ func (s *mongoService) ListDatabases() ([]DatabaseInfo, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	result, err := s.client.ListDatabases(ctx, bson.D{})
+	listOptions := options.ListDatabases()
+	if s.cfg.DatabaseFilter.ConnectableOnly {
+		listOptions.SetAuthorizedDatabases(true)
+	}
+	result, err := s.client.ListDatabases(ctx, bson.D{}, listOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list MongoDB databases: %w", err)
 	}
@@ -80,47 +115,142 @@ func (s *mongoService) ListDatabases() ([]DatabaseInfo, error) {
 			Type: "mongo",
 		}
 
-		if db.SizeOnDisk > 0 {
-			sizeMB := float64(db.SizeOnDisk) / (1024 * 1024)
-			info.Size = fmt.Sprintf("%.2f MB", sizeMB)
-		} else {
-			info.Size = "0 MB"
+		info.Size = humanize.Bytes(db.SizeOnDisk)
+
+		databases = append(databases, info)
+	}
+
+	// Filter before counting collections, which costs a round trip each.
+	databases = FilterDatabases(databases, s.cfg.DatabaseFilter)
+
+	deadline, cancelDeadline := context.WithTimeout(context.Background(), listDatabasesDeadline)
+	defer cancelDeadline()
+
+	gathered, err := CollectDatabaseInfo(deadline, databases, collectionCountTimeout, s.countCollections)
+	if err != nil {
+		s.log.Warnf("collection counts timed out; returning %d of %d databases", len(gathered), len(databases))
+	}
+
+	return gathered, nil
+}
+
+// CollectDatabaseInfo fills in collection counts for each database. Each lookup
+// is best-effort and bounded by perDatabase; once ctx expires the databases
+// gathered so far are returned together with the context error.
+func CollectDatabaseInfo(
+	ctx context.Context,
+	databases []DatabaseInfo,
+	perDatabase time.Duration,
+	count func(ctx context.Context, databaseName string) (int, error),
+) ([]DatabaseInfo, error) {
+	gathered := make([]DatabaseInfo, 0, len(databases))
+
+	for _, info := range databases {
+		if err := ctx.Err(); err != nil {
+			return gathered, err
 		}
 
-		collections, err := s.countCollections(db.Name)
+		lookupCtx, cancel := context.WithTimeout(ctx, perDatabase)
+		collections, err := count(lookupCtx, info.Name)
+		cancel()
+
 		if err == nil {
 			info.Collections = collections
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			return gathered, ctxErr
 		}
 
-		databases = append(databases, info)
+		gathered = append(gathered, info)
 	}
 
-	return databases, nil
+	return gathered, nil
 }
 
 func (s *mongoService) CreateBackup(databaseName string, options BackupOptions) (*BackupMetadata, error) {
 	start := time.Now()
 
+	if _, err := NormalizeFormat("mongo", options.Format); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateExtraArgs("mongodump", options.ExtraArgs); err != nil {
+		return nil, err
+	}
+
+	if options.DumpGlobals {
+		return nil, fmt.Errorf("--dump-globals is only supported for PostgreSQL")
+	}
+
+	if err := preflightVersion(s, "mongodump", options.StrictVersion, s.log.Warnf); err != nil {
+		return nil, err
+	}
+
+	if err := preflightConnect(s.cfg, "mongodump", MongoPreflightArgs(s.cfg, databaseName, options), nil); err != nil {
+		return nil, err
+	}
+
 	outputPath, err := s.ensureOutputPath(databaseName, options)
 	if err != nil {
 		return nil, err
 	}
 
+	if options.ReadPreference != "" && options.ReadPreference != "primary" {
+		s.warnIfStandalone(options.ReadPreference)
+	}
+
 	args := s.buildDumpArgs(databaseName, outputPath, options)
 	if err := s.runCommand("mongodump", args, options.Verbose); err != nil {
 		return nil, err
 	}
 
-	return buildBackupMetadata(outputPath, start)
+	metadata, err := buildBackupMetadata(outputPath, start)
+	if err != nil {
+		return nil, err
+	}
+	describeBackup(metadata, s.cfg, "mongodump", databaseName, options)
+	writeSidecar(s.log, metadata)
+	return metadata, nil
 }
 
 func (s *mongoService) RestoreBackup(options RestoreOptions) error {
+	if options.AtomicSwap {
+		return fmt.Errorf("--atomic-swap is only supported for PostgreSQL restores")
+	}
+
 	if _, err := os.Stat(options.BackupPath); err != nil {
 		return fmt.Errorf("backup file not found: %w", err)
 	}
 
+	metadata, err := checkBackupMetadata(s.log, "mongo", options.BackupPath)
+	if err != nil {
+		return err
+	}
+	if metadata != nil && options.Format == "" {
+		options.Format = metadata.Format
+		options.Compression = metadata.Compression
+	}
+
+	if err := ValidateExtraArgs("mongorestore", options.ExtraArgs); err != nil {
+		return err
+	}
+
+	if err := preflightVersion(s, "mongorestore", options.StrictVersion, s.log.Warnf); err != nil {
+		return err
+	}
+
+	args := MongoRestoreArgs(s.cfg, options)
+	if !options.TolerateExisting {
+		return s.runCommand("mongorestore", args, options.Verbose)
+	}
+	return runTolerant(s.log, "mongorestore", func(output io.Writer) error {
+		return s.runCommandTo("mongorestore", args, options.Verbose, output)
+	})
+}
+
+// MongoRestoreArgs assembles the mongorestore argument list for the given options.
+func MongoRestoreArgs(cfg *config.Config, options RestoreOptions) []string {
 	args := []string{
-		fmt.Sprintf("--uri=%s", s.cfg.GetMongoURI()),
+		fmt.Sprintf("--uri=%s", cfg.MongoConnectionURI()),
 		fmt.Sprintf("--archive=%s", options.BackupPath),
 	}
 
@@ -132,15 +262,49 @@ func (s *mongoService) RestoreBackup(options RestoreOptions) error {
 		args = append(args, "--drop")
 	}
 
+	// A compressed archive must be restored with --gzip.
+	if options.Compression > 0 || (options.Format == "" && strings.HasSuffix(strings.ToLower(options.BackupPath), ".gz")) {
+		args = append(args, "--gzip")
+	}
+
 	if options.Verbose {
 		args = append(args, "--verbose")
 	}
 
-	if options.ExitOnError {
+	if options.ExitOnError && !options.TolerateExisting {
 		args = append(args, "--stopOnError")
 	}
 
-	return s.runCommand("mongorestore", args, options.Verbose)
+	return append(args, options.ExtraArgs...)
+}
+
+func (s *mongoService) CheckVersion(tool string) (*VersionCheck, error) {
+	client, err := clientToolVersion(s.cfg, tool)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.client == nil {
+		if err := s.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to read server version: %w", err)
+	}
+	server, err := ParseVersion(info.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VersionCheck{Tool: tool, Engine: "mongo", Client: client, Server: server}, nil
 }
 
 func (s *mongoService) ensureOutputPath(databaseName string, options BackupOptions) (string, error) {
@@ -155,7 +319,10 @@ func (s *mongoService) ensureOutputPath(databaseName string, options BackupOptio
 			extension = ".archive.gz"
 		}
 
-		fileName := fmt.Sprintf("%s_%s%s", databaseName, time.Now().Format("20060102_150405"), extension)
+		fileName, err := RenderFilename(options.FilenameTemplate, databaseName, time.Now(), extension)
+		if err != nil {
+			return "", err
+		}
 		outputPath = filepath.Join("backup", fileName)
 	} else {
 		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
@@ -167,15 +334,32 @@ func (s *mongoService) ensureOutputPath(databaseName string, options BackupOptio
 }
 
 func (s *mongoService) buildDumpArgs(databaseName, outputPath string, options BackupOptions) []string {
-	args := []string{
-		fmt.Sprintf("--uri=%s", s.cfg.GetMongoURI()),
-		fmt.Sprintf("--archive=%s", outputPath),
+	return MongoDumpArgs(s.cfg, databaseName, outputPath, options)
+}
+
+// MongoDumpArgs assembles the mongodump argument list for the given database and options.
+func MongoDumpArgs(cfg *config.Config, databaseName, outputPath string, options BackupOptions) []string {
+	uri := cfg.MongoConnectionURI()
+	if options.ReadPreference != "" {
+		uri = withURIParam(uri, "readPreference", options.ReadPreference)
+	}
+
+	args := []string{fmt.Sprintf("--uri=%s", uri)}
+	// A bare --archive makes mongodump write the archive to stdout.
+	if outputPath != "" {
+		args = append(args, fmt.Sprintf("--archive=%s", outputPath))
+	} else {
+		args = append(args, "--archive")
 	}
 
 	if databaseName != "" {
 		args = append(args, fmt.Sprintf("--db=%s", databaseName))
 	}
 
+	if options.ReadPreference != "" {
+		args = append(args, fmt.Sprintf("--readPreference=%s", options.ReadPreference))
+	}
+
 	if options.Compression > 0 {
 		args = append(args, "--gzip")
 	}
@@ -184,22 +368,67 @@ func (s *mongoService) buildDumpArgs(databaseName, outputPath string, options Ba
 		args = append(args, "--verbose")
 	}
 
-	return args
+	return append(args, options.ExtraArgs...)
+}
+
+// warnIfStandalone logs a warning when a non-primary read preference is requested
+// against a server that is not a replica set member.
+func (s *mongoService) warnIfStandalone(readPreference string) {
+	if s.client == nil {
+		if err := s.Connect(); err != nil {
+			s.log.Warnf("unable to verify replica set membership: %v", err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var hello bson.M
+	if err := s.client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello); err != nil {
+		s.log.Warnf("unable to verify replica set membership: %v", err)
+		return
+	}
+
+	if _, ok := hello["setName"]; !ok {
+		s.log.Warnf("read preference %q requested but the server is not a replica set member; reads will hit the standalone node", readPreference)
+	}
+}
+
+func withURIParam(uri, key, value string) string {
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	} else if hostPart := uri[strings.Index(uri, "://")+3:]; !strings.Contains(hostPart, "/") {
+		// The connection string spec requires a slash before the options block.
+		separator = "/?"
+	}
+	return fmt.Sprintf("%s%s%s=%s", uri, separator, key, url.QueryEscape(value))
 }
 
 func (s *mongoService) runCommand(name string, args []string, verbose bool) error {
-	cmd := exec.Command(name, args...)
+	return s.runCommandTo(name, args, verbose, io.Discard)
+}
+
+// runCommandTo runs a tool like runCommand and also copies its output to
+// output.
+func (s *mongoService) runCommandTo(name string, args []string, verbose bool, output io.Writer) error {
+	path, err := s.cfg.ToolPath(name)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, args...)
 	if verbose {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		cmd.Stdout = io.MultiWriter(os.Stdout, output)
+		cmd.Stderr = io.MultiWriter(os.Stderr, output)
 	} else {
 		writer := s.log.Writer()
 		defer writer.Close()
-		cmd.Stdout = writer
-		cmd.Stderr = writer
+		cmd.Stdout = io.MultiWriter(writer, output)
+		cmd.Stderr = cmd.Stdout
 	}
 
-	s.log.Debugf("executing %s %s", name, strings.Join(args, " "))
+	s.log.Debugf("executing %s %s", path, strings.Join(args, " "))
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("%s failed: %w", name, err)
@@ -208,14 +437,11 @@ func (s *mongoService) runCommand(name string, args []string, verbose bool) erro
 	return nil
 }
 
-func (s *mongoService) countCollections(databaseName string) (int, error) {
+func (s *mongoService) countCollections(ctx context.Context, databaseName string) (int, error) {
 	if databaseName == "" {
 		return 0, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	collections, err := s.client.Database(databaseName).ListCollectionNames(ctx, bson.D{})
 	if err != nil {
 		return 0, err
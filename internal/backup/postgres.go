@@ -2,6 +2,7 @@ package backup
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
 	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/pkg/humanize"
 	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
 )
 
@@ -49,18 +51,7 @@ func (s *postgresService) ListDatabases() ([]DatabaseInfo, error) {
 		}
 	}
 
-	const query = `
-		SELECT 
-			datname,
-			pg_catalog.pg_get_userbyid(datdba) AS owner,
-			pg_catalog.pg_encoding_to_char(encoding) AS encoding,
-			pg_size_pretty(pg_database_size(datname)) AS size
-		FROM pg_database
-		WHERE datistemplate = false
-		ORDER BY datname;
-	`
-
-	rows, err := s.conn.DB.Query(query)
+	rows, err := s.conn.DB.Query(PostgresListDatabasesQuery(s.cfg.DatabaseFilter.ConnectableOnly))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query databases: %w", err)
 	}
@@ -68,20 +59,68 @@ func (s *postgresService) ListDatabases() ([]DatabaseInfo, error) {
 
 	var databases []DatabaseInfo
 	for rows.Next() {
-		var info DatabaseInfo
-		if err := rows.Scan(&info.Name, &info.Owner, &info.Encoding, &info.Size); err != nil {
+		var (
+			info DatabaseInfo
+			size int64
+		)
+		if err := rows.Scan(&info.Name, &info.Owner, &info.Encoding, &size); err != nil {
 			return nil, fmt.Errorf("failed to read database info: %w", err)
 		}
+		info.Size = humanize.Bytes(size)
 		info.Type = "postgres"
 		databases = append(databases, info)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read database info: %w", err)
+	}
 
-	return databases, nil
+	return FilterDatabases(databases, s.cfg.DatabaseFilter), nil
+}
+
+// PostgresListDatabasesQuery lists non-template databases. With
+// connectableOnly, databases the current user lacks CONNECT on are left out;
+// pg_database_size needs that privilege anyway.
+func PostgresListDatabasesQuery(connectableOnly bool) string {
+	privilege := ""
+	if connectableOnly {
+		privilege = "\n\t\tAND has_database_privilege(datname, 'CONNECT')"
+	}
+	return `
+		SELECT
+			datname,
+			pg_catalog.pg_get_userbyid(datdba) AS owner,
+			pg_catalog.pg_encoding_to_char(encoding) AS encoding,
+			pg_database_size(datname) AS size
+		FROM pg_database
+		WHERE datistemplate = false` + privilege + `
+		ORDER BY datname;
+	`
 }
 
 func (s *postgresService) CreateBackup(databaseName string, options BackupOptions) (*BackupMetadata, error) {
 	start := time.Now()
 
+	format, err := NormalizeFormat("postgres", options.Format)
+	if err != nil {
+		return nil, err
+	}
+	options.Format = format
+	if options.Jobs > 1 && mapFormat(format) != "directory" {
+		s.log.Warnf("pg_dump only runs parallel jobs for the directory format; dumping %s format with one job", format)
+	}
+
+	if err := ValidateExtraArgs("pg_dump", options.ExtraArgs); err != nil {
+		return nil, err
+	}
+
+	if err := preflightVersion(s, "pg_dump", options.StrictVersion, s.log.Warnf); err != nil {
+		return nil, err
+	}
+
+	if err := preflightConnect(s.cfg, "pg_dump", PostgresPreflightArgs(s.cfg, databaseName), s.cfg.PostgresToolEnv()); err != nil {
+		return nil, err
+	}
+
 	outputPath, err := s.ensureOutputPath(databaseName, options)
 	if err != nil {
 		return nil, err
@@ -92,7 +131,21 @@ func (s *postgresService) CreateBackup(databaseName string, options BackupOption
 		return nil, err
 	}
 
-	return buildBackupMetadata(outputPath, start)
+	globalsPath := ""
+	if options.DumpGlobals {
+		if globalsPath, err = s.dumpGlobals(outputPath, options.Verbose); err != nil {
+			return nil, fmt.Errorf("database dumped to %s but globals failed: %w", outputPath, err)
+		}
+	}
+
+	metadata, err := buildBackupMetadata(outputPath, start)
+	if err != nil {
+		return nil, err
+	}
+	metadata.GlobalsPath = globalsPath
+	describeBackup(metadata, s.cfg, "pg_dump", databaseName, options)
+	writeSidecar(s.log, metadata)
+	return metadata, nil
 }
 
 func (s *postgresService) RestoreBackup(options RestoreOptions) error {
@@ -104,18 +157,69 @@ func (s *postgresService) RestoreBackup(options RestoreOptions) error {
 		return fmt.Errorf("backup file not found: %w", err)
 	}
 
+	metadata, err := checkBackupMetadata(s.log, "postgres", options.BackupPath)
+	if err != nil {
+		return err
+	}
+	if metadata != nil && options.Format == "" {
+		options.Format = metadata.Format
+		options.Compression = metadata.Compression
+	}
+
+	if options.ApplyGlobals {
+		steps := PostgresRestoreSteps(s.cfg, options)
+		if err := s.applyGlobals(steps[0], options); err != nil {
+			return err
+		}
+	}
+
+	if options.AtomicSwap {
+		return s.restoreWithSwap(options)
+	}
+
 	if options.CreateDatabase {
 		if err := s.createDatabase(options.TargetDatabase, options.CleanFirst); err != nil {
 			return err
 		}
 	}
 
-	ext := strings.ToLower(filepath.Ext(options.BackupPath))
-	if ext == ".sql" {
-		return s.restoreWithPSQL(options)
+	return s.restoreDump(options)
+}
+
+// restoreDump replays the backup into options.TargetDatabase with psql or
+// pg_restore, depending on the backup's format.
+func (s *postgresService) restoreDump(options RestoreOptions) error {
+	steps := PostgresRestoreSteps(s.cfg, options)
+	main := steps[len(steps)-1]
+
+	if main.Tool == "psql" {
+		return s.restoreWithPSQL(options, main.Args)
+	}
+	return s.restoreWithPgRestore(options, main.Args)
+}
+
+func (s *postgresService) CheckVersion(tool string) (*VersionCheck, error) {
+	client, err := clientToolVersion(s.cfg, tool)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.conn == nil {
+		if err := s.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	var raw string
+	if err := s.conn.DB.QueryRow("SHOW server_version").Scan(&raw); err != nil {
+		return nil, fmt.Errorf("failed to read server version: %w", err)
+	}
+	server, err := ParseVersion(raw)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.restoreWithPgRestore(options)
+	return &VersionCheck{Tool: tool, Engine: "postgres", Client: client, Server: server}, nil
 }
 
 func (s *postgresService) ensureOutputPath(databaseName string, options BackupOptions) (string, error) {
@@ -126,7 +230,10 @@ func (s *postgresService) ensureOutputPath(databaseName string, options BackupOp
 		}
 
 		extension := s.resolveExtension(options.Format)
-		fileName := fmt.Sprintf("%s_%s%s", databaseName, time.Now().Format("20060102_150405"), extension)
+		fileName, err := RenderFilename(options.FilenameTemplate, databaseName, time.Now(), extension)
+		if err != nil {
+			return "", err
+		}
 		outputPath = filepath.Join("backup", fileName)
 	} else {
 		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
@@ -135,7 +242,7 @@ func (s *postgresService) ensureOutputPath(databaseName string, options BackupOp
 	}
 
 	// Directory format expects a folder that does not yet exist.
-	if s.mapFormat(options.Format) == "directory" {
+	if mapFormat(options.Format) == "directory" {
 		if err := os.MkdirAll(outputPath, 0o755); err != nil {
 			return "", fmt.Errorf("failed to prepare directory backup path: %w", err)
 		}
@@ -145,15 +252,23 @@ func (s *postgresService) ensureOutputPath(databaseName string, options BackupOp
 }
 
 func (s *postgresService) buildDumpArgs(databaseName, outputPath string, options BackupOptions) []string {
-	format := s.mapFormat(options.Format)
+	return PostgresDumpArgs(s.cfg, databaseName, outputPath, options)
+}
+
+// PostgresDumpArgs assembles the pg_dump argument list for the given database and options.
+func PostgresDumpArgs(cfg *config.Config, databaseName, outputPath string, options BackupOptions) []string {
+	format := mapFormat(options.Format)
 
 	args := []string{
-		fmt.Sprintf("--host=%s", s.cfg.Database.Host),
-		fmt.Sprintf("--port=%d", s.cfg.Database.Port),
-		fmt.Sprintf("--username=%s", s.cfg.Database.Username),
+		fmt.Sprintf("--host=%s", cfg.Database.Host),
+		fmt.Sprintf("--port=%d", cfg.Database.Port),
+		fmt.Sprintf("--username=%s", cfg.Database.Username),
 		fmt.Sprintf("--dbname=%s", databaseName),
 		fmt.Sprintf("--format=%s", format),
-		fmt.Sprintf("--file=%s", outputPath),
+	}
+	// Without --file, pg_dump writes the archive to stdout.
+	if outputPath != "" {
+		args = append(args, fmt.Sprintf("--file=%s", outputPath))
 	}
 
 	if options.SchemaOnly {
@@ -172,10 +287,28 @@ func (s *postgresService) buildDumpArgs(databaseName, outputPath string, options
 		args = append(args, fmt.Sprintf("--compress=%d", options.Compression))
 	}
 
-	return args
+	// Each pattern is its own argument, so names with spaces or quotes reach
+	// pg_dump as written.
+	for _, pattern := range options.ExcludeSchemas {
+		args = append(args, "--exclude-schema="+pattern)
+	}
+	for _, pattern := range options.ExcludeTables {
+		args = append(args, "--exclude-table="+pattern)
+	}
+
+	// pg_dump rejects --jobs for any other format.
+	if options.Jobs > 1 && format == "directory" {
+		args = append(args, fmt.Sprintf("--jobs=%d", options.Jobs))
+	}
+
+	if cfg.Database.ClientEncoding != "" {
+		args = append(args, fmt.Sprintf("--encoding=%s", cfg.Database.ClientEncoding))
+	}
+
+	return append(args, options.ExtraArgs...)
 }
 
-func (s *postgresService) mapFormat(format string) string {
+func mapFormat(format string) string {
 	switch strings.ToLower(strings.TrimSpace(format)) {
 	case "sql", "plain":
 		return "plain"
@@ -202,19 +335,29 @@ func (s *postgresService) resolveExtension(format string) string {
 }
 
 func (s *postgresService) runCommand(cmdName string, args []string, verbose bool) error {
-	cmd := exec.Command(cmdName, args...)
-	cmd.Env = append(os.Environ(), s.postgresEnv()...)
+	return s.runCommandTo(cmdName, args, verbose, io.Discard)
+}
+
+// runCommandTo runs a tool like runCommand and also copies its output to
+// output.
+func (s *postgresService) runCommandTo(cmdName string, args []string, verbose bool, output io.Writer) error {
+	path, err := s.cfg.ToolPath(cmdName)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), s.cfg.PostgresToolEnv()...)
 	if verbose {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		cmd.Stdout = io.MultiWriter(os.Stdout, output)
+		cmd.Stderr = io.MultiWriter(os.Stderr, output)
 	} else {
 		writer := s.log.Writer()
 		defer writer.Close()
-		cmd.Stdout = writer
-		cmd.Stderr = writer
+		cmd.Stdout = io.MultiWriter(writer, output)
+		cmd.Stderr = cmd.Stdout
 	}
 
-	s.log.Debugf("executing %s %s", cmdName, strings.Join(args, " "))
+	s.log.Debugf("executing %s %s", path, strings.Join(args, " "))
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("%s failed: %w", cmdName, err)
@@ -222,20 +365,43 @@ func (s *postgresService) runCommand(cmdName string, args []string, verbose bool
 	return nil
 }
 
-func (s *postgresService) postgresEnv() []string {
-	if s.cfg.Database.Password == "" {
-		return nil
+// restoreWithPgRestore replays a custom, tar or directory archive. Custom and
+// directory archives can be restored with parallel jobs too: RestoreOptions.Jobs
+// (the restore command's --jobs flag, as for backups) adds --jobs to args.
+func (s *postgresService) restoreWithPgRestore(options RestoreOptions, args []string) error {
+	if err := ValidateExtraArgs("pg_restore", options.ExtraArgs); err != nil {
+		return err
+	}
+
+	if err := preflightVersion(s, "pg_restore", options.StrictVersion, s.log.Warnf); err != nil {
+		return err
+	}
+
+	return s.runRestoreTool("pg_restore", args, options)
+}
+
+// runRestoreTool runs pg_restore or psql. With TolerateExisting, errors about
+// objects already on the target are counted instead of failing the restore.
+func (s *postgresService) runRestoreTool(tool string, args []string, options RestoreOptions) error {
+	if !options.TolerateExisting {
+		return s.runCommand(tool, args, options.Verbose)
 	}
-	return []string{fmt.Sprintf("PGPASSWORD=%s", s.cfg.Database.Password)}
+	return runTolerant(s.log, tool, func(output io.Writer) error {
+		return s.runCommandTo(tool, args, options.Verbose, output)
+	})
 }
 
-func (s *postgresService) restoreWithPgRestore(options RestoreOptions) error {
+// PostgresRestoreArgs assembles the pg_restore argument list for the given options.
+func PostgresRestoreArgs(cfg *config.Config, options RestoreOptions) []string {
 	args := []string{
-		fmt.Sprintf("--host=%s", s.cfg.Database.Host),
-		fmt.Sprintf("--port=%d", s.cfg.Database.Port),
-		fmt.Sprintf("--username=%s", s.cfg.Database.Username),
+		fmt.Sprintf("--host=%s", cfg.Database.Host),
+		fmt.Sprintf("--port=%d", cfg.Database.Port),
+		fmt.Sprintf("--username=%s", cfg.Database.Username),
 		fmt.Sprintf("--dbname=%s", options.TargetDatabase),
-		options.BackupPath,
+	}
+	// Without a file argument, pg_restore reads the archive from stdin.
+	if options.BackupPath != "" {
+		args = append(args, options.BackupPath)
 	}
 
 	if options.Verbose {
@@ -244,37 +410,59 @@ func (s *postgresService) restoreWithPgRestore(options RestoreOptions) error {
 
 	if options.CleanFirst {
 		args = append(args, "--clean")
+		// Without --if-exists, --clean errors on objects missing from the target.
+		if options.IfExists {
+			args = append(args, "--if-exists")
+		}
 	}
 
-	if options.ExitOnError {
+	if options.ExitOnError && !options.TolerateExisting {
 		args = append(args, "--exit-on-error")
 	}
 
-	return s.runCommand("pg_restore", args, options.Verbose)
+	// Parallel restore needs to seek in the archive, which rules out stdin
+	// and the tar format.
+	if options.Jobs > 1 && options.BackupPath != "" && backupFormat(options) != "tar" {
+		args = append(args, fmt.Sprintf("--jobs=%d", options.Jobs))
+	}
+
+	return append(args, options.ExtraArgs...)
 }
 
-func (s *postgresService) restoreWithPSQL(options RestoreOptions) error {
+func (s *postgresService) restoreWithPSQL(options RestoreOptions, args []string) error {
+	if err := ValidateExtraArgs("psql", options.ExtraArgs); err != nil {
+		return err
+	}
+
 	if options.CleanFirst {
 		if err := s.recreateDatabase(options.TargetDatabase); err != nil {
 			return err
 		}
 	}
 
+	return s.runRestoreTool("psql", args, options)
+}
+
+// PsqlRestoreArgs assembles the psql argument list used to replay a plain SQL dump.
+func PsqlRestoreArgs(cfg *config.Config, options RestoreOptions) []string {
 	args := []string{
-		fmt.Sprintf("--host=%s", s.cfg.Database.Host),
-		fmt.Sprintf("--port=%d", s.cfg.Database.Port),
-		fmt.Sprintf("--username=%s", s.cfg.Database.Username),
+		fmt.Sprintf("--host=%s", cfg.Database.Host),
+		fmt.Sprintf("--port=%d", cfg.Database.Port),
+		fmt.Sprintf("--username=%s", cfg.Database.Username),
 		fmt.Sprintf("--dbname=%s", options.TargetDatabase),
-		"--single-transaction",
-		"--set=ON_ERROR_STOP=1",
 		"--file=" + options.BackupPath,
 	}
+	// Tolerating existing objects means carrying on past errors, which a
+	// single transaction would turn into a rollback of everything.
+	if !options.TolerateExisting {
+		args = append(args, "--single-transaction", "--set=ON_ERROR_STOP=1")
+	}
 
 	if options.Verbose {
 		args = append(args, "--echo-errors")
 	}
 
-	return s.runCommand("psql", args, options.Verbose)
+	return append(args, options.ExtraArgs...)
 }
 
 func (s *postgresService) createDatabase(name string, clean bool) error {
@@ -311,6 +499,41 @@ func (s *postgresService) recreateDatabase(name string) error {
 	}
 	defer adminConn.Close()
 
+	if err := s.dropDatabase(adminConn, name); err != nil {
+		return err
+	}
+
+	if _, err := adminConn.DB.Exec(fmt.Sprintf("CREATE DATABASE %s", quoteIdentifier(name))); err != nil {
+		return fmt.Errorf("failed to recreate database %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *postgresService) DropDatabase(name string) error {
+	if err := CheckDropDatabase("postgres", name); err != nil {
+		return err
+	}
+
+	adminConn, err := s.openAdminConnection()
+	if err != nil {
+		return err
+	}
+	defer adminConn.Close()
+
+	var exists bool
+	if err := adminConn.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)", name).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check database existence: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("database %s does not exist", name)
+	}
+
+	return s.dropDatabase(adminConn, name)
+}
+
+// dropDatabase terminates the sessions connected to name, which would
+// otherwise block the drop, and drops it.
+func (s *postgresService) dropDatabase(adminConn *database.Connection, name string) error {
 	if _, err := adminConn.DB.Exec("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1", name); err != nil {
 		s.log.Warnf("failed to terminate active sessions: %v", err)
 	}
@@ -318,10 +541,6 @@ func (s *postgresService) recreateDatabase(name string) error {
 	if _, err := adminConn.DB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", quoteIdentifier(name))); err != nil {
 		return fmt.Errorf("failed to drop database %s: %w", name, err)
 	}
-
-	if _, err := adminConn.DB.Exec(fmt.Sprintf("CREATE DATABASE %s", quoteIdentifier(name))); err != nil {
-		return fmt.Errorf("failed to recreate database %s: %w", name, err)
-	}
 	return nil
 }
 
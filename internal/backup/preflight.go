@@ -0,0 +1,59 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// preflightObject names a collection that is not expected to exist, so the
+// MongoDB connectivity check connects and authenticates but dumps nothing.
+const preflightObject = "dbrts_preflight_check"
+
+// PostgresPreflightArgs builds a pg_dump invocation with the connection
+// arguments of the real dump that only reads the catalog. Every schema is
+// excluded rather than a missing table included: pg_dump fails when an
+// include pattern matches nothing, but not when an exclude pattern does.
+// Extra arguments are left out, since they may conflict with --schema-only.
+func PostgresPreflightArgs(cfg *config.Config, databaseName string) []string {
+	args := PostgresDumpArgs(cfg, databaseName, "", BackupOptions{Format: "sql", SchemaOnly: true})
+	return append(args, "--exclude-schema=*")
+}
+
+// MongoPreflightArgs builds a mongodump invocation with the URI and read
+// preference of the real dump that writes an empty archive to stdout.
+func MongoPreflightArgs(cfg *config.Config, databaseName string, options BackupOptions) []string {
+	args := MongoDumpArgs(cfg, databaseName, "", BackupOptions{ReadPreference: options.ReadPreference})
+	return append(args, fmt.Sprintf("--collection=%s", preflightObject))
+}
+
+// preflightConnect runs a dump tool's connectivity check. The tool connects
+// on its own, with its own auth and TLS handling, so a Go driver connection
+// succeeding says little about whether the dump will.
+func preflightConnect(cfg *config.Config, tool string, args, env []string) error {
+	path, err := cfg.ToolPath(tool)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = io.Discard
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		detail := strings.TrimSpace(stderr.String())
+		if detail == "" {
+			detail = err.Error()
+		}
+		return fmt.Errorf("%s cannot connect with the settings the backup will use: %s", tool, detail)
+	}
+	return nil
+}
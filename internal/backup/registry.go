@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRegistryPath records completed backups next to the default backup directory.
+var DefaultRegistryPath = filepath.Join("backup", "registry.json")
+
+// RegistryEntry is one completed backup as recorded in the registry.
+type RegistryEntry struct {
+	Engine    string    `json:"engine"`
+	Database  string    `json:"database"`
+	Path      string    `json:"path"`
+	Format    string    `json:"format"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	CreatedAt time.Time `json:"created_at"`
+	Globals   string    `json:"globals_path,omitempty"`
+	Checksums string    `json:"checksums_path,omitempty"`
+}
+
+// RecordBackup appends entry to the registry file, creating it when needed.
+func RecordBackup(registryPath string, entry RegistryEntry) error {
+	entries, err := readRegistry(registryPath)
+	if err != nil {
+		return err
+	}
+
+	if entry.Format == "" {
+		entry.Format = DetectFormat(entry.Path)
+	}
+	entries = append(entries, entry)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup registry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(registryPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	if err := os.WriteFile(registryPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write backup registry: %w", err)
+	}
+	return nil
+}
+
+// ListBackups returns the recorded backups for engine, newest first. Entries
+// whose files have since been removed are left out.
+func ListBackups(registryPath, engine string) ([]RegistryEntry, error) {
+	entries, err := readRegistry(registryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []RegistryEntry
+	for _, entry := range entries {
+		if engine != "" && entry.Engine != engine {
+			continue
+		}
+		if _, err := os.Stat(entry.Path); err != nil {
+			continue
+		}
+		result = append(result, entry)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// DetectFormat infers the backup format from the file name or directory.
+func DetectFormat(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".archive.gz"), strings.HasSuffix(lower, ".archive"):
+		return "archive"
+	case strings.HasSuffix(lower, ".sql"):
+		return "plain"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	case strings.HasSuffix(lower, ".dump"):
+		return "custom"
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "directory"
+	}
+	return "unknown"
+}
+
+func readRegistry(registryPath string) ([]RegistryEntry, error) {
+	data, err := os.ReadFile(registryPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup registry: %w", err)
+	}
+
+	var entries []RegistryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse backup registry %s: %w", registryPath, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+// Kinds of restore tool output lines.
+const (
+	RestoreLineOther    = ""
+	RestoreLineExisting = "existing"
+	RestoreLineFailed   = "failed"
+)
+
+var (
+	// pg_restore prints "pg_restore: error: could not execute query: ERROR:  ..."
+	// (or "[archiver (db)] could not execute query" before PostgreSQL 12) and
+	// psql prints "psql:dump.sql:42: ERROR:  ...". FATAL is a lost connection.
+	postgresErrorLine = regexp.MustCompile(`\b(?:ERROR|FATAL):\s+(.*)$`)
+	// Objects the target already has: tables, types, functions, constraints
+	// and a second primary key on a table that already has one.
+	postgresExistingError = regexp.MustCompile(`already exists|multiple primary keys for table`)
+
+	// mongorestore without --stopOnError logs each skipped insert as
+	// "continuing through error: ..." and stops on "Failed: ...".
+	mongoErrorLine = regexp.MustCompile(`(?:continuing through error|(?:^|\s)Failed):\s+(.*)$`)
+)
+
+// ClassifyRestoreLine sorts one line of pg_restore, psql or mongorestore
+// output into an error about an object that already exists on the target,
+// any other error, or neither. It also returns the error message.
+func ClassifyRestoreLine(tool, line string) (string, string) {
+	switch tool {
+	case "pg_restore", "psql":
+		match := postgresErrorLine.FindStringSubmatch(line)
+		if match == nil {
+			return RestoreLineOther, ""
+		}
+		if postgresExistingError.MatchString(match[1]) {
+			return RestoreLineExisting, match[1]
+		}
+		return RestoreLineFailed, match[1]
+	case "mongorestore":
+		match := mongoErrorLine.FindStringSubmatch(line)
+		if match == nil {
+			return RestoreLineOther, ""
+		}
+		// E11000 is a duplicate key: the document is already there.
+		if strings.Contains(match[1], "E11000") {
+			return RestoreLineExisting, match[1]
+		}
+		return RestoreLineFailed, match[1]
+	default:
+		return RestoreLineOther, ""
+	}
+}
+
+// RestoreErrors tallies the errors a restore tool prints as its output is
+// written to it.
+type RestoreErrors struct {
+	Tool     string
+	Existing int
+	Failed   []string
+
+	pending []byte
+}
+
+func (e *RestoreErrors) Write(p []byte) (int, error) {
+	e.pending = append(e.pending, p...)
+	for {
+		end := bytes.IndexByte(e.pending, '\n')
+		if end < 0 {
+			break
+		}
+		e.add(string(e.pending[:end]))
+		e.pending = e.pending[end+1:]
+	}
+	return len(p), nil
+}
+
+// Flush classifies a last line that did not end in a newline.
+func (e *RestoreErrors) Flush() {
+	if len(e.pending) > 0 {
+		e.add(string(e.pending))
+		e.pending = nil
+	}
+}
+
+func (e *RestoreErrors) add(line string) {
+	kind, message := ClassifyRestoreLine(e.Tool, strings.TrimRight(line, "\r"))
+	switch kind {
+	case RestoreLineExisting:
+		e.Existing++
+	case RestoreLineFailed:
+		e.Failed = append(e.Failed, message)
+	}
+}
+
+// Summary describes the tally in one line.
+func (e *RestoreErrors) Summary() string {
+	return fmt.Sprintf("%s: %d already present on the target, %d failed", e.Tool, e.Existing, len(e.Failed))
+}
+
+// Result decides the outcome of a restore run with TolerateExisting. The
+// tool's own failure (runErr) is forgiven when every error it printed was
+// about an object that already exists, since pg_restore exits non-zero
+// whenever it ignored an error.
+func (e *RestoreErrors) Result(runErr error) error {
+	e.Flush()
+	switch {
+	case len(e.Failed) > 0:
+		return fmt.Errorf("%s; first error: %s", e.Summary(), e.Failed[0])
+	case runErr != nil && e.Existing == 0:
+		return runErr
+	default:
+		return nil
+	}
+}
+
+// runTolerant runs a restore tool whose output run also writes to the given
+// writer, and judges the run by the errors the tool printed.
+func runTolerant(log *logger.Logger, tool string, run func(output io.Writer) error) error {
+	tally := &RestoreErrors{Tool: tool}
+	if err := tally.Result(run(tally)); err != nil {
+		return err
+	}
+	if tally.Existing > 0 {
+		log.Warnf("%s; existing objects were left as they are", tally.Summary())
+	}
+	return nil
+}
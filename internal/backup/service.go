@@ -13,6 +13,9 @@ type Service interface {
 	ListDatabases() ([]DatabaseInfo, error)
 	CreateBackup(database string, options BackupOptions) (*BackupMetadata, error)
 	RestoreBackup(options RestoreOptions) error
+	CheckVersion(tool string) (*VersionCheck, error)
+	// DropDatabase drops a database after CheckDropDatabase allows it.
+	DropDatabase(name string) error
 }
 
 func NewService(cfg *config.Config, log *logger.Logger) (Service, error) {
@@ -0,0 +1,240 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+
+	"github.com/lib/pq"
+)
+
+// maxIdentifierLength is PostgreSQL's NAMEDATALEN - 1; longer names are
+// silently truncated by the server, so generated names are cut to fit.
+const maxIdentifierLength = 63
+
+// AtomicSwap names the databases of an --atomic-swap restore: the backup is
+// restored into Temp, then Target is renamed to Old and Temp to Target.
+type AtomicSwap struct {
+	Target string
+	Temp   string
+	Old    string
+}
+
+func NewAtomicSwap(target string, at time.Time) AtomicSwap {
+	return AtomicSwap{
+		Target: target,
+		Temp:   withIdentifierSuffix(target, "_swap_"+at.Format("20060102150405")),
+		Old:    withIdentifierSuffix(target, "_old"),
+	}
+}
+
+// withIdentifierSuffix appends suffix, shortening name so the result still
+// fits in an identifier.
+func withIdentifierSuffix(name, suffix string) string {
+	limit := maxIdentifierLength - len(suffix)
+	for len(name) > limit {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name + suffix
+}
+
+// LockStatements stop new sessions on the target and end the existing ones,
+// since a database cannot be renamed while anyone is connected to it.
+func (a AtomicSwap) LockStatements() []string {
+	return []string{
+		fmt.Sprintf("ALTER DATABASE %s WITH ALLOW_CONNECTIONS false", quoteIdentifier(a.Target)),
+		fmt.Sprintf("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = %s AND pid <> pg_backend_pid()", quoteLiteral(a.Target)),
+	}
+}
+
+// RenameStatements move the restored database into place. They run in one
+// transaction, so clients never see the target name missing.
+func (a AtomicSwap) RenameStatements(targetExists bool) []string {
+	var statements []string
+	if targetExists {
+		statements = append(statements, fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", quoteIdentifier(a.Target), quoteIdentifier(a.Old)))
+	}
+	return append(statements, fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", quoteIdentifier(a.Temp), quoteIdentifier(a.Target)))
+}
+
+// UnlockStatement re-allows connections to the database that was locked: Old
+// after a successful swap, or Target when the rename was rolled back.
+func (a AtomicSwap) UnlockStatement(swapped bool) string {
+	name := a.Target
+	if swapped {
+		name = a.Old
+	}
+	return fmt.Sprintf("ALTER DATABASE %s WITH ALLOW_CONNECTIONS true", quoteIdentifier(name))
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+const restoredTablesQuery = `
+	SELECT count(*)
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind IN ('r', 'p')
+	AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+	AND n.nspname NOT LIKE 'pg_toast%'`
+
+// renameAttempts bounds the retries while terminated sessions are still
+// exiting; pg_terminate_backend does not wait for them.
+const renameAttempts = 10
+
+func (s *postgresService) restoreWithSwap(options RestoreOptions) error {
+	swap := NewAtomicSwap(options.TargetDatabase, time.Now())
+
+	admin, err := s.openAdminConnection()
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	exists := func(name string) (bool, error) {
+		var found bool
+		err := admin.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)", name).Scan(&found)
+		if err != nil {
+			return false, fmt.Errorf("failed to check database existence: %w", err)
+		}
+		return found, nil
+	}
+
+	targetExists, err := exists(swap.Target)
+	if err != nil {
+		return err
+	}
+	if targetExists {
+		oldExists, err := exists(swap.Old)
+		if err != nil {
+			return err
+		}
+		if oldExists {
+			return fmt.Errorf("database %s already exists from an earlier swap; drop or rename it first", swap.Old)
+		}
+	}
+
+	if _, err := admin.DB.Exec(fmt.Sprintf("CREATE DATABASE %s", quoteIdentifier(swap.Temp))); err != nil {
+		return fmt.Errorf("failed to create temporary database %s: %w", swap.Temp, err)
+	}
+	s.log.Infof("Restoring into temporary database %s", swap.Temp)
+
+	tempOptions := options
+	tempOptions.TargetDatabase = swap.Temp
+	tempOptions.CreateDatabase = false
+	tempOptions.CleanFirst = false
+	if err := s.restoreDump(tempOptions); err != nil {
+		s.dropTemporary(admin, swap.Temp)
+		return fmt.Errorf("%w; %s was left unchanged", err, swap.Target)
+	}
+	if err := s.verifyRestored(swap.Temp); err != nil {
+		s.dropTemporary(admin, swap.Temp)
+		return fmt.Errorf("%w; %s was left unchanged", err, swap.Target)
+	}
+
+	if targetExists {
+		s.log.Infof("Locking %s and ending its sessions", swap.Target)
+		for _, statement := range swap.LockStatements() {
+			if _, err := admin.DB.Exec(statement); err != nil {
+				// Connections may already be refused even though ending the
+				// sessions failed.
+				s.unlockTarget(admin, swap)
+				return fmt.Errorf("failed to lock %s (restored copy kept as %s): %w", swap.Target, swap.Temp, err)
+			}
+		}
+	}
+
+	if err := s.renameForSwap(admin, swap, targetExists); err != nil {
+		if targetExists {
+			s.unlockTarget(admin, swap)
+		}
+		return fmt.Errorf("failed to swap %s into place (restored copy kept as %s): %w", swap.Target, swap.Temp, err)
+	}
+
+	if targetExists {
+		if _, err := admin.DB.Exec(swap.UnlockStatement(true)); err != nil {
+			s.log.Warnf("failed to re-allow connections to %s: %v", swap.Old, err)
+		}
+		s.log.Infof("Swapped the restored database into %s; the previous one is now %s", swap.Target, swap.Old)
+	} else {
+		s.log.Infof("Renamed the restored database to %s", swap.Target)
+	}
+	return nil
+}
+
+// unlockTarget allows connections to the target again after a failed swap.
+func (s *postgresService) unlockTarget(admin *database.Connection, swap AtomicSwap) {
+	if _, err := admin.DB.Exec(swap.UnlockStatement(false)); err != nil {
+		s.log.Warnf("failed to re-allow connections to %s: %v", swap.Target, err)
+	}
+}
+
+// renameForSwap runs the renames in one transaction, retrying while sessions
+// that were just terminated still hold the database.
+func (s *postgresService) renameForSwap(admin *database.Connection, swap AtomicSwap, targetExists bool) error {
+	var err error
+	for attempt := 1; attempt <= renameAttempts; attempt++ {
+		if err = s.renameOnce(admin, swap.RenameStatements(targetExists)); !isObjectInUse(err) {
+			return err
+		}
+		s.log.Debugf("%s is still in use, retrying the rename (%d/%d)", swap.Target, attempt, renameAttempts)
+		time.Sleep(200 * time.Millisecond)
+	}
+	return err
+}
+
+func (s *postgresService) renameOnce(admin *database.Connection, statements []string) error {
+	tx, err := admin.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func isObjectInUse(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "55006"
+}
+
+// verifyRestored checks that the restore produced user tables before the
+// target is replaced with it.
+func (s *postgresService) verifyRestored(name string) error {
+	cfg := *s.cfg
+	cfg.Database.Database = name
+	conn, err := database.NewConnection(&cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to restored database %s: %w", name, err)
+	}
+	defer conn.Close()
+
+	var tables int
+	if err := conn.DB.QueryRow(restoredTablesQuery).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to check restored database %s: %w", name, err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("restored database %s has no tables", name)
+	}
+	s.log.Infof("Restored database %s has %d tables", name, tables)
+	return nil
+}
+
+// dropTemporary removes the temporary database of a swap that did not go
+// ahead. Failing to drop it only leaves a database to clean up by hand.
+func (s *postgresService) dropTemporary(admin *database.Connection, name string) {
+	if _, err := admin.DB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", quoteIdentifier(name))); err != nil {
+		s.log.Warnf("failed to drop temporary database %s: %v", name, err)
+	}
+}
@@ -12,12 +12,29 @@ type DatabaseInfo struct {
 }
 
 type BackupOptions struct {
-	Format      string
-	Compression int
-	SchemaOnly  bool
-	DataOnly    bool
-	OutputPath  string
-	Verbose     bool
+	Format         string
+	Compression    int
+	SchemaOnly     bool
+	DataOnly       bool
+	OutputPath     string
+	Verbose        bool
+	ReadPreference string
+	ExtraArgs      []string
+	StrictVersion  bool
+	DumpGlobals    bool
+	// Jobs dumps this many tables in parallel. pg_dump supports it only for
+	// the directory format, so it is ignored for the others.
+	Jobs int
+	// ExcludeTables and ExcludeSchemas are pg_dump patterns (e.g. "audit.*")
+	// for tables and schemas to leave out of the backup.
+	ExcludeTables  []string
+	ExcludeSchemas []string
+	// TableChecksums also digests every table and writes the result next to
+	// the backup (PostgreSQL only; reads every row once more).
+	TableChecksums bool
+	// FilenameTemplate names backups written without an OutputPath; see
+	// RenderFilename.
+	FilenameTemplate string
 }
 
 type RestoreOptions struct {
@@ -25,14 +42,46 @@ type RestoreOptions struct {
 	TargetDatabase string
 	CreateDatabase bool
 	CleanFirst     bool
+	IfExists       bool
 	Verbose        bool
 	ExitOnError    bool
+	ExtraArgs      []string
+	StrictVersion  bool
+	ApplyGlobals   bool
+	// AtomicSwap restores into a temporary database and renames it over
+	// TargetDatabase once it checks out (PostgreSQL only).
+	AtomicSwap bool
+	// TolerateExisting carries on past errors about objects or documents the
+	// target already has and reports how many there were; any other error
+	// still fails the restore.
+	TolerateExisting bool
+	// Jobs restores this many objects in parallel with pg_restore. Plain
+	// SQL and tar backups are always restored with one job.
+	Jobs int
+	// Format and Compression describe the backup. They are read from its
+	// .meta.json when there is one; otherwise the file name decides.
+	Format      string
+	Compression int
 }
 
+// BackupMetadata describes a finished backup. It is also written next to the
+// backup as <backup>.meta.json; see WriteMetadata.
 type BackupMetadata struct {
-	BackupSize  int64
-	Checksum    string
-	Location    string
-	StartedAt   time.Time
-	CompletedAt time.Time
+	Database    string    `json:"database"`
+	Engine      string    `json:"engine"`
+	Format      string    `json:"format"`
+	Compression int       `json:"compression"`
+	ToolVersion string    `json:"tool_version,omitempty"`
+	BackupSize  int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	Location    string    `json:"location"`
+	GlobalsPath string    `json:"globals_path,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+
+	// ChecksumsPath is the .checksums.json written with TableChecksums.
+	ChecksumsPath string `json:"checksums_path,omitempty"`
+	// Files holds the checksum of each file of a directory-format backup,
+	// keyed by relative path, so verify can name the files that changed.
+	Files map[string]string `json:"files,omitempty"`
 }
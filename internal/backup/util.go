@@ -5,7 +5,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 )
 
@@ -15,18 +18,92 @@ func buildBackupMetadata(path string, started time.Time) (*BackupMetadata, error
 		return nil, fmt.Errorf("failed to read backup metadata: %w", err)
 	}
 
-	checksum, err := fileChecksum(path)
-	if err != nil {
-		return nil, err
-	}
-
-	return &BackupMetadata{
-		BackupSize:  info.Size(),
-		Checksum:    checksum,
+	metadata := &BackupMetadata{
 		Location:    path,
 		StartedAt:   started,
 		CompletedAt: time.Now(),
-	}, nil
+	}
+	if info.IsDir() {
+		files, size, err := directoryChecksums(path)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Files = files
+		metadata.Checksum = combineChecksums(files)
+		metadata.BackupSize = size
+		return metadata, nil
+	}
+
+	if metadata.Checksum, err = fileChecksum(path); err != nil {
+		return nil, err
+	}
+	metadata.BackupSize = info.Size()
+	return metadata, nil
+}
+
+// pathChecksum returns the SHA-256 and size of a backup file. A directory
+// backup is hashed file by file in name order, each file's relative path
+// included, and its size is the total of its files.
+func pathChecksum(path string) (string, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if !info.IsDir() {
+		checksum, err := fileChecksum(path)
+		return checksum, info.Size(), err
+	}
+
+	files, size, err := directoryChecksums(path)
+	if err != nil {
+		return "", 0, err
+	}
+	return combineChecksums(files), size, nil
+}
+
+// directoryChecksums returns the SHA-256 of every file under a directory
+// backup, keyed by slash-separated relative path, and their total size.
+func directoryChecksums(path string) (map[string]string, int64, error) {
+	files := make(map[string]string)
+	var size int64
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		checksum, err := fileChecksum(file)
+		if err != nil {
+			return err
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += fileInfo.Size()
+		files[filepath.ToSlash(rel)] = checksum
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+	return files, size, nil
+}
+
+func combineChecksums(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hasher := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hasher, "%s\x00%s\n", name, files[name])
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
 }
 
 func fileChecksum(path string) (string, error) {
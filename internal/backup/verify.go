@@ -0,0 +1,96 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Errors VerifyBackup wraps, so callers can tell a damaged backup from one
+// that is not there.
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrBackupMissing    = errors.New("backup or metadata missing")
+)
+
+// VerifyResult compares a backup with the checksum recorded in its sidecar.
+type VerifyResult struct {
+	Path     string
+	Expected string
+	Actual   string
+	Size     int64
+	// Changed, Missing and Added name the files of a directory backup that
+	// differ from, are absent from, or are not in the sidecar.
+	Changed []string
+	Missing []string
+	Added   []string
+}
+
+func (r *VerifyResult) OK() bool {
+	return r.Expected == r.Actual && len(r.Changed) == 0 && len(r.Missing) == 0 && len(r.Added) == 0
+}
+
+// VerifyBackup recomputes the SHA-256 of a backup and compares it with its
+// .meta.json. Directory backups are checked file by file. The error wraps
+// ErrBackupMissing when the backup or its sidecar does not exist and
+// ErrChecksumMismatch when the contents changed.
+func VerifyBackup(path string) (*VerifyResult, error) {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrBackupMissing, path)
+		}
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	metadataPath := MetadataPath(path)
+	if _, err := os.Stat(metadataPath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s has no %s to verify against", ErrBackupMissing, path, metadataPath)
+	}
+	metadata, err := ReadMetadata(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Checksum == "" {
+		return nil, fmt.Errorf("%s records no checksum", metadataPath)
+	}
+
+	result := &VerifyResult{Path: path, Expected: metadata.Checksum}
+	if metadata.Files != nil {
+		files, size, err := directoryChecksums(path)
+		if err != nil {
+			return nil, err
+		}
+		result.Actual = combineChecksums(files)
+		result.Size = size
+		result.Changed, result.Missing, result.Added = compareFiles(metadata.Files, files)
+	} else if result.Actual, result.Size, err = pathChecksum(path); err != nil {
+		return nil, err
+	}
+
+	if !result.OK() {
+		return result, fmt.Errorf("%w: %s", ErrChecksumMismatch, path)
+	}
+	return result, nil
+}
+
+func compareFiles(recorded, current map[string]string) (changed, missing, added []string) {
+	for name, checksum := range recorded {
+		actual, ok := current[name]
+		switch {
+		case !ok:
+			missing = append(missing, name)
+		case actual != checksum:
+			changed = append(changed, name)
+		}
+	}
+	for name := range current {
+		if _, ok := recorded[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(missing)
+	sort.Strings(added)
+	return changed, missing, added
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// Version is a parsed tool or server version. Only the numeric prefix is kept.
+type Version struct {
+	Major int
+	Minor int
+	Raw   string
+}
+
+func (v Version) String() string {
+	return v.Raw
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.\d+)*`)
+
+// ParseVersion extracts the first dotted version number from tool or server
+// output, e.g. "pg_dump (PostgreSQL) 16.2 (Debian 16.2-1)" or
+// "mongodump version: 100.9.4".
+func ParseVersion(output string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return Version{}, fmt.Errorf("no version number found in %q", strings.TrimSpace(output))
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	minor := 0
+	if match[2] != "" {
+		minor, _ = strconv.Atoi(match[2])
+	}
+	return Version{Major: major, Minor: minor, Raw: match[0]}, nil
+}
+
+// mongoToolsSplitMajor is the first MongoDB Database Tools release versioned
+// independently of the server. Those releases support every server version
+// still in use, so only legacy tools are compared against the server.
+const mongoToolsSplitMajor = 100
+
+// VersionCheck pairs a client tool version with the server it will talk to.
+type VersionCheck struct {
+	Tool   string
+	Engine string
+	Client Version
+	Server Version
+}
+
+// Skewed reports whether the client tool is older than the server. pg_dump
+// and pg_restore cannot read or write the archive format of a newer major
+// release, and legacy mongo tools share the same limitation.
+func (c VersionCheck) Skewed() bool {
+	if c.Engine == "mongo" && c.Client.Major >= mongoToolsSplitMajor {
+		return false
+	}
+	return c.Client.Major < c.Server.Major
+}
+
+func (c VersionCheck) Message() string {
+	if c.Skewed() {
+		return fmt.Sprintf("%s %s is older than the %s server %s; install client tools for version %d or newer",
+			c.Tool, c.Client, c.Engine, c.Server, c.Server.Major)
+	}
+	return fmt.Sprintf("%s %s is compatible with the %s server %s", c.Tool, c.Client, c.Engine, c.Server)
+}
+
+// ToolsFor lists the external tools the backup service invokes for an engine.
+func ToolsFor(engine string) []string {
+	switch engine {
+	case "postgres":
+		return []string{"pg_dump", "pg_restore", "psql"}
+	case "mongo":
+		return []string{"mongodump", "mongorestore"}
+	default:
+		return nil
+	}
+}
+
+func clientToolVersion(cfg *config.Config, tool string) (Version, error) {
+	path, err := cfg.ToolPath(tool)
+	if err != nil {
+		return Version{}, err
+	}
+	output, err := exec.Command(path, "--version").CombinedOutput()
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to run %s --version: %w", path, err)
+	}
+	return ParseVersion(string(output))
+}
+
+// preflightVersion warns about client/server skew before running tool, or
+// fails when strict is set. A check that cannot run is only logged.
+func preflightVersion(service Service, tool string, strict bool, warnf func(string, ...interface{})) error {
+	check, err := service.CheckVersion(tool)
+	if err != nil {
+		warnf("unable to compare %s and server versions: %v", tool, err)
+		return nil
+	}
+
+	if !check.Skewed() {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("version skew: %s", check.Message())
+	}
+	warnf("version skew: %s", check.Message())
+	return nil
+}
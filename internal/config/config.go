@@ -6,25 +6,48 @@ import (
 	"os"
 	"strings"
 
+	"github.com/kadirbelkuyu/DBRTS/internal/engine"
+
 	"gopkg.in/yaml.v3"
 )
 
 type DatabaseConfig struct {
-	Type         string `yaml:"type"`
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	Database     string `yaml:"database"`
-	Username     string `yaml:"username"`
-	Password     string `yaml:"password"`
-	SSLMode      string `yaml:"sslmode"`
-	URI          string `yaml:"uri"`
-	AuthDatabase string `yaml:"auth_database"`
+	Type           string `yaml:"type"`
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	Database       string `yaml:"database"`
+	Username       string `yaml:"username"`
+	Password       string `yaml:"password"`
+	SSLMode        string `yaml:"sslmode"`
+	SSLRootCert    string `yaml:"sslrootcert"`
+	SSLCert        string `yaml:"sslcert"`
+	SSLKey         string `yaml:"sslkey"`
+	ClientEncoding string `yaml:"client_encoding"`
+	// ChannelBinding and SSLNegotiation are libpq's channel_binding and
+	// sslnegotiation (PostgreSQL 17). Empty leaves libpq's default.
+	ChannelBinding string `yaml:"channel_binding"`
+	SSLNegotiation string `yaml:"sslnegotiation"`
+	// ApplicationName identifies DBRTS connections in pg_stat_activity and
+	// MongoDB's currentOp. Empty means DefaultApplicationName.
+	ApplicationName string `yaml:"application_name"`
+	URI             string `yaml:"uri"`
+	AuthDatabase    string `yaml:"auth_database"`
 }
 
 type Config struct {
+	Version  int            `yaml:"version,omitempty"`
 	Database DatabaseConfig `yaml:"database"`
+
+	DatabaseFilter DatabaseFilter `yaml:"database_filter,omitempty"`
+	Tools          ToolPaths      `yaml:"tools,omitempty"`
+
+	// mongoDatabase overrides Database.Database for one run; it is never saved.
+	mongoDatabase string
 }
 
+// LoadConfig reads a config file. ${VAR} and ${VAR:-default} references in
+// the database section are expanded from the environment first, so secrets
+// can be injected at runtime instead of stored in the file.
 func LoadConfig(configPath string) (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -36,24 +59,102 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	config.Database.Type = normalizeDatabaseType(config.Database.Type)
+	if err := config.expandEnv(lookupEnv); err != nil {
+		return nil, err
+	}
 
-	if config.Database.Type == "postgres" && config.Database.SSLMode == "" {
-		config.Database.SSLMode = "disable"
+	if _, err := Migrate(&config); err != nil {
+		return nil, err
+	}
+	if err := config.resolveSecrets(); err != nil {
+		return nil, err
 	}
-	if config.Database.Type == "mongo" && config.Database.Port == 0 {
-		config.Database.Port = 27017
+	if err := config.applyDefaults(); err != nil {
+		return nil, err
 	}
 
 	return &config, nil
 }
 
+// NewConfig builds a config in memory, e.g. from command-line connection
+// flags, applying the same defaults and checks as LoadConfig. When the type
+// is omitted it is inferred from a mongodb:// URI, otherwise PostgreSQL.
+func NewConfig(db DatabaseConfig) (*Config, error) {
+	if db.Type == "" && isMongoURI(db.URI) {
+		db.Type = "mongo"
+	}
+	if db.Host == "" && db.URI == "" {
+		return nil, fmt.Errorf("a host or URI is required")
+	}
+
+	config := &Config{Version: CurrentVersion, Database: db}
+	if err := config.applyDefaults(); err != nil {
+		return nil, err
+	}
+	if config.Database.Type == "postgres" && config.Database.Port == 0 {
+		config.Database.Port = 5432
+	}
+
+	return config, nil
+}
+
+func (c *Config) applyDefaults() error {
+	c.Database.Type = normalizeDatabaseType(c.Database.Type)
+
+	if features, err := engine.Capabilities(c.Database.Type); err == nil && features.SupportsSSL && c.Database.SSLMode == "" {
+		c.Database.SSLMode = "disable"
+	}
+	if err := c.validateSSLFiles(); err != nil {
+		return err
+	}
+	if c.Database.Type == "mongo" && c.Database.Port == 0 {
+		c.Database.Port = 27017
+	}
+
+	return nil
+}
+
+// NormalizeHost treats the usual spellings of the local machine as one host,
+// since local profiles typically point at containers published on loopback.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	switch host {
+	case "", "localhost", "127.0.0.1", "::1", "[::1]":
+		return "localhost"
+	}
+	return host
+}
+
+func isMongoURI(uri string) bool {
+	return strings.HasPrefix(uri, "mongodb://") || strings.HasPrefix(uri, "mongodb+srv://")
+}
+
+// GetConnectionString returns the libpq connection string for the database,
+// as used by dblink and shown by dsn.
 func (c *Config) GetConnectionString() string {
+	return c.connectionString(true)
+}
+
+// DriverConnectionString returns the connection string for DBRTS's own
+// lib/pq connections. lib/pq knows neither channel binding nor direct SSL
+// negotiation, so those settings are left out, and refused when they would
+// otherwise be weakened.
+func (c *Config) DriverConnectionString() (string, error) {
+	if c.Database.ChannelBinding == "require" {
+		return "", fmt.Errorf("channel_binding=require is only supported by pg_dump, pg_restore and psql; DBRTS's own connections cannot use channel binding")
+	}
+	if c.Database.SSLNegotiation == "direct" {
+		return "", fmt.Errorf("sslnegotiation=direct is only supported by pg_dump, pg_restore and psql; DBRTS's own connections negotiate SSL the classic way")
+	}
+	return c.connectionString(false), nil
+}
+
+func (c *Config) connectionString(libpq bool) string {
 	if c.Database.Type != "" && c.Database.Type != "postgres" {
 		return ""
 	}
 
-	return fmt.Sprintf(
+	conn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host,
 		c.Database.Port,
@@ -62,13 +163,168 @@ func (c *Config) GetConnectionString() string {
 		c.Database.Database,
 		c.Database.SSLMode,
 	)
+
+	for _, param := range c.sslFileParams() {
+		conn += fmt.Sprintf(" %s=%s", param.key, quoteConnValue(param.path))
+	}
+	if libpq && c.Database.ChannelBinding != "" {
+		conn += fmt.Sprintf(" channel_binding=%s", quoteConnValue(c.Database.ChannelBinding))
+	}
+	if libpq && c.Database.SSLNegotiation != "" {
+		conn += fmt.Sprintf(" sslnegotiation=%s", quoteConnValue(c.Database.SSLNegotiation))
+	}
+
+	conn += fmt.Sprintf(" application_name=%s", quoteConnValue(c.ApplicationName()))
+
+	return conn
+}
+
+// DefaultApplicationName is reported to servers when neither the config nor
+// the operation names the connection.
+const DefaultApplicationName = "dbrts"
+
+// ApplicationName returns the name DBRTS connections report to the server.
+func (c *Config) ApplicationName() string {
+	if name := strings.TrimSpace(c.Database.ApplicationName); name != "" {
+		return name
+	}
+	return DefaultApplicationName
+}
+
+// WithPurpose returns a copy of the config whose connections identify the
+// operation, e.g. "dbrts-transfer-source". An explicitly configured
+// application name is kept as is.
+func (c *Config) WithPurpose(purpose string) *Config {
+	copied := *c
+	if strings.TrimSpace(copied.Database.ApplicationName) == "" && purpose != "" {
+		copied.Database.ApplicationName = DefaultApplicationName + "-" + purpose
+	}
+	return &copied
+}
+
+// WithMongoDatabase returns a copy of the config whose MongoDB operations use
+// the named database. The connection URI, and with it the database users
+// authenticate against, is left as configured.
+func (c *Config) WithMongoDatabase(name string) *Config {
+	copied := *c
+	copied.mongoDatabase = strings.TrimSpace(name)
+	return &copied
+}
+
+// MongoDatabase is the database MongoDB operations work on: the override set
+// with WithMongoDatabase, or the configured database.
+func (c *Config) MongoDatabase() string {
+	if c.mongoDatabase != "" {
+		return c.mongoDatabase
+	}
+	return c.Database.Database
+}
+
+type sslFileParam struct {
+	key  string
+	env  string
+	path string
+}
+
+func (c *Config) sslFileParams() []sslFileParam {
+	candidates := []sslFileParam{
+		{key: "sslrootcert", env: "PGSSLROOTCERT", path: c.Database.SSLRootCert},
+		{key: "sslcert", env: "PGSSLCERT", path: c.Database.SSLCert},
+		{key: "sslkey", env: "PGSSLKEY", path: c.Database.SSLKey},
+	}
+
+	var params []sslFileParam
+	for _, param := range candidates {
+		if strings.TrimSpace(param.path) != "" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// PostgresToolEnv returns the libpq environment variables pg_dump, pg_restore
+// and psql need to connect the same way GetConnectionString does. The client
+// encoding only applies to these tools: lib/pq requires UTF8, so the server
+// converts text for DBRTS's own connections.
+func (c *Config) PostgresToolEnv() []string {
+	var env []string
+	if c.Database.Password != "" {
+		env = append(env, fmt.Sprintf("PGPASSWORD=%s", c.Database.Password))
+	}
+	if c.Database.SSLMode != "" {
+		env = append(env, fmt.Sprintf("PGSSLMODE=%s", c.Database.SSLMode))
+	}
+	for _, param := range c.sslFileParams() {
+		env = append(env, fmt.Sprintf("%s=%s", param.env, param.path))
+	}
+	if c.Database.ChannelBinding != "" {
+		env = append(env, fmt.Sprintf("PGCHANNELBINDING=%s", c.Database.ChannelBinding))
+	}
+	if c.Database.SSLNegotiation != "" {
+		env = append(env, fmt.Sprintf("PGSSLNEGOTIATION=%s", c.Database.SSLNegotiation))
+	}
+	if c.Database.ClientEncoding != "" {
+		env = append(env, fmt.Sprintf("PGCLIENTENCODING=%s", c.Database.ClientEncoding))
+	}
+	env = append(env, fmt.Sprintf("PGAPPNAME=%s", c.ApplicationName()))
+	return env
+}
+
+// validateSSLFiles checks that configured certificate files exist whenever SSL
+// is in use, so verify-ca and verify-full fail at load time rather than on connect.
+func (c *Config) validateSSLFiles() error {
+	features, err := engine.Capabilities(c.Database.Type)
+	if err != nil || !features.SupportsSSL || c.Database.SSLMode == "disable" {
+		return nil
+	}
+
+	for _, param := range c.sslFileParams() {
+		// Only unexpanded configs (profile check) still have references.
+		if HasEnvReference(param.path) {
+			continue
+		}
+		if _, err := os.Stat(param.path); err != nil {
+			return fmt.Errorf("%s file %q is not readable: %w", param.key, param.path, err)
+		}
+	}
+	return nil
+}
+
+// quoteConnValue quotes a key/value connection string value when it contains
+// spaces or quotes.
+func quoteConnValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// MongoConnectionURI is GetMongoURI with appName set, used for every
+// connection DBRTS opens so it is identifiable in server monitoring.
+func (c *Config) MongoConnectionURI() string {
+	return withAppName(c.GetMongoURI(), c.ApplicationName())
 }
 
 func (c *Config) GetMongoURI() string {
 	if c.Database.URI != "" {
 		return c.Database.URI
 	}
+	return c.mongoHostURI()
+}
+
+// MongoFallbackURI is the connection URI built from the host fields of a
+// config that also sets a URI, for when the URI turns out to be unusable.
+// It reports false when there are no host fields to fall back to.
+func (c *Config) MongoFallbackURI() (string, bool) {
+	if c.Database.URI == "" || c.Database.Host == "" {
+		return "", false
+	}
+	return withAppName(c.mongoHostURI(), c.ApplicationName()), true
+}
 
+func (c *Config) mongoHostURI() string {
 	host := c.Database.Host
 	if host == "" {
 		host = "localhost"
@@ -101,6 +357,22 @@ func (c *Config) GetMongoURI() string {
 	return uri
 }
 
+// withAppName adds appName to a MongoDB URI unless the URI already sets it.
+func withAppName(uri, appName string) string {
+	if strings.Contains(strings.ToLower(uri), "appname=") {
+		return uri
+	}
+
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	} else if rest := uri[strings.Index(uri, "://")+3:]; !strings.Contains(rest, "/") {
+		// The connection string spec requires a slash before the options block.
+		separator = "/?"
+	}
+	return uri + separator + "appName=" + url.QueryEscape(appName)
+}
+
 func normalizeDatabaseType(dbType string) string {
 	dbType = strings.ToLower(strings.TrimSpace(dbType))
 	if dbType == "" {
@@ -116,3 +388,30 @@ func normalizeDatabaseType(dbType string) string {
 		return dbType
 	}
 }
+
+// redactedSecret matches the placeholder used by url.URL.Redacted.
+const redactedSecret = "xxxxx"
+
+// RedactedConnectionString returns GetConnectionString with the password masked.
+func (c *Config) RedactedConnectionString() string {
+	conn := c.GetConnectionString()
+	if conn == "" || c.Database.Password == "" {
+		return conn
+	}
+	return strings.Replace(conn, "password="+c.Database.Password, "password="+redactedSecret, 1)
+}
+
+// RedactedMongoURI returns GetMongoURI with any password in the userinfo masked.
+func (c *Config) RedactedMongoURI() string {
+	return RedactURI(c.GetMongoURI())
+}
+
+// RedactURI masks the password of a connection URI. URIs that cannot be parsed
+// are hidden entirely because they may still contain credentials.
+func RedactURI(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "<unparseable uri>"
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// DatabaseFilter narrows the databases listed by list-databases and offered
+// by the backup selector, e.g. to hide other tenants' databases on a shared
+// server. Patterns use shell syntax (app_*, tenant_?).
+type DatabaseFilter struct {
+	// Include keeps only matching databases; empty keeps all.
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+	// ConnectableOnly asks the server for databases the user may connect to
+	// (PostgreSQL CONNECT privilege, MongoDB authorizedDatabases).
+	ConnectableOnly bool `yaml:"connectable_only,omitempty"`
+}
+
+func (f DatabaseFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid database pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match reports whether a database passes the include and exclude patterns.
+// Exclude wins over include.
+func (f DatabaseFilter) Match(name string) bool {
+	if len(f.Include) > 0 && !matchAny(f.Include, name) {
+		return false
+	}
+	return !matchAny(f.Exclude, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
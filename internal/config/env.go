@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// envReference matches ${VAR} and ${VAR:-default}. A lone $ or a bare $VAR
+// is not a reference, so literal passwords containing $ are left alone.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// ExpandEnv replaces the ${VAR} and ${VAR:-default} references in value
+// using lookup. As in the shell, the default applies when the variable is
+// unset or empty; an unset variable without a default is an error.
+func ExpandEnv(value string, lookup func(string) (string, bool)) (string, error) {
+	var missing string
+	expanded := envReference.ReplaceAllStringFunc(value, func(reference string) string {
+		match := envReference.FindStringSubmatch(reference)
+		name, fallback := match[1], match[2]
+
+		if resolved, ok := lookup(name); ok && (resolved != "" || fallback == "") {
+			return resolved
+		}
+		if fallback != "" {
+			return strings.TrimPrefix(fallback, ":-")
+		}
+		if missing == "" {
+			missing = name
+		}
+		return reference
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return expanded, nil
+}
+
+// HasEnvReference reports whether value contains a ${...} reference.
+func HasEnvReference(value string) bool {
+	return envReference.MatchString(value)
+}
+
+// expandEnv expands environment references in every string field of the
+// database and tools sections, naming the field when a variable is missing.
+func (c *Config) expandEnv(lookup func(string) (string, bool)) error {
+	if err := expandFields(reflect.ValueOf(&c.Database).Elem(), "", lookup); err != nil {
+		return err
+	}
+	return expandFields(reflect.ValueOf(&c.Tools).Elem(), "tools.", lookup)
+}
+
+func expandFields(section reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	for i := 0; i < section.NumField(); i++ {
+		field := section.Field(i)
+		if field.Kind() != reflect.String {
+			continue
+		}
+
+		expanded, err := ExpandEnv(field.String(), lookup)
+		if err != nil {
+			name, _, _ := strings.Cut(section.Type().Field(i).Tag.Get("yaml"), ",")
+			return fmt.Errorf("%s%s: %w", prefix, name, err)
+		}
+		field.SetString(expanded)
+	}
+	return nil
+}
+
+func lookupEnv(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
+)
+
+// KeychainPrefix marks a password or URI value stored in the OS keychain,
+// written as keychain:<service>/<account>.
+const KeychainPrefix = "keychain:"
+
+// KeychainService is the service name profile set-secret stores entries under.
+const KeychainService = "dbrts"
+
+// ParseKeychainRef splits a keychain reference. ok is false for plain values.
+func ParseKeychainRef(value string) (service, account string, ok bool, err error) {
+	if !strings.HasPrefix(value, KeychainPrefix) {
+		return "", "", false, nil
+	}
+
+	service, account, found := strings.Cut(strings.TrimPrefix(value, KeychainPrefix), "/")
+	if !found || strings.TrimSpace(service) == "" || strings.TrimSpace(account) == "" {
+		return "", "", true, fmt.Errorf("invalid keychain reference %q (expected keychain:<service>/<account>)", value)
+	}
+	return service, account, true, nil
+}
+
+// KeychainRef formats the reference for a secret stored by profile set-secret.
+func KeychainRef(account string) string {
+	return KeychainPrefix + KeychainService + "/" + account
+}
+
+// resolveSecrets replaces keychain references in the password and URI with
+// the stored secrets. Configs without references never touch the keychain,
+// so a machine without one only fails for profiles that need it.
+func (c *Config) resolveSecrets() error {
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"password", &c.Database.Password},
+		{"uri", &c.Database.URI},
+	} {
+		service, account, ok, err := ParseKeychainRef(*field.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		secret, err := keyring.Get(service, account)
+		if err != nil {
+			if errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("%s: no keychain entry for %s/%s (store one with `dbrts profile set-secret`)", field.name, service, account)
+			}
+			return fmt.Errorf("%s: keychain unavailable (%v); put the %s in the config or use connection flags instead", field.name, err, field.name)
+		}
+		*field.value = secret
+	}
+	return nil
+}
+
+// StoreSecret saves a secret in the OS keychain under KeychainService.
+func StoreSecret(account, secret string) error {
+	if err := keyring.Set(KeychainService, account, secret); err != nil {
+		return fmt.Errorf("failed to store secret in keychain: %w", err)
+	}
+	return nil
+}
+
+// SetSecretRef points the password or uri field of a config file at a
+// keychain reference. Only that value is replaced; comments, key order and
+// keys this version does not know are kept, and other secrets are not resolved.
+func SetSecretRef(path, field, ref string) error {
+	if field != "password" && field != "uri" {
+		return fmt.Errorf("unsupported secret field %q (use password or uri)", field)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	doc, err := parseDocument(data)
+	if err != nil {
+		return err
+	}
+	database, err := childMapping(doc.Content[0], "database")
+	if err != nil {
+		return err
+	}
+	setMappingValue(database, field, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ref})
+
+	return writeDocument(path, doc)
+}
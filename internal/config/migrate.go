@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config file format written by this build. Files
+// without a version field predate versioning and are treated as version 0.
+const CurrentVersion = 1
+
+// migrations[i] upgrades a config from version i to i+1.
+var migrations = []func(*Config){
+	migrateV0,
+}
+
+// migrateV0 makes the defaults that were implied for unversioned files
+// explicit, so later versions can change defaults without altering them.
+func migrateV0(c *Config) {
+	c.Database.Type = normalizeDatabaseType(c.Database.Type)
+
+	switch c.Database.Type {
+	case "postgres":
+		if c.Database.Port == 0 {
+			c.Database.Port = 5432
+		}
+		if c.Database.SSLMode == "" {
+			c.Database.SSLMode = "disable"
+		}
+	case "mongo":
+		if c.Database.Port == 0 && c.Database.URI == "" {
+			c.Database.Port = 27017
+		}
+	}
+}
+
+// Migrate upgrades c to CurrentVersion and reports whether anything ran.
+// Configs from a newer version are rejected rather than guessed at.
+func Migrate(c *Config) (bool, error) {
+	if c.Version > CurrentVersion {
+		return false, fmt.Errorf("config version %d is newer than supported version %d; upgrade dbrts", c.Version, CurrentVersion)
+	}
+	if c.Version < 0 {
+		return false, fmt.Errorf("invalid config version %d", c.Version)
+	}
+
+	migrated := c.Version < CurrentVersion
+	for c.Version < CurrentVersion {
+		migrations[c.Version](c)
+		c.Version++
+	}
+	return migrated, nil
+}
+
+// MigrateFile rewrites the config at path in the current format. It returns
+// false without touching the file when it is already current. Only the
+// values the migrations change are written; comments, key order and unknown
+// keys are kept.
+func MigrateFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return false, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	original := config
+	migrated, err := Migrate(&config)
+	if err != nil || !migrated {
+		return false, err
+	}
+
+	doc, err := parseDocument(data)
+	if err != nil {
+		return false, err
+	}
+	root := doc.Content[0]
+	if mappingValue(root, "version") == nil {
+		// version leads the file, below any comment heading it.
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(config.Version)}
+		if len(root.Content) > 0 {
+			key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+		}
+		root.Content = append([]*yaml.Node{key, value}, root.Content...)
+	}
+
+	var before, after yaml.Node
+	if err := before.Encode(&original); err != nil {
+		return false, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := after.Encode(&config); err != nil {
+		return false, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := mergeChanged(root, &before, &after); err != nil {
+		return false, err
+	}
+
+	if err := writeDocument(path, doc); err != nil {
+		return false, err
+	}
+	return true, nil
+}
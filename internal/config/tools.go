@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// ToolPaths points at specific client tool binaries, e.g. the pg_dump of the
+// server's major version when several PostgreSQL versions are installed.
+// Empty fields use the tool found on PATH.
+type ToolPaths struct {
+	PgDump       string `yaml:"pg_dump_path,omitempty"`
+	PgDumpall    string `yaml:"pg_dumpall_path,omitempty"`
+	PgRestore    string `yaml:"pg_restore_path,omitempty"`
+	Psql         string `yaml:"psql_path,omitempty"`
+	Mongodump    string `yaml:"mongodump_path,omitempty"`
+	Mongorestore string `yaml:"mongorestore_path,omitempty"`
+}
+
+// configured returns the path set for tool and the name of its config field.
+func (t ToolPaths) configured(tool string) (string, string) {
+	switch tool {
+	case "pg_dump":
+		return t.PgDump, "pg_dump_path"
+	case "pg_dumpall":
+		return t.PgDumpall, "pg_dumpall_path"
+	case "pg_restore":
+		return t.PgRestore, "pg_restore_path"
+	case "psql":
+		return t.Psql, "psql_path"
+	case "mongodump":
+		return t.Mongodump, "mongodump_path"
+	case "mongorestore":
+		return t.Mongorestore, "mongorestore_path"
+	default:
+		return "", ""
+	}
+}
+
+// ToolPath returns the binary to run for a client tool: the configured path,
+// once it is checked to be an executable file, or the bare tool name.
+func (c *Config) ToolPath(tool string) (string, error) {
+	path, field := c.Tools.configured(tool)
+	if path == "" {
+		return tool, nil
+	}
+	if err := checkExecutable(path); err != nil {
+		return "", fmt.Errorf("tools.%s: %w", field, err)
+	}
+	return path, nil
+}
+
+func checkExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s does not exist", path)
+		}
+		return fmt.Errorf("cannot access %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not the tool binary", path)
+	}
+	// Windows has no executable bit; the extension decides.
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("%s is not executable", path)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validate reports every problem that would stop the config from being used
+// to connect, rather than only the first. It expects defaults to be applied.
+func (c *Config) Validate() []error {
+	var errs []error
+
+	db := c.Database
+	switch db.Type {
+	case "postgres", "mongo":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported database type %q (use postgres or mongo)", db.Type))
+	}
+
+	if db.Host == "" && db.URI == "" {
+		errs = append(errs, fmt.Errorf("a host or URI is required"))
+	}
+	if db.Port < 0 || db.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port %d is out of range", db.Port))
+	}
+	if db.Type == "postgres" && db.Host != "" && db.Port == 0 {
+		errs = append(errs, fmt.Errorf("port is not set"))
+	}
+	if db.ChannelBinding != "" && !contains(ChannelBindings, db.ChannelBinding) {
+		errs = append(errs, fmt.Errorf("channel_binding %q is not one of %s", db.ChannelBinding, strings.Join(ChannelBindings, ", ")))
+	}
+	if db.SSLNegotiation != "" && !contains(SSLNegotiations, db.SSLNegotiation) {
+		errs = append(errs, fmt.Errorf("sslnegotiation %q is not one of %s", db.SSLNegotiation, strings.Join(SSLNegotiations, ", ")))
+	}
+	if db.Type == "mongo" && db.URI != "" && !isMongoURI(db.URI) && !HasEnvReference(db.URI) {
+		errs = append(errs, fmt.Errorf("uri must start with mongodb:// or mongodb+srv://"))
+	}
+	if err := c.DatabaseFilter.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// SSLModes are the sslmode values libpq accepts.
+var SSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// ChannelBindings and SSLNegotiations are the channel_binding and
+// sslnegotiation values libpq accepts.
+var (
+	ChannelBindings = []string{"disable", "prefer", "require"}
+	SSLNegotiations = []string{"postgres", "direct"}
+)
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationCheck is one rule Checks applied to a config. Err is nil when it
+// passed. A failed check that is not Required is only a warning.
+type ValidationCheck struct {
+	Field    string
+	Required bool
+	Detail   string
+	Err      error
+}
+
+func (v ValidationCheck) Passed() bool {
+	return v.Err == nil
+}
+
+// Checks applies the per-engine rules for a usable profile and reports each
+// one, passed or not. PostgreSQL needs a host, port, username and database;
+// MongoDB needs a URI or a host and database. No connection is made.
+func (c *Config) Checks() []ValidationCheck {
+	db := c.Database
+	var checks []ValidationCheck
+	add := func(field string, required bool, detail string, err error) {
+		checks = append(checks, ValidationCheck{Field: field, Required: required, Detail: detail, Err: err})
+	}
+	requireSet := func(field, value string) {
+		if value == "" {
+			add(field, true, "", fmt.Errorf("%s is required", field))
+		} else {
+			add(field, true, value, nil)
+		}
+	}
+	checkPort := func() {
+		if db.Port < 1 || db.Port > 65535 {
+			add("port", true, "", fmt.Errorf("port %d is out of range (1-65535)", db.Port))
+		} else {
+			add("port", true, fmt.Sprintf("%d", db.Port), nil)
+		}
+	}
+
+	switch db.Type {
+	case "postgres":
+		add("type", true, db.Type, nil)
+		requireSet("host", db.Host)
+		checkPort()
+		requireSet("username", db.Username)
+		requireSet("database", db.Database)
+
+		known := false
+		for _, mode := range SSLModes {
+			known = known || db.SSLMode == mode
+		}
+		if known {
+			add("sslmode", true, db.SSLMode, nil)
+		} else {
+			add("sslmode", true, "", fmt.Errorf("sslmode %q is not one of %s", db.SSLMode, strings.Join(SSLModes, ", ")))
+		}
+
+		if db.Password == "" {
+			add("password", false, "", fmt.Errorf("password is empty; the server or ~/.pgpass must allow the login without one"))
+		} else {
+			add("password", false, "set", nil)
+		}
+	case "mongo":
+		add("type", true, db.Type, nil)
+		switch {
+		case db.URI != "" && !isMongoURI(db.URI):
+			add("uri", true, "", fmt.Errorf("uri must start with mongodb:// or mongodb+srv://"))
+		case db.URI != "":
+			add("uri", true, "set", nil)
+		default:
+			requireSet("host", db.Host)
+			checkPort()
+			requireSet("database", db.Database)
+		}
+	default:
+		add("type", true, "", fmt.Errorf("unsupported database type %q (use postgres or mongo)", db.Type))
+	}
+
+	for _, tool := range []string{"pg_dump", "pg_dumpall", "pg_restore", "psql", "mongodump", "mongorestore"} {
+		path, field := c.Tools.configured(tool)
+		if path == "" {
+			continue
+		}
+		if err := checkExecutable(path); err != nil {
+			add("tools."+field, true, "", err)
+		} else {
+			add("tools."+field, true, path, nil)
+		}
+	}
+
+	if err := c.DatabaseFilter.Validate(); err != nil {
+		add("database_filter", true, "", err)
+	} else if len(c.DatabaseFilter.Include)+len(c.DatabaseFilter.Exclude) > 0 {
+		add("database_filter", true, "patterns are valid", nil)
+	}
+
+	return checks
+}
+
+// FileCheck is the result of CheckFile.
+type FileCheck struct {
+	// Outdated is set for files in an older config format; MigrateFile
+	// rewrites them with their implied defaults made explicit.
+	Outdated bool
+	Errors   []error
+}
+
+func (f FileCheck) Valid() bool {
+	return len(f.Errors) == 0
+}
+
+// CheckFile loads the config at path the way LoadConfig does and collects
+// every problem found. Keychain and environment references are not resolved,
+// so the result does not depend on the shell it runs in.
+func CheckFile(path string) FileCheck {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileCheck{Errors: []error{fmt.Errorf("failed to read config file: %w", err)}}
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return FileCheck{Errors: []error{fmt.Errorf("failed to parse config: %w", err)}}
+	}
+
+	check := FileCheck{Outdated: config.Version < CurrentVersion}
+	if _, err := Migrate(&config); err != nil {
+		check.Outdated = false
+		check.Errors = append(check.Errors, err)
+		return check
+	}
+	if err := config.applyDefaults(); err != nil {
+		check.Errors = append(check.Errors, err)
+	}
+	check.Errors = append(check.Errors, config.Validate()...)
+	return check
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config files are edited as YAML node trees rather than through Config, so
+// that comments, key order and keys this version does not know survive a
+// rewrite.
+
+// parseDocument parses a config file into a document whose content is the
+// top-level mapping. An empty file yields an empty mapping.
+func parseDocument(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config: expected a mapping at the top level")
+	}
+	return &doc, nil
+}
+
+// writeDocument writes doc back to path, keeping the file's permissions.
+func writeDocument(path string, doc *yaml.Node) error {
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, out.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a YAML mapping, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value for key in place, keeping its line
+// comment, or appends the pair.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			if value.LineComment == "" {
+				value.LineComment = mapping.Content[i+1].LineComment
+			}
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// childMapping returns the mapping under key, adding an empty one if the key
+// is missing.
+func childMapping(mapping *yaml.Node, key string) (*yaml.Node, error) {
+	child := mappingValue(mapping, key)
+	if child == nil {
+		child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setMappingValue(mapping, key, child)
+	}
+	if child.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config: %s is not a mapping", key)
+	}
+	return child, nil
+}
+
+// mergeChanged copies into mapping the values that differ between before and
+// after, two encodings of the same struct, and leaves every other node alone.
+func mergeChanged(mapping, before, after *yaml.Node) error {
+	for i := 0; i+1 < len(after.Content); i += 2 {
+		key, value := after.Content[i].Value, after.Content[i+1]
+		previous := mappingValue(before, key)
+		if previous != nil && sameNode(previous, value) {
+			continue
+		}
+
+		if previous != nil && previous.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			child, err := childMapping(mapping, key)
+			if err != nil {
+				return err
+			}
+			if err := mergeChanged(child, previous, value); err != nil {
+				return err
+			}
+			continue
+		}
+		setMappingValue(mapping, key, value)
+	}
+	return nil
+}
+
+func sameNode(a, b *yaml.Node) bool {
+	left, err := yaml.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := yaml.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(left, right)
+}
@@ -19,7 +19,12 @@ func NewConnection(cfg *config.Config) (*Connection, error) {
 		return nil, fmt.Errorf("unsupported database type for SQL connection: %s", cfg.Database.Type)
 	}
 
-	db, err := sql.Open("postgres", cfg.GetConnectionString())
+	connStr, err := cfg.DriverConnectionString()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// MongoConnector opens a client for uri and checks that it can reach the
+// server. ConnectMongo takes one so tests can stand in for a live server.
+type MongoConnector func(ctx context.Context, uri string) (*mongo.Client, error)
+
+// PingMongo connects to uri and pings the primary.
+func PingMongo(ctx context.Context, uri string) (*mongo.Client, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+	return client, nil
+}
+
+// MongoEndpoint is one way of reaching a MongoDB server from a config.
+type MongoEndpoint struct {
+	Source string
+	URI    string
+}
+
+// MongoEndpoints lists the URIs to try for cfg in order: the configured URI,
+// then one built from the host fields when the config has both.
+func MongoEndpoints(cfg *config.Config) []MongoEndpoint {
+	if cfg.Database.URI == "" {
+		return []MongoEndpoint{{Source: "host fields", URI: cfg.MongoConnectionURI()}}
+	}
+
+	endpoints := []MongoEndpoint{{Source: "uri", URI: cfg.MongoConnectionURI()}}
+	if fallback, ok := cfg.MongoFallbackURI(); ok {
+		endpoints = append(endpoints, MongoEndpoint{Source: "host fields", URI: fallback})
+	}
+	return endpoints
+}
+
+// ConnectMongo connects through the first endpoint of cfg that works. A
+// failure on the configured URI is logged and the host fields are tried next,
+// so a profile whose URI has gone stale still connects. When every endpoint
+// fails, the URI's error is returned.
+func ConnectMongo(ctx context.Context, cfg *config.Config, connect MongoConnector, log *logger.Logger) (*mongo.Client, error) {
+	endpoints := MongoEndpoints(cfg)
+
+	var firstErr error
+	for i, endpoint := range endpoints {
+		attemptCtx, cancel := attemptContext(ctx, len(endpoints)-i)
+		client, err := connect(attemptCtx, endpoint.URI)
+		cancel()
+		if err == nil {
+			if i > 0 {
+				log.Warnf("Connected to MongoDB using the %s after the %s failed: %v", endpoint.Source, endpoints[0].Source, firstErr)
+			} else {
+				log.Debugf("Connected to MongoDB using the %s", endpoint.Source)
+			}
+			return client, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+		if i+1 < len(endpoints) {
+			log.Debugf("MongoDB connection using the %s failed: %v", endpoint.Source, err)
+		}
+	}
+	return nil, firstErr
+}
+
+// attemptContext gives the next of remaining attempts an equal share of
+// ctx's deadline, so an unreachable URI cannot use up the fallback's time.
+func attemptContext(ctx context.Context, remaining int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || remaining <= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(remaining))
+}
@@ -0,0 +1,68 @@
+package engine
+
+import "fmt"
+
+// Features describes what DBRTS supports for one database engine, so prompts,
+// flag validation and defaults consult one table rather than comparing
+// engine names wherever they differ.
+type Features struct {
+	// Name is how the engine is shown to users.
+	Name string
+	// Formats are the backup formats the engine can write.
+	Formats       []string
+	DefaultFormat string
+	// DefaultCompression is the level a backup uses unless told otherwise.
+	// MongoDB archives are either gzipped (1) or not (0).
+	DefaultCompression int
+	// SupportsSchemaOnly means a backup can be limited to the schema or to
+	// the data.
+	SupportsSchemaOnly bool
+	// SupportsSSL means the profile's sslmode and certificate files apply.
+	// MongoDB takes its TLS settings from the URI instead.
+	SupportsSSL bool
+	// SchemaNoun and DataNoun name what a transfer copies with schema only
+	// and with data only.
+	SchemaNoun string
+	DataNoun   string
+}
+
+// SupportsFormat reports whether the engine can write backups in format,
+// which is expected to be normalized already.
+func (f Features) SupportsFormat(format string) bool {
+	for _, candidate := range f.Formats {
+		if candidate == format {
+			return true
+		}
+	}
+	return false
+}
+
+var engines = map[string]Features{
+	"postgres": {
+		Name:               "PostgreSQL",
+		Formats:            []string{"custom", "sql", "tar", "directory"},
+		DefaultFormat:      "custom",
+		DefaultCompression: 6,
+		SupportsSchemaOnly: true,
+		SupportsSSL:        true,
+		SchemaNoun:         "schema",
+		DataNoun:           "data",
+	},
+	"mongo": {
+		Name:               "MongoDB",
+		Formats:            []string{"archive"},
+		DefaultFormat:      "archive",
+		DefaultCompression: 1,
+		SchemaNoun:         "indexes",
+		DataNoun:           "documents",
+	},
+}
+
+// Capabilities returns the features of a normalized database type.
+func Capabilities(dbType string) (Features, error) {
+	features, ok := engines[dbType]
+	if !ok {
+		return Features{}, fmt.Errorf("unsupported database type: %s", dbType)
+	}
+	return features, nil
+}
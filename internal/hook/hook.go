@@ -0,0 +1,120 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+const (
+	StatusStarting = "starting"
+	StatusSuccess  = "success"
+	StatusFailure  = "failure"
+)
+
+const defaultTimeout = 5 * time.Minute
+
+// Hooks are shell commands run around an operation. A failing pre-hook aborts
+// the operation unless ContinueOnPreFailure is set; post-hook failures are
+// only logged because the operation has already finished.
+type Hooks struct {
+	Pre                  string
+	Post                 string
+	ContinueOnPreFailure bool
+	Timeout              time.Duration
+	Logger               *logger.Logger
+}
+
+// Event describes the operation a hook runs for. It is exposed to the hook
+// through DBRTS_* environment variables.
+type Event struct {
+	Operation string
+	Database  string
+	Status    string
+	Path      string
+	Error     string
+}
+
+func (e Event) Env() []string {
+	return []string{
+		"DBRTS_OPERATION=" + e.Operation,
+		"DBRTS_DATABASE=" + e.Database,
+		"DBRTS_STATUS=" + e.Status,
+		"DBRTS_PATH=" + e.Path,
+		"DBRTS_ERROR=" + e.Error,
+	}
+}
+
+// Around runs the pre-hook, then fn, then the post-hook with the outcome of fn.
+// fn returns the artifact path (backup file, restored dump) when there is one.
+func (h Hooks) Around(operation, database string, fn func() (string, error)) error {
+	if h.Pre != "" {
+		event := Event{Operation: operation, Database: database, Status: StatusStarting}
+		if err := h.Run(h.Pre, event); err != nil {
+			if !h.ContinueOnPreFailure {
+				return fmt.Errorf("pre-hook failed, %s aborted: %w", operation, err)
+			}
+			h.warnf("pre-hook failed, continuing: %v", err)
+		}
+	}
+
+	path, opErr := fn()
+
+	if h.Post != "" {
+		event := Event{Operation: operation, Database: database, Status: StatusSuccess, Path: path}
+		if opErr != nil {
+			event.Status = StatusFailure
+			event.Error = opErr.Error()
+		}
+		if err := h.Run(h.Post, event); err != nil {
+			h.warnf("post-hook failed: %v", err)
+		}
+	}
+
+	return opErr
+}
+
+// Run executes command through the platform shell with the event environment.
+func (h Hooks) Run(command string, event Event) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), event.Env()...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%q timed out after %s", command, timeout)
+		}
+		return fmt.Errorf("%q: %w", command, err)
+	}
+	return nil
+}
+
+func (h Hooks) warnf(format string, args ...interface{}) {
+	if h.Logger != nil {
+		h.Logger.Warnf(format, args...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", strings.TrimSpace(command))
+}
@@ -0,0 +1,183 @@
+package preset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDir is where presets live, next to the saved connection configs.
+var DefaultDir = filepath.Join("configs", "presets")
+
+// TransferPreset captures a reusable set of transfer options. Connection
+// details are deliberately excluded; presets still require source/target configs.
+type TransferPreset struct {
+	SchemaOnly      bool   `yaml:"schema_only,omitempty"`
+	DataOnly        bool   `yaml:"data_only,omitempty"`
+	Workers         int    `yaml:"workers,omitempty"`
+	BatchSize       int    `yaml:"batch_size,omitempty"`
+	SplitThreshold  int64  `yaml:"split_threshold,omitempty"`
+	IdentifierCase  string `yaml:"identifier_case,omitempty"`
+	DisableTriggers bool   `yaml:"disable_triggers,omitempty"`
+	PreserveStorage bool   `yaml:"preserve_storage,omitempty"`
+	VerifyChecksums bool   `yaml:"verify_checksums,omitempty"`
+	Append          bool   `yaml:"append,omitempty"`
+	SchemaDiff      bool   `yaml:"schema_diff,omitempty"`
+	UseCopy         bool   `yaml:"use_copy,omitempty"`
+
+	ConsistentSnapshot bool `yaml:"consistent_snapshot,omitempty"`
+	RegenerateIDs      bool `yaml:"regenerate_ids,omitempty"`
+
+	UnkeyedStrategy string `yaml:"unkeyed_strategy,omitempty"`
+}
+
+func FromOptions(opts transfer.Options) TransferPreset {
+	return TransferPreset{
+		SchemaOnly:      opts.SchemaOnly,
+		DataOnly:        opts.DataOnly,
+		Workers:         opts.ParallelWorkers,
+		BatchSize:       opts.BatchSize,
+		SplitThreshold:  opts.SplitThreshold,
+		IdentifierCase:  opts.IdentifierCase,
+		DisableTriggers: opts.DisableTriggers,
+		PreserveStorage: opts.PreserveStorage,
+		VerifyChecksums: opts.VerifyChecksums,
+		Append:          opts.Append,
+		SchemaDiff:      opts.SchemaDiff,
+		UseCopy:         opts.UseCopy,
+
+		ConsistentSnapshot: opts.ConsistentSnapshot,
+		RegenerateIDs:      opts.RegenerateIDs,
+
+		UnkeyedStrategy: opts.UnkeyedStrategy,
+	}
+}
+
+// Merge layers flag values over the preset. changed reports whether a flag
+// was set explicitly on the command line; only those flags override the preset.
+func (p TransferPreset) Merge(flags transfer.Options, changed func(flag string) bool) transfer.Options {
+	merged := flags
+
+	if !changed("schema-only") {
+		merged.SchemaOnly = p.SchemaOnly
+	}
+	if !changed("data-only") {
+		merged.DataOnly = p.DataOnly
+	}
+	if !changed("workers") && p.Workers > 0 {
+		merged.ParallelWorkers = p.Workers
+	}
+	if !changed("batch-size") && p.BatchSize > 0 {
+		merged.BatchSize = p.BatchSize
+	}
+	if !changed("split-threshold") && p.SplitThreshold > 0 {
+		merged.SplitThreshold = p.SplitThreshold
+	}
+	if !changed("identifier-case") && p.IdentifierCase != "" {
+		merged.IdentifierCase = p.IdentifierCase
+	}
+	if !changed("disable-triggers") {
+		merged.DisableTriggers = p.DisableTriggers
+	}
+	if !changed("preserve-storage") {
+		merged.PreserveStorage = p.PreserveStorage
+	}
+	if !changed("verify-checksums") {
+		merged.VerifyChecksums = p.VerifyChecksums
+	}
+	if !changed("append") {
+		merged.Append = p.Append
+	}
+	if !changed("schema-diff") {
+		merged.SchemaDiff = p.SchemaDiff
+	}
+	if !changed("copy") {
+		merged.UseCopy = p.UseCopy
+	}
+	if !changed("consistent-snapshot") {
+		merged.ConsistentSnapshot = p.ConsistentSnapshot
+	}
+	if !changed("regenerate-ids") {
+		merged.RegenerateIDs = p.RegenerateIDs
+	}
+	if !changed("unkeyed-strategy") && p.UnkeyedStrategy != "" {
+		merged.UnkeyedStrategy = p.UnkeyedStrategy
+	}
+
+	return merged
+}
+
+func Save(dir, name string, p TransferPreset) error {
+	path, err := presetPath(dir, name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create preset directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode preset: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func Load(dir, name string) (*TransferPreset, error) {
+	path, err := presetPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("preset %q not found in %s", name, dir)
+		}
+		return nil, fmt.Errorf("failed to read preset: %w", err)
+	}
+
+	var p TransferPreset
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse preset %q: %w", name, err)
+	}
+
+	return &p, nil
+}
+
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read preset directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func presetPath(dir, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid preset name %q", name)
+	}
+	return filepath.Join(dir, name+".yaml"), nil
+}
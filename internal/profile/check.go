@@ -0,0 +1,73 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// QuarantineDir is where Quarantine moves broken profiles, inside the profile
+// directory. Files and the interactive picker do not look into it.
+const QuarantineDir = ".quarantine"
+
+// FileStatus is one saved profile and what is wrong with it, if anything.
+type FileStatus struct {
+	Path string
+	config.FileCheck
+}
+
+// Check inspects every saved profile in dir, including the ones the
+// interactive picker skips because they fail to load.
+func Check(dir string) ([]FileStatus, error) {
+	files, err := Files(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]FileStatus, 0, len(files))
+	for _, path := range files {
+		statuses = append(statuses, FileStatus{Path: path, FileCheck: config.CheckFile(path)})
+	}
+	return statuses, nil
+}
+
+// Quarantine moves a profile into QuarantineDir and returns its new path. When
+// a file of the same name is already quarantined, a counter is added before the
+// extension (broken.1.yaml, broken.2.yaml, ...) so the earlier one is kept.
+func Quarantine(dir, path string) (string, error) {
+	target := filepath.Join(dir, QuarantineDir)
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+
+	moved, err := freeQuarantinePath(target, filepath.Base(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to quarantine %s: %w", path, err)
+	}
+	if err := os.Rename(path, moved); err != nil {
+		return "", fmt.Errorf("failed to quarantine %s: %w", path, err)
+	}
+	return moved, nil
+}
+
+func freeQuarantinePath(target, name string) (string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+
+	candidate := filepath.Join(target, name)
+	for i := 1; ; i++ {
+		_, err := os.Lstat(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		candidate = filepath.Join(target, fmt.Sprintf("%s.%d%s", stem, i, ext))
+	}
+}
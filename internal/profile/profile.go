@@ -0,0 +1,138 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDir holds the saved connection profiles, one YAML file per profile.
+const DefaultDir = "configs"
+
+const stateFileName = ".state.yaml"
+
+// ErrNoConfig is returned when neither --config nor a default profile is available.
+var ErrNoConfig = errors.New("no --config given and no default profile set (use `dbrts profile set-default <name>`)")
+
+type state struct {
+	DefaultProfile string `yaml:"default_profile,omitempty"`
+}
+
+// Path returns the config file for a profile name, accepting names with or
+// without the .yaml/.yml extension.
+func Path(dir, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+
+	if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		return filepath.Join(dir, name), nil
+	}
+
+	for _, ext := range []string{".yaml", ".yml"} {
+		candidate := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return filepath.Join(dir, name+".yaml"), nil
+}
+
+// Files lists the saved profile config files in dir.
+func Files(dir string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if filepath.Base(match) != stateFileName {
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func SetDefault(dir, name string) error {
+	path, err := Path(dir, name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("profile %q not found: %w", name, err)
+	}
+
+	st, err := loadState(dir)
+	if err != nil {
+		return err
+	}
+	st.DefaultProfile = name
+
+	return saveState(dir, st)
+}
+
+// Default returns the default profile name, or an empty string when none is set.
+func Default(dir string) (string, error) {
+	st, err := loadState(dir)
+	if err != nil {
+		return "", err
+	}
+	return st.DefaultProfile, nil
+}
+
+// ResolveConfigPath picks the config file for a command: an explicit flag
+// wins, then the default profile, otherwise ErrNoConfig.
+func ResolveConfigPath(flagValue, dir string) (string, error) {
+	if strings.TrimSpace(flagValue) != "" {
+		return flagValue, nil
+	}
+
+	name, err := Default(dir)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return "", ErrNoConfig
+	}
+
+	return Path(dir, name)
+}
+
+func loadState(dir string) (*state, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &state{}, nil
+		}
+		return nil, fmt.Errorf("failed to read profile state: %w", err)
+	}
+
+	var st state
+	if err := yaml.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse profile state: %w", err)
+	}
+	return &st, nil
+}
+
+func saveState(dir string, st *state) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile state: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, stateFileName), data, 0o644)
+}
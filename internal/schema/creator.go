@@ -1,179 +1,530 @@
 package schema
 
 import (
+	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/database"
 	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
 )
 
+type CreateOptions struct {
+	IdentifierCase  string
+	PreserveStorage bool
+	// SchemaMap creates the objects of a source schema in another schema on
+	// the target, which is created when missing.
+	SchemaMap map[string]string
+}
+
 type Creator struct {
-	conn   *database.Connection
-	logger *logger.Logger
+	conn    *database.Connection
+	logger  *logger.Logger
+	options CreateOptions
 }
 
-func NewCreator(conn *database.Connection, logger *logger.Logger) *Creator {
+func NewCreator(conn *database.Connection, logger *logger.Logger, options CreateOptions) *Creator {
 	return &Creator{
-		conn:   conn,
-		logger: logger,
+		conn:    conn,
+		logger:  logger,
+		options: options,
 	}
 }
 
+// Objects groups the schema objects the creator can recreate on the target.
+type Objects struct {
+	Extensions []Extension
+	Domains    []Domain
+	Sequences  []Sequence
+	Tables     []Table
+}
+
+// Statement is a single DDL step of a schema creation plan. Best-effort
+// statements log a warning on failure instead of aborting the transaction.
+type Statement struct {
+	SQL        string
+	Object     string
+	BestEffort bool
+}
+
 func (c *Creator) CreateTables(tables []Table) error {
+	return c.CreateSchema(Objects{Tables: tables})
+}
+
+func (c *Creator) CreateSchema(objects Objects) error {
 	c.logger.Logger.Info("Creating tables...")
 
+	c.warnUnavailableExtensions(objects.Extensions)
+	c.warnMissingPolicyRoles(objects.Tables)
+	if c.options.PreserveStorage {
+		objects.Tables = c.dropMissingTablespaces(objects.Tables)
+	}
+
+	if err := c.execPlan(c.PlanSchema(objects)); err != nil {
+		return err
+	}
+
+	c.logger.Logger.Infof("%d tables created successfully", len(objects.Tables))
+	return nil
+}
+
+// execPlan runs the statements in one transaction on the target.
+func (c *Creator) execPlan(plan []Statement) error {
 	tx, err := c.conn.DB.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	for _, table := range tables {
-		if err := c.createTable(tx, table); err != nil {
-			return fmt.Errorf("failed to create table %s.%s: %w", table.Schema, table.Name, err)
+	for _, stmt := range plan {
+		c.logger.Logger.Debugf("Creating %s: %s", stmt.Object, stmt.SQL)
+
+		if !stmt.BestEffort {
+			if _, err := tx.Exec(stmt.SQL); err != nil {
+				return fmt.Errorf("failed to create %s: %w", stmt.Object, err)
+			}
+			continue
+		}
+
+		if err := execSavepoint(tx, stmt.SQL); err != nil {
+			c.logger.Logger.Warnf("Failed to create %s: %v", stmt.Object, err)
 		}
 	}
 
-	for _, table := range tables {
-		if err := c.createIndexes(tx, table); err != nil {
-			return fmt.Errorf("failed to create indexes for %s.%s: %w", table.Schema, table.Name, err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// PlanSchema orders the DDL needed to recreate objects: mapped schemas,
+// extensions, domains and sequences first, so column defaults can call
+// nextval, then tables, secondary indexes, and finally foreign keys and row
+// level security policies once every table they may reference exists.
+func (c *Creator) PlanSchema(objects Objects) []Statement {
+	var plan []Statement
+
+	for _, name := range c.mappedSchemas(objects) {
+		plan = append(plan, Statement{
+			SQL:        fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", QuoteIdentifier(name)),
+			Object:     fmt.Sprintf("schema %s", name),
+			BestEffort: true,
+		})
+	}
+
+	for _, ext := range objects.Extensions {
+		plan = append(plan, Statement{
+			SQL:        BuildCreateExtensionSQL(ext),
+			Object:     fmt.Sprintf("extension %s", ext.Name),
+			BestEffort: true,
+		})
+	}
+
+	for _, domain := range objects.Domains {
+		plan = append(plan, Statement{
+			SQL:        c.BuildCreateDomainSQL(domain),
+			Object:     fmt.Sprintf("domain %s.%s", domain.Schema, domain.Name),
+			BestEffort: true,
+		})
+	}
+
+	for _, seq := range objects.Sequences {
+		plan = append(plan, Statement{
+			SQL:    c.BuildCreateSequenceSQL(seq),
+			Object: fmt.Sprintf("sequence %s.%s", seq.Schema, seq.Name),
+		})
+	}
+
+	for _, table := range objects.Tables {
+		plan = append(plan, Statement{
+			SQL:    c.BuildCreateTableSQL(table),
+			Object: fmt.Sprintf("table %s.%s", table.Schema, table.Name),
+		})
+	}
+
+	for _, table := range objects.Tables {
+		for _, idx := range table.Indexes {
+			if idx.IsPrimary {
+				continue
+			}
+			plan = append(plan, Statement{
+				SQL:        c.BuildCreateIndexSQL(table, idx),
+				Object:     fmt.Sprintf("index %s", idx.Name),
+				BestEffort: true,
+			})
 		}
 	}
 
-	for _, table := range tables {
-		if err := c.createForeignKeys(tx, table); err != nil {
-			return fmt.Errorf("failed to create foreign keys for %s.%s: %w", table.Schema, table.Name, err)
+	for _, table := range objects.Tables {
+		for _, fk := range table.ForeignKeys {
+			plan = append(plan, Statement{
+				SQL:        c.BuildForeignKeySQL(table, fk),
+				Object:     fmt.Sprintf("foreign key %s", fk.Name),
+				BestEffort: true,
+			})
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	for _, table := range objects.Tables {
+		if table.RowSecurity {
+			plan = append(plan, Statement{
+				SQL:    c.BuildEnableRowSecuritySQL(table),
+				Object: fmt.Sprintf("row level security on %s.%s", table.Schema, table.Name),
+			})
+		}
+		for _, policy := range table.Policies {
+			plan = append(plan, Statement{
+				SQL:        c.BuildCreatePolicySQL(table, policy),
+				Object:     fmt.Sprintf("policy %s", policy.Name),
+				BestEffort: true,
+			})
+		}
 	}
 
-	c.logger.Logger.Infof("%d tables created successfully", len(tables))
-	return nil
+	return plan
 }
 
-func (c *Creator) createTable(tx interface{}, table Table) error {
-	var columnDefs []string
+// mappedSchemas lists, once each and in order, the target schemas that
+// SchemaMap sends objects to. Unmapped schemas are expected to exist.
+func (c *Creator) mappedSchemas(objects Objects) []string {
+	var schemas []string
+	seen := make(map[string]bool)
+	add := func(source string) {
+		target, ok := c.options.SchemaMap[source]
+		if ok && !seen[target] {
+			seen[target] = true
+			schemas = append(schemas, target)
+		}
+	}
 
-	for _, col := range table.Columns {
-		colDef := fmt.Sprintf(`"%s" %s`, col.Name, col.DataType)
+	for _, domain := range objects.Domains {
+		add(domain.Schema)
+	}
+	for _, seq := range objects.Sequences {
+		add(seq.Schema)
+	}
+	for _, table := range objects.Tables {
+		add(table.Schema)
+	}
+	sort.Strings(schemas)
+	return schemas
+}
 
-		if col.MaxLength != nil && (col.DataType == "character varying" || col.DataType == "varchar") {
-			colDef = fmt.Sprintf(`"%s" %s(%d)`, col.Name, col.DataType, *col.MaxLength)
+func (c *Creator) BuildEnableRowSecuritySQL(table Table) string {
+	return fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", c.qualified(table.Schema, table.Name))
+}
+
+func (c *Creator) BuildCreatePolicySQL(table Table, policy Policy) string {
+	kind := "RESTRICTIVE"
+	if policy.Permissive {
+		kind = "PERMISSIVE"
+	}
+
+	command := policy.Command
+	if command == "" {
+		command = "ALL"
+	}
+
+	stmt := fmt.Sprintf("CREATE POLICY %s ON %s AS %s FOR %s",
+		QuoteIdentifier(policy.Name), c.qualified(table.Schema, table.Name), kind, command)
+
+	if len(policy.Roles) > 0 {
+		roles := make([]string, len(policy.Roles))
+		for i, role := range policy.Roles {
+			if strings.EqualFold(role, "public") {
+				roles[i] = "PUBLIC"
+			} else {
+				roles[i] = QuoteIdentifier(role)
+			}
 		}
+		stmt += " TO " + strings.Join(roles, ", ")
+	}
 
-		if !col.IsNullable {
-			colDef += " NOT NULL"
+	if policy.Using != nil {
+		stmt += fmt.Sprintf(" USING (%s)", *policy.Using)
+	}
+	if policy.WithCheck != nil {
+		stmt += fmt.Sprintf(" WITH CHECK (%s)", *policy.WithCheck)
+	}
+
+	return stmt
+}
+
+func (c *Creator) warnMissingPolicyRoles(tables []Table) {
+	checked := make(map[string]bool)
+	for _, table := range tables {
+		for _, policy := range table.Policies {
+			for _, role := range policy.Roles {
+				if strings.EqualFold(role, "public") || checked[role] {
+					continue
+				}
+				checked[role] = true
+
+				var exists bool
+				if err := c.conn.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = $1)", role).Scan(&exists); err != nil {
+					c.logger.Logger.Warnf("Unable to check role %s on target: %v", role, err)
+					continue
+				}
+				if !exists {
+					c.logger.Logger.Warnf("Role %s referenced by policy %s does not exist on the target", role, policy.Name)
+				}
+			}
 		}
+	}
+}
 
-		if col.DefaultValue != nil {
-			colDef += fmt.Sprintf(" DEFAULT %s", *col.DefaultValue)
+func BuildCreateExtensionSQL(ext Extension) string {
+	stmt := fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s", QuoteIdentifier(ext.Name))
+	if ext.Schema != "" {
+		stmt += fmt.Sprintf(" WITH SCHEMA %s", QuoteIdentifier(ext.Schema))
+	}
+	return stmt
+}
+
+func (c *Creator) warnUnavailableExtensions(extensions []Extension) {
+	for _, ext := range extensions {
+		var available bool
+		err := c.conn.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_available_extensions WHERE name = $1)", ext.Name).Scan(&available)
+		if err != nil {
+			c.logger.Logger.Warnf("Unable to check availability of extension %s: %v", ext.Name, err)
+			continue
 		}
+		if !available {
+			c.logger.Logger.Warnf("Extension %s is not available on the target server; objects depending on it may fail", ext.Name)
+		}
+	}
+}
+
+// BuildCreateTableSQL renders the CREATE TABLE statement for a table.
+func (c *Creator) BuildCreateTableSQL(table Table) string {
+	var columnDefs []string
 
-		columnDefs = append(columnDefs, colDef)
+	for _, col := range table.Columns {
+		columnDefs = append(columnDefs, c.columnDefinition(col))
 	}
 
 	if len(table.PrimaryKeys) > 0 {
 		pkCols := make([]string, len(table.PrimaryKeys))
 		for i, pk := range table.PrimaryKeys {
-			pkCols[i] = fmt.Sprintf(`"%s"`, pk)
+			pkCols[i] = c.ident(pk)
 		}
 		columnDefs = append(columnDefs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pkCols, ", ")))
 	}
 
-	createSQL := fmt.Sprintf(
-		`CREATE TABLE IF NOT EXISTS "%s"."%s" (%s)`,
-		table.Schema,
-		table.Name,
+	stmt := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (%s)`,
+		c.qualified(table.Schema, table.Name),
 		strings.Join(columnDefs, ", "),
 	)
 
-	c.logger.Logger.Debugf("Creating table: %s", createSQL)
+	if c.options.PreserveStorage {
+		stmt += BuildStorageClause(table)
+	}
+
+	return stmt
+}
 
-	if execer, ok := tx.(interface {
-		Exec(string, ...interface{}) error
-	}); ok {
-		return execer.Exec(createSQL)
+func (c *Creator) columnDefinition(col Column) string {
+	colName := c.ident(col.Name)
+	colDef := fmt.Sprintf(`%s %s`, colName, col.DataType)
+
+	if col.Domain != "" {
+		colDef = fmt.Sprintf(`%s %s`, colName, c.qualified(col.DomainSchema, col.Domain))
+	} else if col.MaxLength != nil && (col.DataType == "character varying" || col.DataType == "varchar") {
+		colDef = fmt.Sprintf(`%s %s(%d)`, colName, col.DataType, *col.MaxLength)
+	}
+
+	if !col.IsNullable {
+		colDef += " NOT NULL"
 	}
 
-	return fmt.Errorf("transaction does not support Exec")
+	if col.DefaultValue != nil {
+		colDef += fmt.Sprintf(" DEFAULT %s", c.mapSequenceDefault(*col.DefaultValue))
+	}
+
+	return colDef
 }
 
-func (c *Creator) createIndexes(tx interface{}, table Table) error {
-	for _, idx := range table.Indexes {
-		if idx.IsPrimary {
-			continue
-		}
+// BuildCreateSequenceSQL renders CREATE SEQUENCE with the source's options.
+// The sequence starts at its start value; SetSequenceValues moves it on once
+// the rows are copied.
+func (c *Creator) BuildCreateSequenceSQL(seq Sequence) string {
+	stmt := fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s INCREMENT BY %d", c.qualified(seq.Schema, seq.Name), seq.IncrementBy)
+	if seq.MinValue != nil {
+		stmt += fmt.Sprintf(" MINVALUE %d", *seq.MinValue)
+	}
+	if seq.MaxValue != nil {
+		stmt += fmt.Sprintf(" MAXVALUE %d", *seq.MaxValue)
+	}
+	stmt += fmt.Sprintf(" START WITH %d CACHE %d", seq.StartValue, seq.CacheValue)
+	if seq.IsCycle {
+		return stmt + " CYCLE"
+	}
+	return stmt + " NO CYCLE"
+}
 
-		var indexSQL string
-		uniqueStr := ""
-		if idx.IsUnique {
-			uniqueStr = "UNIQUE "
+// BuildSetSequenceSQL renders the setval call that gives a target sequence
+// the source's current value, so the next nextval continues after the copied
+// rows. A sequence never used on the source is reset to its start value.
+func (c *Creator) BuildSetSequenceSQL(seq Sequence) string {
+	name := QuoteLiteral(c.qualified(seq.Schema, seq.Name))
+	if seq.LastValue == nil {
+		return fmt.Sprintf("SELECT setval(%s, %d, false)", name, seq.StartValue)
+	}
+	return fmt.Sprintf("SELECT setval(%s, %d, true)", name, *seq.LastValue)
+}
+
+// SetSequenceValues moves the target's sequences to the source's values. A
+// sequence missing on the target is only warned about.
+func (c *Creator) SetSequenceValues(sequences []Sequence) error {
+	for _, seq := range sequences {
+		if _, err := c.conn.DB.Exec(c.BuildSetSequenceSQL(seq)); err != nil {
+			c.logger.Logger.Warnf("Failed to set sequence %s.%s: %v", seq.Schema, seq.Name, err)
 		}
+	}
+	return nil
+}
 
-		indexCols := make([]string, len(idx.Columns))
-		for i, col := range idx.Columns {
-			indexCols[i] = fmt.Sprintf(`"%s"`, col)
+// mapSequenceDefault points a serial column's nextval('schema.seq') default
+// at the sequence's mapped schema. PostgreSQL only qualifies the sequence
+// when its schema is not on the search path, and quotes it when needed.
+func (c *Creator) mapSequenceDefault(value string) string {
+	for source, target := range c.options.SchemaMap {
+		for _, prefix := range []string{source, QuoteIdentifier(source)} {
+			value = strings.ReplaceAll(value, "nextval('"+prefix+".", "nextval('"+QuoteIdentifier(target)+".")
 		}
+	}
+	return value
+}
 
-		indexSQL = fmt.Sprintf(
-			`CREATE %sINDEX IF NOT EXISTS "%s" ON "%s"."%s" USING %s (%s)`,
-			uniqueStr,
-			idx.Name,
-			table.Schema,
-			table.Name,
-			idx.IndexType,
-			strings.Join(indexCols, ", "),
-		)
+// BuildStorageClause renders the WITH (...) and TABLESPACE suffix for a table's
+// extracted storage parameters. It returns an empty string when there are none.
+func BuildStorageClause(table Table) string {
+	var clause string
+	if len(table.StorageOpts) > 0 {
+		clause += fmt.Sprintf(" WITH (%s)", strings.Join(table.StorageOpts, ", "))
+	}
+	if table.Tablespace != "" {
+		clause += " TABLESPACE " + QuoteIdentifier(table.Tablespace)
+	}
+	return clause
+}
 
-		c.logger.Logger.Debugf("Creating index: %s", indexSQL)
+// dropMissingTablespaces clears tablespace assignments that do not exist on the
+// target, so CREATE TABLE falls back to the default tablespace instead of failing.
+func (c *Creator) dropMissingTablespaces(tables []Table) []Table {
+	exists := make(map[string]bool)
+	result := make([]Table, len(tables))
+	for i, table := range tables {
+		result[i] = table
+		if table.Tablespace == "" {
+			continue
+		}
 
-		if execer, ok := tx.(interface {
-			Exec(string, ...interface{}) error
-		}); ok {
-			if err := execer.Exec(indexSQL); err != nil {
-				c.logger.Logger.Warnf("Failed to create index %s: %v", idx.Name, err)
+		found, checked := exists[table.Tablespace]
+		if !checked {
+			err := c.conn.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_tablespace WHERE spcname = $1)", table.Tablespace).Scan(&found)
+			if err != nil {
+				c.logger.Logger.Warnf("Unable to check tablespace %s on target: %v", table.Tablespace, err)
+			}
+			exists[table.Tablespace] = found
+			if !found {
+				c.logger.Logger.Warnf("Tablespace %s does not exist on the target; tables using it are created in the default tablespace", table.Tablespace)
 			}
 		}
+
+		if !found {
+			result[i].Tablespace = ""
+		}
 	}
+	return result
+}
 
-	return nil
+// BuildCreateDomainSQL renders CREATE DOMAIN with its default, NOT NULL and
+// CHECK constraints.
+func (c *Creator) BuildCreateDomainSQL(domain Domain) string {
+	stmt := fmt.Sprintf("CREATE DOMAIN %s AS %s", c.qualified(domain.Schema, domain.Name), domain.BaseType)
+
+	if domain.Default != nil {
+		stmt += fmt.Sprintf(" DEFAULT %s", *domain.Default)
+	}
+	if domain.NotNull {
+		stmt += " NOT NULL"
+	}
+	for _, constraint := range domain.Constraints {
+		stmt += fmt.Sprintf(" CONSTRAINT %s %s", QuoteIdentifier(constraint.Name), constraint.Definition)
+	}
+
+	return stmt
 }
 
-func (c *Creator) createForeignKeys(tx interface{}, table Table) error {
-	for _, fk := range table.ForeignKeys {
-		fkSQL := fmt.Sprintf(
-			`ALTER TABLE "%s"."%s" ADD CONSTRAINT "%s" FOREIGN KEY ("%s") REFERENCES "%s"."%s" ("%s")`,
-			table.Schema,
-			table.Name,
-			fk.Name,
-			fk.ColumnName,
-			fk.ReferencedSchema,
-			fk.ReferencedTable,
-			fk.ReferencedColumn,
-		)
+// BuildCreateIndexSQL renders the CREATE INDEX statement for a secondary index.
+func (c *Creator) BuildCreateIndexSQL(table Table, idx Index) string {
+	uniqueStr := ""
+	if idx.IsUnique {
+		uniqueStr = "UNIQUE "
+	}
 
-		if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
-			fkSQL += fmt.Sprintf(" ON DELETE %s", fk.OnDelete)
-		}
+	indexCols := make([]string, len(idx.Columns))
+	for i, col := range idx.Columns {
+		indexCols[i] = c.ident(col)
+	}
 
-		if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
-			fkSQL += fmt.Sprintf(" ON UPDATE %s", fk.OnUpdate)
-		}
+	return fmt.Sprintf(
+		`CREATE %sINDEX IF NOT EXISTS %s ON %s USING %s (%s)`,
+		uniqueStr,
+		c.ident(idx.Name),
+		c.qualified(table.Schema, table.Name),
+		idx.IndexType,
+		strings.Join(indexCols, ", "),
+	)
+}
 
-		c.logger.Logger.Debugf("Creating foreign key: %s", fkSQL)
+// BuildForeignKeySQL renders the ALTER TABLE statement adding a foreign key.
+func (c *Creator) BuildForeignKeySQL(table Table, fk ForeignKey) string {
+	fkSQL := fmt.Sprintf(
+		`ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)`,
+		c.qualified(table.Schema, table.Name),
+		c.ident(fk.Name),
+		c.ident(fk.ColumnName),
+		c.qualified(fk.ReferencedSchema, fk.ReferencedTable),
+		c.ident(fk.ReferencedColumn),
+	)
 
-		if execer, ok := tx.(interface {
-			Exec(string, ...interface{}) error
-		}); ok {
-			if err := execer.Exec(fkSQL); err != nil {
-				c.logger.Logger.Warnf("Failed to create foreign key %s: %v", fk.Name, err)
-			}
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		fkSQL += fmt.Sprintf(" ON DELETE %s", fk.OnDelete)
+	}
+
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		fkSQL += fmt.Sprintf(" ON UPDATE %s", fk.OnUpdate)
+	}
+
+	return fkSQL
+}
+
+func (c *Creator) ident(name string) string {
+	return QuoteIdentifier(FoldIdentifier(name, c.options.IdentifierCase))
+}
+
+func (c *Creator) qualified(schemaName, name string) string {
+	return QuoteIdentifier(MapSchema(schemaName, c.options.SchemaMap)) + "." + c.ident(name)
+}
+
+// execSavepoint runs a best-effort statement inside a savepoint so that a
+// failure does not abort the surrounding transaction.
+func execSavepoint(tx *sql.Tx, statement string) error {
+	if _, err := tx.Exec("SAVEPOINT dbrts_stmt"); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(statement); err != nil {
+		if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT dbrts_stmt"); rbErr != nil {
+			return fmt.Errorf("%v (rollback to savepoint failed: %v)", err, rbErr)
 		}
+		return err
 	}
 
-	return nil
+	_, err := tx.Exec("RELEASE SAVEPOINT dbrts_stmt")
+	return err
 }
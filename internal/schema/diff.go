@@ -0,0 +1,243 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Diff lists the objects the target is missing compared with the source.
+// Changed column definitions are reported but never altered.
+type Diff struct {
+	Extensions     []Extension
+	Domains        []Domain
+	Sequences      []Sequence
+	NewTables      []Table
+	AddedColumns   []TableColumn
+	NewIndexes     []TableIndex
+	NewForeignKeys []TableForeignKey
+	ChangedColumns []ColumnChange
+}
+
+type TableColumn struct {
+	Table  Table
+	Column Column
+}
+
+type TableIndex struct {
+	Table Table
+	Index Index
+}
+
+type TableForeignKey struct {
+	Table      Table
+	ForeignKey ForeignKey
+}
+
+// ColumnChange describes a column whose type or nullability differs on the target.
+type ColumnChange struct {
+	Table  Table
+	Source Column
+	Target Column
+}
+
+// Empty reports whether the target already has every source object.
+func (d Diff) Empty() bool {
+	return len(d.NewTables) == 0 && len(d.AddedColumns) == 0 && len(d.NewIndexes) == 0 &&
+		len(d.NewForeignKeys) == 0 && len(d.Domains) == 0
+}
+
+// DiffSchema compares source objects with what exists on the target. Source
+// names are folded with identifierCase before matching, the same way the
+// creator names them on the target.
+func DiffSchema(source Objects, target Objects, identifierCase string) Diff {
+	// Like extensions, sequences are created with IF NOT EXISTS, so every
+	// source sequence is planned.
+	diff := Diff{Extensions: source.Extensions, Sequences: source.Sequences}
+
+	fold := func(name string) string { return FoldIdentifier(name, identifierCase) }
+
+	existingDomains := make(map[string]bool, len(target.Domains))
+	for _, domain := range target.Domains {
+		existingDomains[domain.Schema+"."+domain.Name] = true
+	}
+	for _, domain := range source.Domains {
+		if !existingDomains[domain.Schema+"."+fold(domain.Name)] {
+			diff.Domains = append(diff.Domains, domain)
+		}
+	}
+
+	targetTables := make(map[string]Table, len(target.Tables))
+	for _, table := range target.Tables {
+		targetTables[table.Schema+"."+table.Name] = table
+	}
+
+	for _, table := range source.Tables {
+		existing, ok := targetTables[table.Schema+"."+fold(table.Name)]
+		if !ok {
+			diff.NewTables = append(diff.NewTables, table)
+			continue
+		}
+
+		targetColumns := make(map[string]Column, len(existing.Columns))
+		for _, col := range existing.Columns {
+			targetColumns[col.Name] = col
+		}
+		for _, col := range table.Columns {
+			targetCol, ok := targetColumns[fold(col.Name)]
+			if !ok {
+				diff.AddedColumns = append(diff.AddedColumns, TableColumn{Table: table, Column: col})
+				continue
+			}
+			if columnChanged(col, targetCol) {
+				diff.ChangedColumns = append(diff.ChangedColumns, ColumnChange{Table: table, Source: col, Target: targetCol})
+			}
+		}
+
+		targetIndexes := make(map[string]bool, len(existing.Indexes))
+		for _, idx := range existing.Indexes {
+			targetIndexes[idx.Name] = true
+		}
+		for _, idx := range table.Indexes {
+			if !idx.IsPrimary && !targetIndexes[fold(idx.Name)] {
+				diff.NewIndexes = append(diff.NewIndexes, TableIndex{Table: table, Index: idx})
+			}
+		}
+
+		targetForeignKeys := make(map[string]bool, len(existing.ForeignKeys))
+		for _, fk := range existing.ForeignKeys {
+			targetForeignKeys[fk.Name] = true
+		}
+		for _, fk := range table.ForeignKeys {
+			if !targetForeignKeys[fold(fk.Name)] {
+				diff.NewForeignKeys = append(diff.NewForeignKeys, TableForeignKey{Table: table, ForeignKey: fk})
+			}
+		}
+	}
+
+	return diff
+}
+
+func columnChanged(source, target Column) bool {
+	if !strings.EqualFold(source.DataType, target.DataType) || source.IsNullable != target.IsNullable {
+		return true
+	}
+	if source.MaxLength != nil && target.MaxLength != nil && *source.MaxLength != *target.MaxLength {
+		return true
+	}
+	return false
+}
+
+// PlanDiff orders the DDL that brings the target up to the source: missing
+// extensions, domains and sequences, new tables with all their objects, then added
+// columns, indexes and foreign keys on existing tables.
+func (c *Creator) PlanDiff(diff Diff) []Statement {
+	plan := c.PlanSchema(Objects{
+		Extensions: diff.Extensions,
+		Domains:    diff.Domains,
+		Sequences:  diff.Sequences,
+		Tables:     diff.NewTables,
+	})
+
+	for _, added := range diff.AddedColumns {
+		plan = append(plan, Statement{
+			SQL:    c.BuildAddColumnSQL(added.Table, added.Column),
+			Object: fmt.Sprintf("column %s.%s.%s", added.Table.Schema, added.Table.Name, added.Column.Name),
+		})
+	}
+
+	for _, added := range diff.NewIndexes {
+		plan = append(plan, Statement{
+			SQL:        c.BuildCreateIndexSQL(added.Table, added.Index),
+			Object:     fmt.Sprintf("index %s", added.Index.Name),
+			BestEffort: true,
+		})
+	}
+
+	for _, added := range diff.NewForeignKeys {
+		plan = append(plan, Statement{
+			SQL:        c.BuildForeignKeySQL(added.Table, added.ForeignKey),
+			Object:     fmt.Sprintf("foreign key %s", added.ForeignKey.Name),
+			BestEffort: true,
+		})
+	}
+
+	return plan
+}
+
+// BuildAddColumnSQL renders ALTER TABLE ... ADD COLUMN for a column missing on
+// the target. A NOT NULL column without a default fails on a non-empty table,
+// which is reported instead of silently relaxing the constraint.
+func (c *Creator) BuildAddColumnSQL(table Table, col Column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s",
+		c.qualified(table.Schema, table.Name), c.columnDefinition(col))
+}
+
+// ApplyDiff runs PlanDiff on the target in a single transaction.
+func (c *Creator) ApplyDiff(diff Diff) error {
+	for _, changed := range diff.ChangedColumns {
+		c.logger.Logger.Warnf("Column %s.%s.%s differs on the target (%s vs %s); it is left unchanged",
+			changed.Table.Schema, changed.Table.Name, changed.Source.Name, describeColumn(changed.Source), describeColumn(changed.Target))
+	}
+
+	if diff.Empty() {
+		c.logger.Logger.Info("Target schema is up to date.")
+		return nil
+	}
+
+	c.logger.Logger.Infof("Applying schema diff: %d new tables, %d added columns, %d new indexes, %d new foreign keys",
+		len(diff.NewTables), len(diff.AddedColumns), len(diff.NewIndexes), len(diff.NewForeignKeys))
+
+	c.warnUnavailableExtensions(diff.Extensions)
+	c.warnMissingPolicyRoles(diff.NewTables)
+	if c.options.PreserveStorage {
+		diff.NewTables = c.dropMissingTablespaces(diff.NewTables)
+	}
+
+	return c.execPlan(c.PlanDiff(diff))
+}
+
+func describeColumn(col Column) string {
+	desc := col.DataType
+	if col.MaxLength != nil {
+		desc += fmt.Sprintf("(%d)", *col.MaxLength)
+	}
+	if !col.IsNullable {
+		desc += " NOT NULL"
+	}
+	return desc
+}
+
+// WriteMigrationScript writes PlanDiff as a SQL script for review, wrapped in
+// one transaction. Columns that differ are listed as comments since they are
+// never altered. Statements a transfer treats as best effort are marked: in
+// a script any failure aborts the whole transaction.
+func (c *Creator) WriteMigrationScript(w io.Writer, diff Diff) error {
+	var b strings.Builder
+
+	for _, changed := range diff.ChangedColumns {
+		fmt.Fprintf(&b, "-- Not altered: column %s.%s.%s differs on the target (%s vs %s)\n",
+			changed.Table.Schema, changed.Table.Name, changed.Source.Name, describeColumn(changed.Source), describeColumn(changed.Target))
+	}
+
+	if diff.Empty() {
+		b.WriteString("-- The target schema is up to date; there is nothing to apply.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	fmt.Fprintf(&b, "-- %d new tables, %d added columns, %d new indexes, %d new foreign keys\n\n",
+		len(diff.NewTables), len(diff.AddedColumns), len(diff.NewIndexes), len(diff.NewForeignKeys))
+	b.WriteString("BEGIN;\n")
+	for _, stmt := range c.PlanDiff(diff) {
+		fmt.Fprintf(&b, "\n-- %s", stmt.Object)
+		if stmt.BestEffort {
+			b.WriteString(" (optional: remove it if it fails)")
+		}
+		fmt.Fprintf(&b, "\n%s;\n", stmt.SQL)
+	}
+	b.WriteString("\nCOMMIT;\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
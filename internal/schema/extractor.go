@@ -7,6 +7,8 @@ import (
 
 	"github.com/kadirbelkuyu/DBRTS/internal/database"
 	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/lib/pq"
 )
 
 type Extractor struct {
@@ -63,6 +65,180 @@ func (e *Extractor) ExtractTables(schemaFilter string) ([]Table, error) {
 	return tables, nil
 }
 
+// ExtractTable loads the full metadata for a single table.
+func (e *Extractor) ExtractTable(schemaName, tableName string) (*Table, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.tables
+			WHERE table_schema = $1 AND table_name = $2 AND table_type = 'BASE TABLE'
+		)
+	`
+	if err := e.conn.DB.QueryRow(query, schemaName, tableName).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up table: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %s.%s not found", schemaName, tableName)
+	}
+
+	table := &Table{Name: tableName, Schema: schemaName}
+	if err := e.extractTableDetails(table); err != nil {
+		return nil, fmt.Errorf("failed to gather table details for %s.%s: %w", schemaName, tableName, err)
+	}
+
+	return table, nil
+}
+
+// ExtractExtensions lists the extensions installed in the source database,
+// skipping plpgsql which every database ships with.
+func (e *Extractor) ExtractExtensions() ([]Extension, error) {
+	query := `
+		SELECT e.extname, e.extversion, n.nspname
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		WHERE e.extname <> 'plpgsql'
+		ORDER BY e.extname
+	`
+
+	rows, err := e.conn.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extensions: %w", err)
+	}
+	defer rows.Close()
+
+	var extensions []Extension
+	for rows.Next() {
+		var ext Extension
+		if err := rows.Scan(&ext.Name, &ext.Version, &ext.Schema); err != nil {
+			return nil, fmt.Errorf("failed to read extension metadata: %w", err)
+		}
+		extensions = append(extensions, ext)
+	}
+
+	return extensions, rows.Err()
+}
+
+// ExtractDomains lists user-defined domains with their base type, default and
+// CHECK constraints.
+func (e *Extractor) ExtractDomains() ([]Domain, error) {
+	query := `
+		SELECT t.oid, n.nspname, t.typname, format_type(t.typbasetype, t.typtypmod), t.typnotnull, t.typdefault
+		FROM pg_type t
+		JOIN pg_namespace n ON n.oid = t.typnamespace
+		WHERE t.typtype = 'd'
+			AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND n.nspname NOT LIKE 'pg_toast%'
+		ORDER BY n.nspname, t.typname
+	`
+
+	rows, err := e.conn.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query domains: %w", err)
+	}
+	defer rows.Close()
+
+	var domains []Domain
+	var oids []int64
+	for rows.Next() {
+		var domain Domain
+		var oid int64
+		var defaultValue sql.NullString
+		if err := rows.Scan(&oid, &domain.Schema, &domain.Name, &domain.BaseType, &domain.NotNull, &defaultValue); err != nil {
+			return nil, fmt.Errorf("failed to read domain metadata: %w", err)
+		}
+		if defaultValue.Valid {
+			domain.Default = &defaultValue.String
+		}
+		domains = append(domains, domain)
+		oids = append(oids, oid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range domains {
+		if err := e.extractDomainConstraints(&domains[i], oids[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return domains, nil
+}
+
+// ExtractSequences lists the sequences of the source database with their
+// options and current value, including those behind serial columns. Sequences
+// owned by identity columns are left out: the creator does not recreate
+// identity columns, so nothing on the target would use them.
+func (e *Extractor) ExtractSequences() ([]Sequence, error) {
+	query := `
+		SELECT schemaname, sequencename, last_value, start_value, increment_by,
+			min_value, max_value, cache_size, cycle
+		FROM pg_sequences s
+		WHERE schemaname NOT IN ('pg_catalog', 'information_schema')
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_depend d
+				WHERE d.classid = 'pg_class'::regclass
+					AND d.objid = format('%I.%I', s.schemaname, s.sequencename)::regclass
+					AND d.deptype = 'i'
+			)
+		ORDER BY schemaname, sequencename
+	`
+
+	rows, err := e.conn.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sequences: %w", err)
+	}
+	defer rows.Close()
+
+	var sequences []Sequence
+	for rows.Next() {
+		var seq Sequence
+		var lastValue, minValue, maxValue sql.NullInt64
+		if err := rows.Scan(&seq.Schema, &seq.Name, &lastValue, &seq.StartValue, &seq.IncrementBy,
+			&minValue, &maxValue, &seq.CacheValue, &seq.IsCycle); err != nil {
+			return nil, fmt.Errorf("failed to read sequence metadata: %w", err)
+		}
+		if lastValue.Valid {
+			seq.LastValue = &lastValue.Int64
+		}
+		if minValue.Valid {
+			seq.MinValue = &minValue.Int64
+		}
+		if maxValue.Valid {
+			seq.MaxValue = &maxValue.Int64
+		}
+		sequences = append(sequences, seq)
+	}
+
+	return sequences, rows.Err()
+}
+
+func (e *Extractor) extractDomainConstraints(domain *Domain, oid int64) error {
+	query := `
+		SELECT conname, pg_get_constraintdef(oid)
+		FROM pg_constraint
+		WHERE contypid = $1 AND contype = 'c'
+		ORDER BY conname
+	`
+
+	rows, err := e.conn.DB.Query(query, oid)
+	if err != nil {
+		return fmt.Errorf("failed to query constraints for domain %s: %w", domain.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var constraint DomainConstraint
+		if err := rows.Scan(&constraint.Name, &constraint.Definition); err != nil {
+			return fmt.Errorf("failed to read domain constraint: %w", err)
+		}
+		domain.Constraints = append(domain.Constraints, constraint)
+	}
+
+	return rows.Err()
+}
+
 func (e *Extractor) extractTableDetails(table *Table) error {
 	if err := e.extractColumns(table); err != nil {
 		return err
@@ -84,6 +260,14 @@ func (e *Extractor) extractTableDetails(table *Table) error {
 		return err
 	}
 
+	if err := e.extractPolicies(table); err != nil {
+		return err
+	}
+
+	if err := e.extractStorage(table); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -95,7 +279,9 @@ func (e *Extractor) extractColumns(table *Table) error {
 			is_nullable,
 			column_default,
 			character_maximum_length,
-			ordinal_position
+			ordinal_position,
+			domain_schema,
+			domain_name
 		FROM information_schema.columns
 		WHERE table_schema = $1 AND table_name = $2
 		ORDER BY ordinal_position
@@ -112,6 +298,7 @@ func (e *Extractor) extractColumns(table *Table) error {
 		var isNullable string
 		var defaultValue sql.NullString
 		var maxLength sql.NullInt64
+		var domainSchema, domainName sql.NullString
 
 		err := rows.Scan(
 			&col.Name,
@@ -120,6 +307,8 @@ func (e *Extractor) extractColumns(table *Table) error {
 			&defaultValue,
 			&maxLength,
 			&col.Position,
+			&domainSchema,
+			&domainName,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to read column metadata: %w", err)
@@ -133,6 +322,10 @@ func (e *Extractor) extractColumns(table *Table) error {
 			length := int(maxLength.Int64)
 			col.MaxLength = &length
 		}
+		if domainName.Valid {
+			col.DomainSchema = domainSchema.String
+			col.Domain = domainName.String
+		}
 
 		table.Columns = append(table.Columns, col)
 	}
@@ -260,6 +453,7 @@ func (e *Extractor) extractIndexes(table *Table) error {
 
 		idx.Columns = e.parseIndexColumns(indexDef)
 		idx.IndexType = e.parseIndexType(indexDef)
+		idx.Partial = strings.Contains(indexDef, " WHERE ")
 
 		table.Indexes = append(table.Indexes, idx)
 	}
@@ -267,6 +461,77 @@ func (e *Extractor) extractIndexes(table *Table) error {
 	return nil
 }
 
+func (e *Extractor) extractPolicies(table *Table) error {
+	rlsQuery := `
+		SELECT c.relrowsecurity
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+	`
+	if err := e.conn.DB.QueryRow(rlsQuery, table.Schema, table.Name).Scan(&table.RowSecurity); err != nil {
+		return fmt.Errorf("failed to query row level security flag: %w", err)
+	}
+
+	query := `
+		SELECT policyname, permissive, roles, cmd, qual, with_check
+		FROM pg_policies
+		WHERE schemaname = $1 AND tablename = $2
+		ORDER BY policyname
+	`
+
+	rows, err := e.conn.DB.Query(query, table.Schema, table.Name)
+	if err != nil {
+		return fmt.Errorf("failed to query policies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var policy Policy
+		var permissive string
+		var roles pq.StringArray
+		var using, withCheck sql.NullString
+
+		if err := rows.Scan(&policy.Name, &permissive, &roles, &policy.Command, &using, &withCheck); err != nil {
+			return fmt.Errorf("failed to read policy metadata: %w", err)
+		}
+
+		policy.Permissive = permissive == "PERMISSIVE"
+		policy.Roles = roles
+		if using.Valid {
+			policy.Using = &using.String
+		}
+		if withCheck.Valid {
+			policy.WithCheck = &withCheck.String
+		}
+
+		table.Policies = append(table.Policies, policy)
+	}
+
+	return rows.Err()
+}
+
+// extractStorage reads the table's storage parameters (fillfactor, autovacuum
+// settings, ...) and its tablespace when it is not the database default.
+func (e *Extractor) extractStorage(table *Table) error {
+	query := `
+		SELECT c.reloptions, ts.spcname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_tablespace ts ON ts.oid = c.reltablespace
+		WHERE n.nspname = $1 AND c.relname = $2
+	`
+
+	var options pq.StringArray
+	var tablespace sql.NullString
+	if err := e.conn.DB.QueryRow(query, table.Schema, table.Name).Scan(&options, &tablespace); err != nil {
+		return fmt.Errorf("failed to query storage parameters: %w", err)
+	}
+
+	table.StorageOpts = options
+	table.Tablespace = tablespace.String
+	return nil
+}
+
 func (e *Extractor) extractRowCount(table *Table) error {
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", table.Schema, table.Name)
 
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	IdentifierCasePreserve = "preserve"
+	IdentifierCaseLower    = "lower"
+	IdentifierCaseUpper    = "upper"
+)
+
+func ValidateIdentifierCase(mode string) error {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", IdentifierCasePreserve, IdentifierCaseLower, IdentifierCaseUpper:
+		return nil
+	default:
+		return fmt.Errorf("unsupported identifier case %q (expected preserve, lower or upper)", mode)
+	}
+}
+
+// FoldsIdentifiers reports whether mode renames identifiers on the target.
+func FoldsIdentifiers(mode string) bool {
+	return mode != "" && !strings.EqualFold(strings.TrimSpace(mode), IdentifierCasePreserve)
+}
+
+// FoldIdentifier applies the configured case folding to a target identifier.
+func FoldIdentifier(name, mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case IdentifierCaseLower:
+		return strings.ToLower(name)
+	case IdentifierCaseUpper:
+		return strings.ToUpper(name)
+	default:
+		return name
+	}
+}
+
+// MapSchema returns the target schema that objects of a source schema are
+// created in: the schema schemaMap maps it to, or the same name.
+func MapSchema(name string, schemaMap map[string]string) string {
+	if target, ok := schemaMap[name]; ok {
+		return target
+	}
+	return name
+}
+
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteLiteral quotes a string constant, such as a name passed to regclass.
+func QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -1,47 +1,86 @@
 package schema
 
 type Table struct {
-	Name        string
-	Schema      string
-	Columns     []Column
-	PrimaryKeys []string
-	ForeignKeys []ForeignKey
-	Indexes     []Index
-	RowCount    int64
+	Name        string       `json:"name"`
+	Schema      string       `json:"schema"`
+	Columns     []Column     `json:"columns"`
+	PrimaryKeys []string     `json:"primary_keys"`
+	ForeignKeys []ForeignKey `json:"foreign_keys"`
+	Indexes     []Index      `json:"indexes"`
+	RowCount    int64        `json:"row_count"`
+	RowSecurity bool         `json:"row_security,omitempty"`
+	Policies    []Policy     `json:"policies,omitempty"`
+	StorageOpts []string     `json:"storage_options,omitempty"`
+	Tablespace  string       `json:"tablespace,omitempty"`
 }
 
 type Column struct {
-	Name         string
-	DataType     string
-	IsNullable   bool
-	DefaultValue *string
-	MaxLength    *int
-	Position     int
+	Name         string  `json:"name"`
+	DataType     string  `json:"data_type"`
+	IsNullable   bool    `json:"nullable"`
+	DefaultValue *string `json:"default,omitempty"`
+	MaxLength    *int    `json:"max_length,omitempty"`
+	Position     int     `json:"position"`
+	DomainSchema string  `json:"domain_schema,omitempty"`
+	Domain       string  `json:"domain,omitempty"`
 }
 
 type ForeignKey struct {
-	Name             string
-	ColumnName       string
-	ReferencedTable  string
-	ReferencedColumn string
-	ReferencedSchema string
-	OnDelete         string
-	OnUpdate         string
+	Name             string `json:"name"`
+	ColumnName       string `json:"column"`
+	ReferencedTable  string `json:"referenced_table"`
+	ReferencedColumn string `json:"referenced_column"`
+	ReferencedSchema string `json:"referenced_schema"`
+	OnDelete         string `json:"on_delete"`
+	OnUpdate         string `json:"on_update"`
 }
 
 type Index struct {
-	Name      string
-	TableName string
-	Columns   []string
-	IsUnique  bool
-	IsPrimary bool
-	IndexType string
+	Name      string   `json:"name"`
+	TableName string   `json:"table"`
+	Columns   []string `json:"columns"`
+	IsUnique  bool     `json:"unique"`
+	IsPrimary bool     `json:"primary"`
+	IndexType string   `json:"type"`
+	// Partial indexes (with a WHERE clause) only cover some rows.
+	Partial bool `json:"partial,omitempty"`
+}
+
+type Policy struct {
+	Name       string   `json:"name"`
+	Permissive bool     `json:"permissive"`
+	Command    string   `json:"command"`
+	Roles      []string `json:"roles"`
+	Using      *string  `json:"using,omitempty"`
+	WithCheck  *string  `json:"with_check,omitempty"`
+}
+
+type Extension struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Schema  string `json:"schema"`
+}
+
+type Domain struct {
+	Name        string             `json:"name"`
+	Schema      string             `json:"schema"`
+	BaseType    string             `json:"base_type"`
+	NotNull     bool               `json:"not_null"`
+	Default     *string            `json:"default,omitempty"`
+	Constraints []DomainConstraint `json:"constraints,omitempty"`
+}
+
+type DomainConstraint struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
 }
 
 type Sequence struct {
-	Name        string
-	Schema      string
-	LastValue   int64
+	Name   string
+	Schema string
+	// LastValue is nil for a sequence nextval has never been called on, or
+	// whose value the user is not allowed to read.
+	LastValue   *int64
 	StartValue  int64
 	IncrementBy int64
 	MinValue    *int64
@@ -54,6 +93,10 @@ type View struct {
 	Name       string
 	Schema     string
 	Definition string
+	// Materialized views are created without data and filled by a refresh.
+	Materialized bool
+	// DependsOn names, as schema.name, the views this one selects from.
+	DependsOn []string
 }
 
 type Function struct {
@@ -0,0 +1,222 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExtractViews lists the views and materialized views of the source database
+// with the views each one selects from, so they can be created in order.
+func (e *Extractor) ExtractViews() ([]View, error) {
+	query := `
+		SELECT table_schema, table_name, view_definition, false
+		FROM information_schema.views
+		WHERE table_schema NOT IN ('pg_catalog', 'information_schema')
+		UNION ALL
+		SELECT schemaname, matviewname, definition, true
+		FROM pg_matviews
+		ORDER BY 1, 2
+	`
+
+	rows, err := e.conn.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []View
+	for rows.Next() {
+		var view View
+		var definition *string
+		if err := rows.Scan(&view.Schema, &view.Name, &definition, &view.Materialized); err != nil {
+			return nil, fmt.Errorf("failed to read view metadata: %w", err)
+		}
+		// information_schema hides the definition of views the user does not own.
+		if definition == nil {
+			e.logger.Logger.Warnf("Skipping view %s.%s: its definition is only visible to its owner", view.Schema, view.Name)
+			continue
+		}
+		view.Definition = *definition
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := e.extractViewDependencies(views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+func (e *Extractor) extractViewDependencies(views []View) error {
+	query := `
+		SELECT DISTINCT vn.nspname, v.relname, dn.nspname, d.relname
+		FROM pg_rewrite r
+		JOIN pg_class v ON v.oid = r.ev_class
+		JOIN pg_namespace vn ON vn.oid = v.relnamespace
+		JOIN pg_depend dep ON dep.objid = r.oid
+			AND dep.classid = 'pg_rewrite'::regclass
+			AND dep.refclassid = 'pg_class'::regclass
+		JOIN pg_class d ON d.oid = dep.refobjid
+		JOIN pg_namespace dn ON dn.oid = d.relnamespace
+		WHERE v.relkind IN ('v', 'm')
+			AND d.relkind IN ('v', 'm')
+			AND d.oid <> v.oid
+		ORDER BY 1, 2, 3, 4
+	`
+
+	rows, err := e.conn.DB.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to query view dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(views))
+	for i, view := range views {
+		index[view.Schema+"."+view.Name] = i
+	}
+
+	for rows.Next() {
+		var viewSchema, viewName, depSchema, depName string
+		if err := rows.Scan(&viewSchema, &viewName, &depSchema, &depName); err != nil {
+			return fmt.Errorf("failed to read view dependency: %w", err)
+		}
+		if i, ok := index[viewSchema+"."+viewName]; ok {
+			views[i].DependsOn = append(views[i].DependsOn, depSchema+"."+depName)
+		}
+	}
+	return rows.Err()
+}
+
+// OrderViews sorts views so each comes after the views it depends on, keeping
+// the given order otherwise. Dependencies outside views are ignored. A cycle
+// is an error naming the views involved.
+func OrderViews(views []View) ([]View, error) {
+	index := make(map[string]int, len(views))
+	for i, view := range views {
+		index[view.Schema+"."+view.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(views))
+	ordered := make([]View, 0, len(views))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		name := views[i].Schema + "." + views[i].Name
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for start < len(path) && path[start] != name {
+				start++
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("views form a dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[i] = visiting
+		path = append(path, name)
+		for _, dep := range views[i].DependsOn {
+			if j, ok := index[dep]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+		ordered = append(ordered, views[i])
+		return nil
+	}
+
+	for i := range views {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// MissingViews returns the source views the target does not have, matched
+// by schema and name.
+func MissingViews(source, target []View) []View {
+	existing := make(map[string]bool, len(target))
+	for _, view := range target {
+		existing[view.Schema+"."+view.Name] = true
+	}
+
+	var missing []View
+	for _, view := range source {
+		if !existing[view.Schema+"."+view.Name] {
+			missing = append(missing, view)
+		}
+	}
+	return missing
+}
+
+// BuildCreateViewSQL renders the statement that recreates a view. The
+// definition is copied as is, so it still names the source's schemas and
+// identifiers. Materialized views are created empty.
+func (c *Creator) BuildCreateViewSQL(view View) string {
+	definition := strings.TrimRight(strings.TrimSpace(view.Definition), ";")
+	if view.Materialized {
+		return fmt.Sprintf("CREATE MATERIALIZED VIEW IF NOT EXISTS %s AS %s WITH NO DATA", c.qualified(view.Schema, view.Name), definition)
+	}
+	return fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", c.qualified(view.Schema, view.Name), definition)
+}
+
+// CreateViews creates views on the target in dependency order. It runs once
+// every base table exists; a view that fails, for example because it
+// selects an excluded column, is warned about and skipped.
+func (c *Creator) CreateViews(views []View) error {
+	ordered, err := OrderViews(views)
+	if err != nil {
+		return err
+	}
+
+	plan := make([]Statement, 0, len(ordered))
+	for _, view := range ordered {
+		plan = append(plan, Statement{
+			SQL:        c.BuildCreateViewSQL(view),
+			Object:     fmt.Sprintf("%s %s.%s", viewKind(view), view.Schema, view.Name),
+			BestEffort: true,
+		})
+	}
+	return c.execPlan(plan)
+}
+
+// RefreshMaterializedViews fills the target's materialized views once the
+// data is in, refreshing those built on other materialized views last.
+func (c *Creator) RefreshMaterializedViews(views []View) error {
+	ordered, err := OrderViews(views)
+	if err != nil {
+		return err
+	}
+
+	for _, view := range ordered {
+		if !view.Materialized {
+			continue
+		}
+		c.logger.Logger.Infof("Refreshing materialized view %s.%s...", view.Schema, view.Name)
+		if _, err := c.conn.DB.Exec(fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", c.qualified(view.Schema, view.Name))); err != nil {
+			c.logger.Logger.Warnf("Failed to refresh materialized view %s.%s: %v", view.Schema, view.Name, err)
+		}
+	}
+	return nil
+}
+
+func viewKind(view View) string {
+	if view.Materialized {
+		return "materialized view"
+	}
+	return "view"
+}
@@ -0,0 +1,142 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/preset"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/interactive"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PathEnv overrides the settings file location.
+const PathEnv = "DBRTS_SETTINGS"
+
+// Settings holds per-user defaults for each engine. They are the baseline
+// for every run: presets and explicitly set flags take precedence.
+type Settings struct {
+	Postgres EngineSettings `yaml:"postgres,omitempty"`
+	Mongo    EngineSettings `yaml:"mongo,omitempty"`
+}
+
+type EngineSettings struct {
+	Transfer preset.TransferPreset `yaml:"transfer,omitempty"`
+	Backup   BackupDefaults        `yaml:"backup,omitempty"`
+}
+
+type BackupDefaults struct {
+	ReadPreference string   `yaml:"read_preference,omitempty"`
+	StrictVersion  bool     `yaml:"strict_version,omitempty"`
+	DumpGlobals    bool     `yaml:"dump_globals,omitempty"`
+	ExtraArgs      []string `yaml:"extra_args,omitempty"`
+
+	FilenameTemplate string `yaml:"filename_template,omitempty"`
+
+	// Format and Compression are the answers the interactive backup prompt
+	// suggests. Compression is a pointer so that 0 (uncompressed) can be set.
+	Format      string `yaml:"format,omitempty"`
+	Compression *int   `yaml:"compression,omitempty"`
+}
+
+// DefaultPath returns $DBRTS_SETTINGS, or settings.yaml in the user's config
+// directory (e.g. ~/.config/dbrts/settings.yaml).
+func DefaultPath() (string, error) {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "dbrts", "settings.yaml"), nil
+}
+
+// Load reads the settings file. A missing file yields empty settings, which
+// leave every built-in default in place.
+func Load(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Settings{}, nil
+		}
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	var s Settings
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse settings %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func Save(path string, s *Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// For returns the settings for a database type; unknown types get none.
+func (s *Settings) For(engine string) EngineSettings {
+	switch engine {
+	case "postgres":
+		return s.Postgres
+	case "mongo":
+		return s.Mongo
+	default:
+		return EngineSettings{}
+	}
+}
+
+// TransferOptions layers the transfer defaults under the flags. changed
+// reports whether a flag was set explicitly, as for presets.
+func (e EngineSettings) TransferOptions(flags transfer.Options, changed func(flag string) bool) transfer.Options {
+	return e.Transfer.Merge(flags, changed)
+}
+
+// BackupOptions layers the backup defaults under the flags.
+func (e EngineSettings) BackupOptions(flags backup.BackupOptions, changed func(flag string) bool) backup.BackupOptions {
+	merged := flags
+	if !changed("read-preference") && e.Backup.ReadPreference != "" {
+		merged.ReadPreference = e.Backup.ReadPreference
+	}
+	if !changed("strict-version") && e.Backup.StrictVersion {
+		merged.StrictVersion = true
+	}
+	if !changed("dump-globals") && e.Backup.DumpGlobals {
+		merged.DumpGlobals = true
+	}
+	if !changed("extra-arg") && len(e.Backup.ExtraArgs) > 0 {
+		merged.ExtraArgs = e.Backup.ExtraArgs
+	}
+	if !changed("filename-template") && e.Backup.FilenameTemplate != "" {
+		merged.FilenameTemplate = e.Backup.FilenameTemplate
+	}
+	return merged
+}
+
+// PromptDefaults returns the backup format and compression for the
+// interactive prompt, checked against the engine.
+func (e EngineSettings) PromptDefaults(engine string) (interactive.BackupDefaults, error) {
+	defaults := interactive.BackupDefaults{Compression: e.Backup.Compression}
+	if e.Backup.Format != "" {
+		format, err := backup.NormalizeFormat(engine, e.Backup.Format)
+		if err != nil {
+			return interactive.BackupDefaults{}, fmt.Errorf("settings: %w", err)
+		}
+		defaults.Format = format
+	}
+	if c := e.Backup.Compression; c != nil && (*c < 0 || *c > 9) {
+		return interactive.BackupDefaults{}, fmt.Errorf("settings: backup compression %d is out of range (0-9)", *c)
+	}
+	return defaults, nil
+}
@@ -0,0 +1,136 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+)
+
+// dumpEngine streams a custom-format pg_dump of the source straight into
+// pg_restore on the target, without an intermediate file.
+type dumpEngine struct {
+	sourceConfig *config.Config
+	targetConfig *config.Config
+	options      Options
+	report       *TransferReport
+}
+
+func newDumpEngine(sourceConfig, targetConfig *config.Config, options Options) *dumpEngine {
+	return &dumpEngine{
+		sourceConfig: sourceConfig,
+		targetConfig: targetConfig,
+		options:      options,
+		report:       NewTransferReport(),
+	}
+}
+
+// ViaDumpCommands builds the pg_dump and pg_restore invocations of a
+// --via-dump transfer. Owners and privileges are not restored, matching the
+// regular transfer, and the restore stops at the first error.
+func ViaDumpCommands(source, target *config.Config, options Options) (dump, restore backup.RestoreStep) {
+	dumpArgs := backup.PostgresDumpArgs(source, source.Database.Database, "", backup.BackupOptions{
+		Format:      "custom",
+		Compression: options.DumpCompression,
+		SchemaOnly:  options.SchemaOnly,
+		DataOnly:    options.DataOnly,
+	})
+
+	restoreArgs := backup.PostgresRestoreArgs(target, backup.RestoreOptions{
+		TargetDatabase: target.Database.Database,
+		ExitOnError:    true,
+	})
+	restoreArgs = append(restoreArgs, "--no-owner", "--no-privileges")
+	if options.DataOnly && options.DisableTriggers {
+		restoreArgs = append(restoreArgs, "--disable-triggers")
+	}
+
+	return backup.RestoreStep{Tool: "pg_dump", Args: dumpArgs}, backup.RestoreStep{Tool: "pg_restore", Args: restoreArgs}
+}
+
+func (e *dumpEngine) Execute() (*TransferReport, error) {
+	defer e.report.Finish()
+
+	stopListening, err := startListeners(e.sourceConfig, e.targetConfig, e.options.ListenChannel, e.options.Logger)
+	if err != nil {
+		return e.report, err
+	}
+	defer stopListening()
+
+	dumpStep, restoreStep := ViaDumpCommands(e.sourceConfig, e.targetConfig, e.options)
+	e.options.Logger.Infof("Streaming %s to %s through pg_dump | pg_restore...",
+		e.sourceConfig.Database.Database, e.targetConfig.Database.Database)
+
+	started := time.Now()
+	err = e.runPipeline(dumpStep, restoreStep)
+	e.report.Record(TableResult{Table: e.sourceConfig.Database.Database, Duration: time.Since(started)}, err)
+	if err != nil {
+		return e.report, err
+	}
+
+	e.options.Logger.Info("PostgreSQL transfer completed successfully.")
+	return e.report, nil
+}
+
+func (e *dumpEngine) runPipeline(dumpStep, restoreStep backup.RestoreStep) error {
+	// pg_dump runs with the source's tools and pg_restore with the target's.
+	dumpPath, err := e.sourceConfig.ToolPath(dumpStep.Tool)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	restorePath, err := e.targetConfig.ToolPath(restoreStep.Tool)
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	dump := exec.Command(dumpPath, dumpStep.Args...)
+	dump.Env = append(os.Environ(), e.sourceConfig.PostgresToolEnv()...)
+	restore := exec.Command(restorePath, restoreStep.Args...)
+	restore.Env = append(os.Environ(), e.targetConfig.PostgresToolEnv()...)
+
+	logWriter := e.options.Logger.Writer()
+	defer logWriter.Close()
+	dump.Stderr = logWriter
+	restore.Stderr = logWriter
+	restore.Stdout = logWriter
+
+	archive, err := dump.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to connect pg_dump to pg_restore: %w", err)
+	}
+	restore.Stdin = archive
+
+	e.options.Logger.Debugf("executing %s %s | %s %s",
+		dumpPath, strings.Join(dumpStep.Args, " "), restorePath, strings.Join(restoreStep.Args, " "))
+
+	if err := dump.Start(); err != nil {
+		return fmt.Errorf("failed to start pg_dump: %w", err)
+	}
+	if err := restore.Start(); err != nil {
+		_ = dump.Process.Kill()
+		_ = dump.Wait()
+		return fmt.Errorf("failed to start pg_restore: %w", err)
+	}
+	// pg_restore holds its own copy of the pipe. Closing ours lets pg_dump
+	// see a broken pipe if pg_restore exits early, instead of blocking.
+	archive.Close()
+
+	restoreErr := restore.Wait()
+	dumpErr := dump.Wait()
+
+	// Either side failing usually takes the other down with it (a truncated
+	// archive or a broken pipe), so report both when both failed.
+	switch {
+	case dumpErr != nil && restoreErr != nil:
+		return fmt.Errorf("pg_dump failed: %v; pg_restore failed: %w", dumpErr, restoreErr)
+	case dumpErr != nil:
+		return fmt.Errorf("pg_dump failed: %w", dumpErr)
+	case restoreErr != nil:
+		return fmt.Errorf("pg_restore failed: %w", restoreErr)
+	}
+	return nil
+}
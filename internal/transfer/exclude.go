@@ -0,0 +1,95 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+// ParseExcludeColumnFlags validates "schema.table.column" flag values.
+func ParseExcludeColumnFlags(values []string) ([]string, error) {
+	for _, value := range values {
+		parts := strings.Split(value, ".")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid excluded column %q (expected schema.table.column)", value)
+		}
+	}
+	return values, nil
+}
+
+// ExcludeColumns removes the named columns from the tables, so they are
+// neither created on the target nor read from the source. Indexes and
+// foreign keys that involve an excluded column are dropped with it. Primary
+// key columns cannot be excluded, since rows are ordered and split by them.
+func ExcludeColumns(tables []schema.Table, excluded []string) ([]schema.Table, error) {
+	if len(excluded) == 0 {
+		return tables, nil
+	}
+
+	excludedSet := make(map[string]bool, len(excluded))
+	for _, column := range excluded {
+		excludedSet[column] = true
+	}
+	isExcluded := func(schemaName, table, column string) bool {
+		return excludedSet[schemaName+"."+table+"."+column]
+	}
+	found := make(map[string]bool, len(excluded))
+
+	result := make([]schema.Table, 0, len(tables))
+	for _, table := range tables {
+		for _, pk := range table.PrimaryKeys {
+			if isExcluded(table.Schema, table.Name, pk) {
+				return nil, fmt.Errorf("cannot exclude %s.%s.%s: it is part of the primary key", table.Schema, table.Name, pk)
+			}
+		}
+
+		columns := make([]schema.Column, 0, len(table.Columns))
+		for _, col := range table.Columns {
+			if isExcluded(table.Schema, table.Name, col.Name) {
+				found[table.Schema+"."+table.Name+"."+col.Name] = true
+				continue
+			}
+			columns = append(columns, col)
+		}
+		table.Columns = columns
+
+		var indexes []schema.Index
+		for _, index := range table.Indexes {
+			keep := true
+			for _, column := range index.Columns {
+				if isExcluded(table.Schema, table.Name, column) {
+					keep = false
+					break
+				}
+			}
+			if keep {
+				indexes = append(indexes, index)
+			}
+		}
+		table.Indexes = indexes
+
+		var foreignKeys []schema.ForeignKey
+		for _, fk := range table.ForeignKeys {
+			if isExcluded(table.Schema, table.Name, fk.ColumnName) ||
+				isExcluded(fk.ReferencedSchema, fk.ReferencedTable, fk.ReferencedColumn) {
+				continue
+			}
+			foreignKeys = append(foreignKeys, fk)
+		}
+		table.ForeignKeys = foreignKeys
+
+		result = append(result, table)
+	}
+
+	var missing []string
+	for _, column := range excluded {
+		if !found[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("excluded column(s) not found in the source: %s", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package transfer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/lib/pq"
+)
+
+// NotificationListener relays NOTIFY messages that server-side code, such as
+// a trigger reporting its own progress with pg_notify, sends on a channel
+// during a transfer to the transfer log. It holds a connection of its own.
+type NotificationListener struct {
+	listener *pq.Listener
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// ListenForNotifications starts listening on channel. side ("source" or
+// "target") labels the log lines.
+func ListenForNotifications(cfg *config.Config, channel, side string, log *logger.Logger) (*NotificationListener, error) {
+	reportProblem := func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warnf("LISTEN %s on the %s: %v", channel, side, err)
+		}
+	}
+
+	connStr, err := cfg.DriverConnectionString()
+	if err != nil {
+		return nil, err
+	}
+
+	listener := pq.NewListener(connStr, time.Second, time.Minute, reportProblem)
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to LISTEN on channel %s on the %s: %w", channel, side, err)
+	}
+
+	n := &NotificationListener{
+		listener: listener,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go n.relay(side, log)
+	return n, nil
+}
+
+func (n *NotificationListener) relay(side string, log *logger.Logger) {
+	defer close(n.stopped)
+	for {
+		select {
+		case notification := <-n.listener.Notify:
+			HandleNotification(log, side, notification)
+		case <-n.done:
+			return
+		}
+	}
+}
+
+// Close stops relaying and closes the listener's connection. Notifications
+// still in flight are dropped.
+func (n *NotificationListener) Close() {
+	close(n.done)
+	<-n.stopped
+	n.listener.Close()
+}
+
+// HandleNotification writes one notification to the log. pq delivers nil
+// after it reconnects, when notifications sent in between are lost.
+func HandleNotification(log *logger.Logger, side string, notification *pq.Notification) {
+	if notification == nil {
+		log.Warnf("LISTEN connection to the %s was re-established; notifications may have been missed", side)
+		return
+	}
+	log.Infof("[%s %s] %s", side, notification.Channel, notification.Extra)
+}
+
+// startListeners listens on the channel on both source and target, since a
+// notifying trigger or function may run on either side. The returned
+// function stops both.
+func startListeners(source, target *config.Config, channel string, log *logger.Logger) (func(), error) {
+	if channel == "" {
+		return func() {}, nil
+	}
+
+	sourceListener, err := ListenForNotifications(source, channel, "source", log)
+	if err != nil {
+		return nil, err
+	}
+	targetListener, err := ListenForNotifications(target, channel, "target", log)
+	if err != nil {
+		sourceListener.Close()
+		return nil, err
+	}
+
+	log.Infof("Relaying notifications sent on channel %s to the log", channel)
+	return func() {
+		sourceListener.Close()
+		targetListener.Close()
+	}, nil
+}
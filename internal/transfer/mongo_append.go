@@ -0,0 +1,78 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoTarget is the subset of *mongo.Collection the copy loop writes through.
+type MongoTarget interface {
+	Drop(ctx context.Context) error
+	InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error)
+}
+
+// PrepareTargetCollection drops the target collection unless appendMode is
+// set, in which case existing documents are kept.
+func PrepareTargetCollection(ctx context.Context, target MongoTarget, appendMode bool) error {
+	if appendMode {
+		return nil
+	}
+
+	if err := target.Drop(ctx); err != nil && !isNamespaceNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// InsertDocuments writes a batch unordered so one failing document does not
+// stop the rest. In appendMode, duplicate _id or unique-key violations are
+// expected and reported as skipped rather than as an error.
+func InsertDocuments(ctx context.Context, target MongoTarget, batch []interface{}, appendMode bool) (skipped int, err error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	_, err = target.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return 0, nil
+	}
+
+	if appendMode {
+		if duplicates, ok := onlyDuplicateKeyErrors(err); ok {
+			return duplicates, nil
+		}
+	}
+	return 0, err
+}
+
+// StripIDs removes _id from every document in the batch so the target
+// assigns fresh ObjectIds.
+func StripIDs(batch []interface{}) {
+	for _, document := range batch {
+		if doc, ok := document.(bson.M); ok {
+			delete(doc, "_id")
+		}
+	}
+}
+
+// onlyDuplicateKeyErrors reports whether every write error in err is a
+// duplicate key violation, and how many there were.
+func onlyDuplicateKeyErrors(err error) (int, bool) {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return 0, false
+	}
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		switch writeErr.Code {
+		case 11000, 11001, 12582:
+		default:
+			return 0, false
+		}
+	}
+	return len(bulkErr.WriteErrors), true
+}
@@ -0,0 +1,139 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CollectionKindCollection = "collection"
+	CollectionKindView       = "view"
+	CollectionKindTimeSeries = "timeseries"
+)
+
+// CollectionDefinition is what the target needs to recreate a source
+// collection before any documents are copied.
+type CollectionDefinition struct {
+	Name string
+	Kind string
+
+	// Views only.
+	ViewOn   string
+	Pipeline bson.A
+
+	// Time-series collections only.
+	TimeSeries         *options.TimeSeriesOptions
+	ExpireAfterSeconds *int64
+}
+
+// CopiesData reports whether documents are copied. Views have no data of
+// their own.
+func (d CollectionDefinition) CopiesData() bool {
+	return d.Kind != CollectionKindView
+}
+
+// ParseCollectionInfo reads one listCollections result document.
+func ParseCollectionInfo(info bson.Raw) (CollectionDefinition, error) {
+	var doc struct {
+		Name    string `bson:"name"`
+		Type    string `bson:"type"`
+		Options struct {
+			ViewOn             string `bson:"viewOn"`
+			Pipeline           bson.A `bson:"pipeline"`
+			ExpireAfterSeconds *int64 `bson:"expireAfterSeconds"`
+			TimeSeries         *struct {
+				TimeField   string `bson:"timeField"`
+				MetaField   string `bson:"metaField"`
+				Granularity string `bson:"granularity"`
+			} `bson:"timeseries"`
+		} `bson:"options"`
+	}
+	if err := bson.Unmarshal(info, &doc); err != nil {
+		return CollectionDefinition{}, fmt.Errorf("failed to decode collection info: %w", err)
+	}
+
+	definition := CollectionDefinition{Name: doc.Name, Kind: doc.Type}
+	if definition.Kind == "" {
+		definition.Kind = CollectionKindCollection
+	}
+
+	switch definition.Kind {
+	case CollectionKindView:
+		if doc.Options.ViewOn == "" {
+			return definition, fmt.Errorf("view %s has no viewOn", doc.Name)
+		}
+		definition.ViewOn = doc.Options.ViewOn
+		definition.Pipeline = doc.Options.Pipeline
+		if definition.Pipeline == nil {
+			definition.Pipeline = bson.A{}
+		}
+	case CollectionKindTimeSeries:
+		ts := doc.Options.TimeSeries
+		if ts == nil || ts.TimeField == "" {
+			return definition, fmt.Errorf("time-series collection %s has no timeField", doc.Name)
+		}
+		definition.TimeSeries = options.TimeSeries().SetTimeField(ts.TimeField)
+		if ts.MetaField != "" {
+			definition.TimeSeries.SetMetaField(ts.MetaField)
+		}
+		if ts.Granularity != "" {
+			definition.TimeSeries.SetGranularity(ts.Granularity)
+		}
+		definition.ExpireAfterSeconds = doc.Options.ExpireAfterSeconds
+	}
+
+	return definition, nil
+}
+
+// CreateCollectionOptions returns the options for creating a time-series
+// collection, or nil for collections the first insert creates implicitly.
+func (d CollectionDefinition) CreateCollectionOptions() *options.CreateCollectionOptions {
+	if d.Kind != CollectionKindTimeSeries {
+		return nil
+	}
+	opts := options.CreateCollection().SetTimeSeriesOptions(d.TimeSeries)
+	if d.ExpireAfterSeconds != nil {
+		opts.SetExpireAfterSeconds(*d.ExpireAfterSeconds)
+	}
+	return opts
+}
+
+// OrderCollectionDefinitions drops system collections and puts views last,
+// each after the collection or view it is defined on.
+func OrderCollectionDefinitions(definitions []CollectionDefinition) []CollectionDefinition {
+	var ordered, views []CollectionDefinition
+	for _, definition := range definitions {
+		switch {
+		case strings.HasPrefix(definition.Name, "system."):
+		case definition.Kind == CollectionKindView:
+			views = append(views, definition)
+		default:
+			ordered = append(ordered, definition)
+		}
+	}
+
+	for len(views) > 0 {
+		pending := make(map[string]bool, len(views))
+		for _, view := range views {
+			pending[view.Name] = true
+		}
+
+		var blocked []CollectionDefinition
+		for _, view := range views {
+			if pending[view.ViewOn] {
+				blocked = append(blocked, view)
+				continue
+			}
+			ordered = append(ordered, view)
+		}
+		if len(blocked) == len(views) {
+			// A cycle cannot be created on the source; keep the rest as listed.
+			return append(ordered, blocked...)
+		}
+		views = blocked
+	}
+	return ordered
+}
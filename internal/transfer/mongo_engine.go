@@ -2,15 +2,16 @@ package transfer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 type mongoEngine struct {
@@ -19,6 +20,8 @@ type mongoEngine struct {
 	options      Options
 	sourceClient *mongo.Client
 	targetClient *mongo.Client
+	report       *TransferReport
+	transformer  *DocumentTransformer
 }
 
 func newMongoEngine(sourceConfig, targetConfig *config.Config, options Options) (*mongoEngine, error) {
@@ -26,45 +29,52 @@ func newMongoEngine(sourceConfig, targetConfig *config.Config, options Options)
 		sourceConfig: sourceConfig,
 		targetConfig: targetConfig,
 		options:      options,
+		report:       NewTransferReport(),
 	}
+
+	transformer, err := NewDocumentTransformer(options.MongoTransforms)
+	if err != nil {
+		return nil, err
+	}
+	engine.transformer = transformer
 	return engine, nil
 }
 
-func (e *mongoEngine) Execute() error {
+func (e *mongoEngine) Execute() (*TransferReport, error) {
 	e.options.Logger.Info("Starting MongoDB transfer...")
+	defer e.report.Finish()
+
+	if e.options.RegenerateIDs {
+		e.options.Logger.Warn("Regenerating _id values: references to source _ids in other documents will not resolve on the target.")
+	}
 
 	if err := e.connect(); err != nil {
-		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+		return e.report, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 	defer e.cleanup()
 
 	if err := e.transfer(); err != nil {
-		return err
+		return e.report, err
 	}
 
 	e.options.Logger.Info("MongoDB transfer completed successfully.")
-	return nil
+	return e.report, nil
 }
 
 func (e *mongoEngine) connect() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	sourceClient, err := mongo.Connect(ctx, options.Client().ApplyURI(e.sourceConfig.GetMongoURI()))
+	sourceClient, err := database.ConnectMongo(ctx, e.sourceConfig, database.PingMongo, e.options.Logger)
 	if err != nil {
 		return fmt.Errorf("failed to connect to source MongoDB: %w", err)
 	}
-	if err := sourceClient.Ping(ctx, readpref.Primary()); err != nil {
-		return fmt.Errorf("failed to ping source MongoDB: %w", err)
-	}
 
-	targetClient, err := mongo.Connect(ctx, options.Client().ApplyURI(e.targetConfig.GetMongoURI()))
+	targetClient, err := database.ConnectMongo(ctx, e.targetConfig, database.PingMongo, e.options.Logger)
 	if err != nil {
+		_ = sourceClient.Disconnect(context.Background())
 		return fmt.Errorf("failed to connect to target MongoDB: %w", err)
 	}
-	if err := targetClient.Ping(ctx, readpref.Primary()); err != nil {
-		return fmt.Errorf("failed to ping target MongoDB: %w", err)
-	}
 
 	e.sourceClient = sourceClient
 	e.targetClient = targetClient
@@ -96,9 +106,14 @@ func (e *mongoEngine) transfer() error {
 	sourceDB := e.sourceClient.Database(sourceDBName)
 	targetDB := e.targetClient.Database(targetDBName)
 
-	collections, err := sourceDB.ListCollectionNames(ctx, bson.D{})
+	collections, err := listCollectionDefinitions(ctx, sourceDB)
 	if err != nil {
-		return fmt.Errorf("failed to list collections: %w", err)
+		return err
+	}
+
+	if len(collections) == 0 {
+		e.options.Logger.Infof("Nothing to transfer: source database %s has no collections.", sourceDBName)
+		return nil
 	}
 
 	copyIndexes := !e.options.DataOnly
@@ -109,8 +124,13 @@ func (e *mongoEngine) transfer() error {
 		return nil
 	}
 
-	for _, collectionName := range collections {
-		if err := e.cloneCollection(ctx, sourceDB, targetDB, collectionName, copyIndexes, copyData); err != nil {
+	for _, definition := range collections {
+		result := TableResult{Table: definition.Name}
+		started := time.Now()
+		err := e.cloneCollection(ctx, sourceDB, targetDB, definition, copyIndexes, copyData, &result)
+		result.Duration = time.Since(started)
+		e.report.Record(result, err)
+		if err != nil {
 			return err
 		}
 	}
@@ -122,18 +142,41 @@ func (e *mongoEngine) cloneCollection(
 	ctx context.Context,
 	sourceDB *mongo.Database,
 	targetDB *mongo.Database,
-	collectionName string,
+	definition CollectionDefinition,
 	copyIndexes bool,
 	copyData bool,
+	result *TableResult,
 ) error {
-	e.options.Logger.Infof("Transferring collection %s...", collectionName)
+	collectionName := definition.Name
+	e.options.Logger.Infof("Transferring %s %s...", definition.Kind, collectionName)
 
 	sourceCollection := sourceDB.Collection(collectionName)
 	targetCollection := targetDB.Collection(collectionName)
 
-	if err := targetCollection.Drop(ctx); err != nil {
-		if !isNamespaceNotFound(err) {
-			return fmt.Errorf("failed to drop target collection %s: %w", collectionName, err)
+	if definition.Kind == CollectionKindView {
+		// Views hold no data; they are recreated with the schema.
+		result.Status = StatusSkipped
+		if !copyIndexes {
+			return nil
+		}
+	}
+
+	if err := PrepareTargetCollection(ctx, targetCollection, e.options.Append); err != nil {
+		return fmt.Errorf("failed to drop target collection %s: %w", collectionName, err)
+	}
+
+	if definition.Kind == CollectionKindView {
+		err := targetDB.CreateView(ctx, collectionName, definition.ViewOn, definition.Pipeline)
+		if err != nil && !(e.options.Append && isNamespaceExists(err)) {
+			return fmt.Errorf("failed to create view %s: %w", collectionName, err)
+		}
+		return nil
+	}
+
+	if opts := definition.CreateCollectionOptions(); opts != nil {
+		err := targetDB.CreateCollection(ctx, collectionName, opts)
+		if err != nil && !(e.options.Append && isNamespaceExists(err)) {
+			return fmt.Errorf("failed to create %s collection %s: %w", definition.Kind, collectionName, err)
 		}
 	}
 
@@ -159,18 +202,26 @@ func (e *mongoEngine) cloneCollection(
 	defer cursor.Close(ctx)
 
 	batch := make([]interface{}, 0, batchSize)
+	var batchBytes int64
 	for cursor.Next(ctx) {
 		var document bson.M
 		if err := cursor.Decode(&document); err != nil {
 			return fmt.Errorf("failed to decode document from %s: %w", collectionName, err)
 		}
 
+		result.RowsAttempted++
+		result.Bytes += int64(len(cursor.Current))
+		batchBytes += int64(len(cursor.Current))
+		if e.transformer != nil {
+			e.transformer.Apply(document)
+		}
 		batch = append(batch, document)
 		if len(batch) >= batchSize {
-			if err := e.insertBatch(ctx, targetCollection, batch); err != nil {
+			if err := e.insertBatch(ctx, targetCollection, batch, batchBytes, result); err != nil {
 				return fmt.Errorf("failed to insert batch into %s: %w", collectionName, err)
 			}
 			batch = batch[:0]
+			batchBytes = 0
 		}
 	}
 
@@ -179,7 +230,7 @@ func (e *mongoEngine) cloneCollection(
 	}
 
 	if len(batch) > 0 {
-		if err := e.insertBatch(ctx, targetCollection, batch); err != nil {
+		if err := e.insertBatch(ctx, targetCollection, batch, batchBytes, result); err != nil {
 			return fmt.Errorf("failed to insert final batch into %s: %w", collectionName, err)
 		}
 	}
@@ -246,17 +297,66 @@ func (e *mongoEngine) cloneIndexes(ctx context.Context, sourceCollection, target
 	return nil
 }
 
-func (e *mongoEngine) insertBatch(ctx context.Context, collection *mongo.Collection, batch []interface{}) error {
-	if len(batch) == 0 {
-		return nil
+func (e *mongoEngine) insertBatch(ctx context.Context, collection *mongo.Collection, batch []interface{}, batchBytes int64, result *TableResult) error {
+	if err := e.options.Throttle.Wait(ctx, int64(len(batch)), batchBytes); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+	if e.options.RegenerateIDs {
+		StripIDs(batch)
+	}
+	skipped, err := InsertDocuments(ctx, collection, batch, e.options.Append)
+	if skipped > 0 {
+		e.options.Logger.Debugf("Skipped %d existing documents in %s", skipped, collection.Name())
 	}
 
-	opts := options.InsertMany().SetOrdered(false)
-	_, err := collection.InsertMany(ctx, batch, opts)
+	result.RowsSkipped += int64(skipped)
+	result.RowsSucceeded += int64(len(batch)-skipped) - failedWrites(err, len(batch))
 	return err
 }
 
+// failedWrites counts the documents of an unordered batch that were not
+// written. A bulk write exception lists them; any other error fails the batch.
+func failedWrites(err error, batchSize int) int64 {
+	if err == nil {
+		return 0
+	}
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+		return int64(len(bulkErr.WriteErrors))
+	}
+	return int64(batchSize)
+}
+
 func isNamespaceNotFound(err error) bool {
 	cmdErr, ok := err.(mongo.CommandError)
 	return ok && cmdErr.Code == 26
 }
+
+func isNamespaceExists(err error) bool {
+	cmdErr, ok := err.(mongo.CommandError)
+	return ok && cmdErr.Code == 48
+}
+
+// listCollectionDefinitions reads the source's collections with their type
+// and creation options, ordered so views follow what they are defined on.
+func listCollectionDefinitions(ctx context.Context, db *mongo.Database) ([]CollectionDefinition, error) {
+	cursor, err := db.ListCollections(ctx, bson.D{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var definitions []CollectionDefinition
+	for cursor.Next(ctx) {
+		definition, err := ParseCollectionInfo(cursor.Current)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	return OrderCollectionDefinitions(definitions), nil
+}
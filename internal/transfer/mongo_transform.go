@@ -0,0 +1,164 @@
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MongoRemoveField is the --mongo-transform operation that deletes a field
+// instead of rewriting its value.
+const MongoRemoveField = "remove"
+
+// DocumentTransformer rewrites or removes fields of MongoDB documents by
+// dot-path before they are inserted into the target.
+type DocumentTransformer struct {
+	rules []fieldRule
+}
+
+type fieldRule struct {
+	path   []string
+	remove bool
+	fn     TransformFunc
+}
+
+// ParseMongoTransformFlags turns "path:operation" flag values into the map
+// stored in Options.MongoTransforms. The operation is remove or any
+// registered transform.
+func ParseMongoTransformFlags(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]string, len(values))
+	for _, value := range values {
+		path, spec, ok := strings.Cut(value, ":")
+		if !ok || spec == "" || !validFieldPath(path) {
+			return nil, fmt.Errorf("invalid MongoDB transform %q (expected field.path:operation)", value)
+		}
+		if spec != MongoRemoveField {
+			if _, err := NewTransform(spec); err != nil {
+				return nil, err
+			}
+		}
+		parsed[path] = spec
+	}
+	return parsed, nil
+}
+
+func validFieldPath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, part := range strings.Split(path, ".") {
+		if part == "" || strings.HasPrefix(part, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// NewDocumentTransformer builds a transformer from path -> operation specs.
+// It returns nil when there is nothing to transform.
+func NewDocumentTransformer(specs map[string]string) (*DocumentTransformer, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	paths := make([]string, 0, len(specs))
+	for path := range specs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	transformer := &DocumentTransformer{}
+	for _, path := range paths {
+		rule := fieldRule{path: strings.Split(path, ".")}
+		if specs[path] == MongoRemoveField {
+			rule.remove = true
+		} else {
+			fn, err := NewTransform(specs[path])
+			if err != nil {
+				return nil, err
+			}
+			rule.fn = fn
+		}
+		transformer.rules = append(transformer.rules, rule)
+	}
+	return transformer, nil
+}
+
+// Apply rewrites the document in place. Missing fields are left alone. As in
+// MongoDB queries, arrays along the path are traversed element by element,
+// and a transform reaching an array applies to each element.
+func (t *DocumentTransformer) Apply(document bson.M) {
+	for _, rule := range t.rules {
+		applyFieldRule(document, rule.path, rule)
+	}
+}
+
+func applyFieldRule(value interface{}, path []string, rule fieldRule) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		field, ok := v[path[0]]
+		if !ok {
+			return v
+		}
+		switch {
+		case len(path) > 1:
+			v[path[0]] = applyFieldRule(field, path[1:], rule)
+		case rule.remove:
+			delete(v, path[0])
+		default:
+			v[path[0]] = transformLeaf(field, rule.fn)
+		}
+		return v
+	case bson.D:
+		for i, element := range v {
+			if element.Key != path[0] {
+				continue
+			}
+			switch {
+			case len(path) > 1:
+				v[i].Value = applyFieldRule(element.Value, path[1:], rule)
+			case rule.remove:
+				return append(v[:i], v[i+1:]...)
+			default:
+				v[i].Value = transformLeaf(element.Value, rule.fn)
+			}
+			return v
+		}
+		return v
+	case bson.A:
+		for i, element := range v {
+			v[i] = applyFieldRule(element, path, rule)
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = applyFieldRule(element, path, rule)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func transformLeaf(value interface{}, fn TransformFunc) interface{} {
+	switch v := value.(type) {
+	case bson.A:
+		for i, element := range v {
+			v[i] = fn(element)
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = fn(element)
+		}
+		return v
+	default:
+		return fn(value)
+	}
+}
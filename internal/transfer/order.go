@@ -0,0 +1,88 @@
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+const (
+	// UnkeyedOffset pages tables without a stable order key with OFFSET and
+	// LIMIT like every other table, after a warning.
+	UnkeyedOffset = "offset"
+	// UnkeyedFullRead reads tables without a stable order key in a single
+	// query, committing every batch, so no row is skipped or read twice.
+	UnkeyedFullRead = "full-read"
+)
+
+func ValidateUnkeyedStrategy(strategy string) error {
+	switch strategy {
+	case "", UnkeyedOffset, UnkeyedFullRead:
+		return nil
+	default:
+		return fmt.Errorf("invalid unkeyed strategy %q (use %s or %s)", strategy, UnkeyedOffset, UnkeyedFullRead)
+	}
+}
+
+// OrderKey picks the columns that give a table's rows a stable order for
+// OFFSET/LIMIT paging: the primary key, else the narrowest unique index whose
+// columns are all NOT NULL and that covers every row. It returns nil when
+// the table has neither, along with a description of the key it chose.
+func OrderKey(table schema.Table) ([]string, string) {
+	if len(table.PrimaryKeys) > 0 {
+		return table.PrimaryKeys, "primary key"
+	}
+
+	notNull := make(map[string]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		if !col.IsNullable {
+			notNull[col.Name] = true
+		}
+	}
+
+	var candidates []schema.Index
+	for _, idx := range table.Indexes {
+		if !idx.IsUnique || idx.Partial || len(idx.Columns) == 0 {
+			continue
+		}
+		usable := true
+		for _, column := range idx.Columns {
+			if !notNull[unquoteIndexColumn(column)] {
+				usable = false
+				break
+			}
+		}
+		if usable {
+			candidates = append(candidates, idx)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ""
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].Columns) != len(candidates[j].Columns) {
+			return len(candidates[i].Columns) < len(candidates[j].Columns)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	chosen := candidates[0]
+
+	columns := make([]string, len(chosen.Columns))
+	for i, column := range chosen.Columns {
+		columns[i] = unquoteIndexColumn(column)
+	}
+	return columns, "unique index " + chosen.Name
+}
+
+// unquoteIndexColumn turns a column as written by pg_get_indexdef back into
+// its name. Expressions and columns with a sort order do not match any table
+// column afterwards, which rules out their index.
+func unquoteIndexColumn(column string) string {
+	if len(column) >= 2 && strings.HasPrefix(column, `"`) && strings.HasSuffix(column, `"`) {
+		return strings.ReplaceAll(column[1:len(column)-1], `""`, `"`)
+	}
+	return column
+}
@@ -2,8 +2,10 @@ package transfer
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
 	"github.com/kadirbelkuyu/DBRTS/internal/database"
@@ -17,6 +19,9 @@ type postgresEngine struct {
 	options      Options
 	sourceConn   *database.Connection
 	targetConn   *database.Connection
+	report       *TransferReport
+	snapshotID   string
+	serverSide   bool
 }
 
 func newPostgresEngine(sourceConfig, targetConfig *config.Config, options Options) *postgresEngine {
@@ -24,31 +29,51 @@ func newPostgresEngine(sourceConfig, targetConfig *config.Config, options Option
 		sourceConfig: sourceConfig,
 		targetConfig: targetConfig,
 		options:      options,
+		report:       NewTransferReport(),
 	}
 }
 
-func (e *postgresEngine) Execute() error {
+func (e *postgresEngine) Execute() (*TransferReport, error) {
 	e.options.Logger.Info("Starting PostgreSQL transfer...")
+	defer e.report.Finish()
 
 	if err := e.connect(); err != nil {
-		return fmt.Errorf("connection error: %w", err)
+		return e.report, fmt.Errorf("connection error: %w", err)
 	}
 	defer e.cleanup()
 
+	stopListening, err := startListeners(e.sourceConfig, e.targetConfig, e.options.ListenChannel, e.options.Logger)
+	if err != nil {
+		return e.report, err
+	}
+	defer stopListening()
+
+	if e.options.Query != "" {
+		if err := e.transferQuery(); err != nil {
+			return e.report, fmt.Errorf("query transfer failed: %w", err)
+		}
+		e.options.Logger.Info("PostgreSQL transfer completed successfully.")
+		return e.report, nil
+	}
+
+	if err := e.checkTransforms(); err != nil {
+		return e.report, err
+	}
+
 	if !e.options.DataOnly {
 		if err := e.transferSchema(); err != nil {
-			return fmt.Errorf("schema transfer failed: %w", err)
+			return e.report, fmt.Errorf("schema transfer failed: %w", err)
 		}
 	}
 
 	if !e.options.SchemaOnly {
 		if err := e.transferData(); err != nil {
-			return fmt.Errorf("data transfer failed: %w", err)
+			return e.report, fmt.Errorf("data transfer failed: %w", err)
 		}
 	}
 
 	e.options.Logger.Info("PostgreSQL transfer completed successfully.")
-	return nil
+	return e.report, nil
 }
 
 func (e *postgresEngine) connect() error {
@@ -82,68 +107,434 @@ func (e *postgresEngine) transferSchema() error {
 	e.options.Logger.Info("Transferring schema...")
 
 	extractor := schema.NewExtractor(e.sourceConn, e.options.Logger)
-	creator := schema.NewCreator(e.targetConn, e.options.Logger)
+	creator := schema.NewCreator(e.targetConn, e.options.Logger, schema.CreateOptions{
+		IdentifierCase:  e.options.IdentifierCase,
+		PreserveStorage: e.options.PreserveStorage,
+		SchemaMap:       e.options.SchemaMap,
+	})
+
+	extensions, err := extractor.ExtractExtensions()
+	if err != nil {
+		return fmt.Errorf("failed to extract extensions: %w", err)
+	}
+
+	domains, err := extractor.ExtractDomains()
+	if err != nil {
+		return fmt.Errorf("failed to extract domains: %w", err)
+	}
+
+	sequences, err := extractor.ExtractSequences()
+	if err != nil {
+		return fmt.Errorf("failed to extract sequences: %w", err)
+	}
 
 	tables, err := extractor.ExtractTables("")
 	if err != nil {
 		return fmt.Errorf("failed to extract tables: %w", err)
 	}
+	tables, err = ExcludeColumns(tables, e.options.ExcludeColumns)
+	if err != nil {
+		return err
+	}
 
-	if err := creator.CreateTables(tables); err != nil {
+	objects := schema.Objects{
+		Extensions: extensions,
+		Domains:    domains,
+		Sequences:  sequences,
+		Tables:     tables,
+	}
+
+	if e.options.SchemaDiff {
+		if err := e.applySchemaDiff(creator, objects); err != nil {
+			return err
+		}
+	} else if err := creator.CreateSchema(objects); err != nil {
 		return fmt.Errorf("failed to create tables: %w", err)
 	}
 
+	// Views come last, once every table they select from exists.
+	if err := e.transferViews(extractor, creator); err != nil {
+		return err
+	}
+
 	e.options.Logger.Info("Schema transfer completed.")
 	return nil
 }
 
+// transferViews recreates the source's views on the target, only those it
+// lacks under --schema-diff. View definitions are copied as written, so
+// they are skipped when --map-schema or --identifier-case renames what
+// they select from: they would fail, or read another schema's tables.
+func (e *postgresEngine) transferViews(extractor *schema.Extractor, creator *schema.Creator) error {
+	views, err := extractor.ExtractViews()
+	if err != nil {
+		return fmt.Errorf("failed to extract views: %w", err)
+	}
+	if len(views) == 0 {
+		return nil
+	}
+
+	if len(e.options.SchemaMap) > 0 || schema.FoldsIdentifiers(e.options.IdentifierCase) {
+		e.options.Logger.Warnf("Skipping %d view(s): their definitions name the source's schemas and identifiers, which --map-schema and --identifier-case rename on the target", len(views))
+		return nil
+	}
+
+	if e.options.SchemaDiff {
+		targetViews, err := schema.NewExtractor(e.targetConn, e.options.Logger).ExtractViews()
+		if err != nil {
+			return fmt.Errorf("failed to extract target views: %w", err)
+		}
+		views = schema.MissingViews(views, targetViews)
+		if len(views) == 0 {
+			return nil
+		}
+	}
+
+	e.options.Logger.Infof("Creating %d view(s)...", len(views))
+	if err := creator.CreateViews(views); err != nil {
+		return fmt.Errorf("failed to create views: %w", err)
+	}
+	return nil
+}
+
+// applySchemaDiff reads the target schema and creates only what it lacks.
+func (e *postgresEngine) applySchemaDiff(creator *schema.Creator, source schema.Objects) error {
+	targetExtractor := schema.NewExtractor(e.targetConn, e.options.Logger)
+
+	targetDomains, err := targetExtractor.ExtractDomains()
+	if err != nil {
+		return fmt.Errorf("failed to extract target domains: %w", err)
+	}
+	targetTables, err := targetExtractor.ExtractTables("")
+	if err != nil {
+		return fmt.Errorf("failed to extract target tables: %w", err)
+	}
+
+	diff := schema.DiffSchema(source, schema.Objects{Domains: targetDomains, Tables: targetTables}, e.options.IdentifierCase)
+	if err := creator.ApplyDiff(diff); err != nil {
+		return fmt.Errorf("failed to apply schema diff: %w", err)
+	}
+	return nil
+}
+
 func (e *postgresEngine) transferData() error {
 	e.options.Logger.Info("Transferring data...")
 
+	// Exported before the tables are listed, and held open until every
+	// worker has finished, since the snapshot dies with its transaction.
+	if e.options.ConsistentSnapshot {
+		snapshot, err := exportSnapshot(e.sourceConn.DB)
+		if err != nil {
+			return err
+		}
+		defer snapshot.Close()
+		e.snapshotID = snapshot.ID
+		e.options.Logger.Infof("Copying from source snapshot %s", snapshot.ID)
+	}
+
 	extractor := schema.NewExtractor(e.sourceConn, e.options.Logger)
 	tables, err := extractor.ExtractTables("")
 	if err != nil {
 		return fmt.Errorf("failed to extract table metadata: %w", err)
 	}
+	tables, err = ExcludeColumns(tables, e.options.ExcludeColumns)
+	if err != nil {
+		return err
+	}
+	if e.snapshotID != "" {
+		for i := range tables {
+			count, err := SnapshotRowCount(e.sourceConn.DB, e.snapshotID, tables[i])
+			if err != nil {
+				return err
+			}
+			tables[i].RowCount = count
+		}
+	}
 
-	totalRows := int64(0)
-	for _, table := range tables {
-		totalRows += table.RowCount
+	e.serverSide = e.useServerSideCopy()
+
+	pending, empty, totalRows := PartitionByRows(tables)
+	for _, table := range empty {
+		e.report.Record(TableResult{Schema: table.Schema, Table: table.Name, Status: StatusSkipped}, nil)
 	}
 
-	progressBar := progress.NewBar(totalRows, "Data transfer")
+	if len(pending) == 0 {
+		e.options.Logger.Infof("Nothing to transfer: %d table(s), none with rows.", len(tables))
+		if err := e.transferSequenceValues(); err != nil {
+			return err
+		}
+		if err := e.refreshMaterializedViews(); err != nil {
+			return err
+		}
+		if e.options.VerifyChecksums {
+			return e.verifyChecksums(tables)
+		}
+		return nil
+	}
+
+	progressBars := progress.NewMulti(totalRows, "Data transfer")
 
 	ctx := context.Background()
-	workerPool := NewWorkerPool(e.options.ParallelWorkers, e.options.BatchSize)
+	err = RunTableJobs(ctx, e.options.ParallelWorkers, e.options.BatchSize, pending, func(t schema.Table) error {
+		tableBar := progressBars.Table(t.Schema+"."+t.Name, t.RowCount)
+		defer tableBar.Done()
+
+		started := time.Now()
+		load := func() error {
+			return e.transferTable(ctx, t, tableBar)
+		}
+
+		var err error
+		if e.options.DisableTriggers {
+			err = WithTriggersDisabled(e.execTarget, e.options.Logger, e.onTarget(t), e.options.IdentifierCase, load)
+		} else {
+			err = load()
+		}
+
+		if err != nil {
+			e.options.Logger.Errorf("Table transfer failed for %s: %v", t.Name, err)
+		}
+		e.report.Record(TableResult{Schema: t.Schema, Table: t.Name, Duration: time.Since(started)}, err)
+		return err
+	})
+	progressBars.Finish()
 
-	var wg sync.WaitGroup
+	// One failed table does not stop the others, but the run fails once they
+	// are done, before sequences and verification touch a partial copy.
+	if err != nil {
+		return fmt.Errorf("%d table(s) failed, first error: %w", len(e.report.Failed()), err)
+	}
+
+	e.options.Logger.Info("Data transfer completed.")
+
+	if err := e.transferSequenceValues(); err != nil {
+		return err
+	}
+	if err := e.refreshMaterializedViews(); err != nil {
+		return err
+	}
+
+	if e.options.VerifyChecksums {
+		return e.verifyChecksums(tables)
+	}
+	return nil
+}
+
+// transferSequenceValues copies the sequences' current values once the rows
+// are in, so serial and identity columns continue after the copied keys
+// rather than colliding with them. The values are read after the copy, so
+// they are never behind the rows it took.
+func (e *postgresEngine) transferSequenceValues() error {
+	sequences, err := schema.NewExtractor(e.sourceConn, e.options.Logger).ExtractSequences()
+	if err != nil {
+		return fmt.Errorf("failed to extract sequences: %w", err)
+	}
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	e.options.Logger.Infof("Setting %d sequence value(s)...", len(sequences))
+	creator := schema.NewCreator(e.targetConn, e.options.Logger, schema.CreateOptions{
+		IdentifierCase: e.options.IdentifierCase,
+		SchemaMap:      e.options.SchemaMap,
+	})
+	return creator.SetSequenceValues(sequences)
+}
+
+// refreshMaterializedViews fills the target's materialized views, which the
+// schema step creates empty, when --refresh-matviews is set.
+func (e *postgresEngine) refreshMaterializedViews() error {
+	if !e.options.RefreshMatViews {
+		return nil
+	}
+
+	views, err := schema.NewExtractor(e.sourceConn, e.options.Logger).ExtractViews()
+	if err != nil {
+		return fmt.Errorf("failed to extract views: %w", err)
+	}
+	creator := schema.NewCreator(e.targetConn, e.options.Logger, schema.CreateOptions{
+		IdentifierCase: e.options.IdentifierCase,
+		SchemaMap:      e.options.SchemaMap,
+	})
+	return creator.RefreshMaterializedViews(views)
+}
+
+// checkTransforms resolves every table's transforms up front, so a transform
+// that cannot apply to its column fails the run before the target is touched.
+func (e *postgresEngine) checkTransforms() error {
+	if len(e.options.Transforms) == 0 {
+		return nil
+	}
+
+	tables, err := schema.NewExtractor(e.sourceConn, e.options.Logger).ExtractTables("")
+	if err != nil {
+		return fmt.Errorf("failed to extract table metadata: %w", err)
+	}
 	for _, table := range tables {
-		if table.RowCount == 0 {
-			continue
+		if _, err := ColumnTransforms(table, e.options.Transforms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *postgresEngine) transferTable(ctx context.Context, table schema.Table, progressBar *progress.TableBar) error {
+	transforms, err := ColumnTransforms(table, e.options.Transforms)
+	if err != nil {
+		return err
+	}
+
+	// Transforms run in this process, so those tables always take the
+	// regular path.
+	if e.serverSide && len(transforms) == 0 {
+		copied, err := e.copyServerSide(table)
+		if err == nil {
+			progressBar.Start()
+			progressBar.IncrementBy(copied)
+			e.report.Record(TableResult{Schema: table.Schema, Table: table.Name, RowsAttempted: copied, RowsSucceeded: copied}, nil)
+			return nil
+		}
+		e.options.Logger.Warnf("Server-side copy of %s.%s failed, using the regular copy: %v", table.Schema, table.Name, err)
+	}
+
+	if ShouldSplitTable(table, e.options.SplitThreshold) {
+		return e.transferTableInRanges(table, transforms, progressBar)
+	}
+
+	job := &DataTransferJob{
+		Table:          table,
+		SourceConn:     e.sourceConn,
+		TargetConn:     e.targetConn,
+		BatchSize:      e.options.BatchSize,
+		ProgressBar:    progressBar,
+		Logger:         e.options.Logger,
+		IdentifierCase: e.options.IdentifierCase,
+		Transforms:     transforms,
+		Snapshot:       e.snapshotID,
+		Throttle:       e.options.Throttle,
+		FullRead:       e.readInFull(table),
+		UseCopy:        e.options.UseCopy,
+		SchemaMap:      e.options.SchemaMap,
+	}
+
+	err = e.options.Limiter.Do(ctx, job.Execute)
+	e.report.Record(job.Result(), nil)
+	return err
+}
+
+// queryKeyBounds reads the split bounds from the snapshot when there is one,
+// so rows deleted since it was taken still fall inside a range.
+func (e *postgresEngine) queryKeyBounds(query string, minKey, maxKey *sql.NullInt64) error {
+	if e.snapshotID == "" {
+		return e.sourceConn.DB.QueryRow(query).Scan(minKey, maxKey)
+	}
+
+	rows, done, err := querySnapshot(e.sourceConn.DB, e.snapshotID, query)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
 		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(minKey, maxKey)
+}
 
+// readInFull reports whether a table without a stable order key is read in
+// one query. Otherwise it is paged as usual, which can skip or repeat rows
+// if the table changes during the copy, so that case is warned about.
+func (e *postgresEngine) readInFull(table schema.Table) bool {
+	key, source := OrderKey(table)
+	if len(key) > 0 {
+		if source != "primary key" {
+			e.options.Logger.Debugf("Paging %s.%s by its %s", table.Schema, table.Name, source)
+		}
+		return false
+	}
+
+	if e.options.UnkeyedStrategy == UnkeyedFullRead {
+		e.options.Logger.Infof("%s.%s has no primary key or usable unique index; reading it in a single query", table.Schema, table.Name)
+		return true
+	}
+	e.options.Logger.Warnf("%s.%s has no primary key or usable unique index; paging it may skip or repeat rows if it changes during the copy (use --unkeyed-strategy %s)",
+		table.Schema, table.Name, UnkeyedFullRead)
+	return false
+}
+
+func (e *postgresEngine) execTarget(query string) error {
+	_, err := e.targetConn.DB.Exec(query)
+	return err
+}
+
+// transferTableInRanges splits a large table on its numeric primary key and
+// copies the resulting ranges concurrently, bounded by ParallelWorkers and the
+// shared concurrency limiter.
+func (e *postgresEngine) transferTableInRanges(table schema.Table, transforms []TransformFunc, progressBar *progress.TableBar) error {
+	key, _ := SplittablePrimaryKey(table)
+
+	var minKey, maxKey sql.NullInt64
+	boundsQuery := fmt.Sprintf(`SELECT MIN("%s"), MAX("%s") FROM "%s"."%s"`, key, key, table.Schema, table.Name)
+	if err := e.queryKeyBounds(boundsQuery, &minKey, &maxKey); err != nil {
+		return fmt.Errorf("failed to read key bounds: %w", err)
+	}
+	if !minKey.Valid || !maxKey.Valid {
+		return nil
+	}
+
+	workers := e.options.ParallelWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	ranges := SplitRange(minKey.Int64, maxKey.Int64, workers)
+	e.options.Logger.Infof("Splitting %s.%s (%d rows) into %d ranges on %s", table.Schema, table.Name, table.RowCount, len(ranges), key)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, workers)
+
+	for _, r := range ranges {
 		wg.Add(1)
-		go func(t schema.Table) {
+		sem <- struct{}{}
+		go func(r RowRange) {
 			defer wg.Done()
+			defer func() { <-sem }()
 
 			job := &DataTransferJob{
-				Table:       t,
-				SourceConn:  e.sourceConn,
-				TargetConn:  e.targetConn,
-				BatchSize:   e.options.BatchSize,
-				ProgressBar: progressBar,
-				Logger:      e.options.Logger,
+				Table:          table,
+				SourceConn:     e.sourceConn,
+				TargetConn:     e.targetConn,
+				BatchSize:      e.options.BatchSize,
+				ProgressBar:    progressBar,
+				Logger:         e.options.Logger,
+				Range:          &r,
+				RangeKey:       key,
+				IdentifierCase: e.options.IdentifierCase,
+				Transforms:     transforms,
+				Snapshot:       e.snapshotID,
+				Throttle:       e.options.Throttle,
+				UseCopy:        e.options.UseCopy,
+				SchemaMap:      e.options.SchemaMap,
 			}
 
-			if err := workerPool.SubmitJob(ctx, job); err != nil {
-				e.options.Logger.Errorf("Table transfer failed for %s: %v", t.Name, err)
+			err := e.options.Limiter.Do(context.Background(), job.Execute)
+			e.report.Record(job.Result(), nil)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
 			}
-		}(table)
+		}(r)
 	}
 
 	wg.Wait()
-	progressBar.Finish()
-
-	e.options.Logger.Info("Data transfer completed.")
-	return nil
+	return firstErr
 }
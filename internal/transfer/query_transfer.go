@@ -0,0 +1,138 @@
+package transfer
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/progress"
+)
+
+// transferQuery copies the result of Options.Query into Options.TargetTable,
+// creating the table from the result's column types when it is missing.
+func (e *postgresEngine) transferQuery() error {
+	schemaName, tableName := SplitTargetTable(e.options.TargetTable)
+	e.options.Logger.Infof("Copying query result into %s.%s...", schemaName, tableName)
+
+	// A trailing semicolon would end the subquery below early.
+	query := strings.TrimRight(strings.TrimSpace(e.options.Query), "; \t\n")
+
+	// LIMIT 0 only plans the query, which is enough to learn its columns.
+	rows, err := e.sourceConn.DB.Query(fmt.Sprintf("SELECT * FROM (%s) AS q LIMIT 0", query))
+	if err != nil {
+		return fmt.Errorf("failed to run query: %w", err)
+	}
+	columnTypes, err := rows.ColumnTypes()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read query columns: %w", err)
+	}
+
+	columns, err := QueryColumns(columnTypes)
+	if err != nil {
+		return err
+	}
+	for i, columnType := range columnTypes {
+		if columnType.DatabaseTypeName() == "" {
+			e.options.Logger.Warnf("Column %s has a type DBRTS cannot name; creating it as text", columns[i].Name)
+		}
+	}
+
+	table := schema.Table{Schema: schemaName, Name: tableName, Columns: columns}
+	creator := schema.NewCreator(e.targetConn, e.options.Logger, schema.CreateOptions{IdentifierCase: e.options.IdentifierCase})
+	if err := creator.CreateTables([]schema.Table{table}); err != nil {
+		return err
+	}
+
+	progressBars := progress.NewMulti(-1, "Query transfer")
+	tableBar := progressBars.Table(schemaName+"."+tableName, -1)
+
+	job := &DataTransferJob{
+		Table:          table,
+		SourceConn:     e.sourceConn,
+		TargetConn:     e.targetConn,
+		BatchSize:      e.options.BatchSize,
+		ProgressBar:    tableBar,
+		Logger:         e.options.Logger,
+		IdentifierCase: e.options.IdentifierCase,
+		Throttle:       e.options.Throttle,
+		FullRead:       true,
+		UseCopy:        e.options.UseCopy,
+		Query:          query,
+	}
+
+	started := time.Now()
+	err = job.Execute()
+	tableBar.Done()
+	progressBars.Finish()
+
+	result := job.Result()
+	result.Duration = time.Since(started)
+	e.report.Record(result, err)
+	return err
+}
+
+// SplitTargetTable splits a --target-table of the form schema.table. A bare
+// table name goes to the public schema.
+func SplitTargetTable(name string) (string, string) {
+	if schemaName, tableName, ok := strings.Cut(name, "."); ok {
+		return schemaName, tableName
+	}
+	return "public", name
+}
+
+// QueryColumns derives the target columns of a query transfer from the
+// result's metadata. Types lib/pq cannot name, such as enums and other
+// user-defined types, become text. Every column is nullable, since a result
+// set carries no constraints.
+func QueryColumns(columnTypes []*sql.ColumnType) ([]schema.Column, error) {
+	seen := make(map[string]bool, len(columnTypes))
+	columns := make([]schema.Column, 0, len(columnTypes))
+	for i, columnType := range columnTypes {
+		name := columnType.Name()
+		if seen[name] {
+			return nil, fmt.Errorf("the query returns more than one column named %q; give each a distinct alias", name)
+		}
+		seen[name] = true
+
+		column := schema.Column{Name: name, IsNullable: true, Position: i + 1}
+		column.DataType, column.MaxLength = queryColumnType(columnType)
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func queryColumnType(columnType *sql.ColumnType) (string, *int) {
+	name := strings.ToLower(columnType.DatabaseTypeName())
+	switch {
+	case name == "":
+		return "text", nil
+	case strings.HasPrefix(name, "_"):
+		return strings.TrimPrefix(name, "_") + "[]", nil
+	case name == "varchar":
+		// Without a type modifier lib/pq reports a negative length.
+		if length, ok := columnType.Length(); ok && length > 0 && length <= maxTypeModifier {
+			n := int(length)
+			return "varchar", &n
+		}
+		return "varchar", nil
+	case name == "bpchar":
+		if length, ok := columnType.Length(); ok && length > 0 && length <= maxTypeModifier {
+			return fmt.Sprintf("char(%d)", length), nil
+		}
+		return "bpchar", nil
+	case name == "numeric":
+		// An unconstrained numeric reports a precision outside 1-1000.
+		if precision, scale, ok := columnType.DecimalSize(); ok && precision >= 1 && precision <= 1000 && scale >= 0 && scale <= precision {
+			return fmt.Sprintf("numeric(%d,%d)", precision, scale), nil
+		}
+		return "numeric", nil
+	default:
+		return name, nil
+	}
+}
+
+// maxTypeModifier is the largest length PostgreSQL accepts for varchar(n).
+const maxTypeModifier = 10485760
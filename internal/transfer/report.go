@@ -0,0 +1,204 @@
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/humanize"
+)
+
+type TableStatus string
+
+const (
+	StatusSucceeded TableStatus = "succeeded"
+	StatusFailed    TableStatus = "failed"
+	StatusSkipped   TableStatus = "skipped"
+)
+
+// TableResult is the outcome of copying one table or collection.
+type TableResult struct {
+	Schema        string      `json:"schema,omitempty"`
+	Table         string      `json:"table"`
+	Status        TableStatus `json:"status"`
+	RowsAttempted int64       `json:"rows_attempted"`
+	RowsSucceeded int64       `json:"rows_succeeded"`
+	RowsFailed    int64       `json:"rows_failed"`
+	RowsSkipped   int64       `json:"rows_skipped,omitempty"`
+	Bytes         int64       `json:"bytes,omitempty"`
+	Seconds       float64     `json:"seconds,omitempty"`
+	RowsPerSecond float64     `json:"rows_per_second,omitempty"`
+	FirstError    string      `json:"first_error,omitempty"`
+
+	// Duration is the table's wall-clock copy time. Ranges of a split table
+	// run concurrently, so merged results keep the longest one.
+	Duration time.Duration `json:"-"`
+}
+
+func (r TableResult) QualifiedName() string {
+	if r.Schema == "" {
+		return r.Table
+	}
+	return r.Schema + "." + r.Table
+}
+
+// TransferReport accumulates per-table results from concurrent jobs. A table
+// split into ranges records once per range; the results are merged.
+type TransferReport struct {
+	mu      sync.Mutex
+	results map[string]*TableResult
+	order   []string
+	started time.Time
+	elapsed time.Duration
+}
+
+func NewTransferReport() *TransferReport {
+	return &TransferReport{results: make(map[string]*TableResult), started: time.Now()}
+}
+
+// Finish stops the report's wall clock; Totals uses it for the overall rate.
+func (r *TransferReport) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.elapsed = time.Since(r.started)
+}
+
+// Elapsed is the wall-clock time between NewTransferReport and Finish.
+func (r *TransferReport) Elapsed() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.elapsed
+}
+
+// Record merges result into the table's entry. Row counts add up, the first
+// error is kept, and any failure marks the whole table as failed.
+func (r *TransferReport) Record(result TableResult, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		result.Status = StatusFailed
+		if result.FirstError == "" {
+			result.FirstError = err.Error()
+		}
+	} else if result.Status == "" {
+		result.Status = StatusSucceeded
+	}
+
+	key := result.QualifiedName()
+	existing, ok := r.results[key]
+	if !ok {
+		result.RowsFailed = result.RowsAttempted - result.RowsSucceeded - result.RowsSkipped
+		r.results[key] = &result
+		r.order = append(r.order, key)
+		return
+	}
+
+	existing.RowsAttempted += result.RowsAttempted
+	existing.RowsSucceeded += result.RowsSucceeded
+	existing.RowsSkipped += result.RowsSkipped
+	existing.Bytes += result.Bytes
+	if result.Duration > existing.Duration {
+		existing.Duration = result.Duration
+	}
+	existing.RowsFailed = existing.RowsAttempted - existing.RowsSucceeded - existing.RowsSkipped
+	if result.Status == StatusFailed {
+		existing.Status = StatusFailed
+		if existing.FirstError == "" {
+			existing.FirstError = result.FirstError
+		}
+	}
+}
+
+// Results returns a snapshot of every table's result, sorted by name.
+func (r *TransferReport) Results() []TableResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]TableResult, 0, len(r.order))
+	for _, key := range r.order {
+		result := *r.results[key]
+		result.setRate()
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].QualifiedName() < results[j].QualifiedName()
+	})
+	return results
+}
+
+// Failed returns the results whose status is failed.
+func (r *TransferReport) Failed() []TableResult {
+	var failed []TableResult
+	for _, result := range r.Results() {
+		if result.Status == StatusFailed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Totals sums row counts across all tables.
+func (r *TransferReport) Totals() TableResult {
+	total := TableResult{Table: "total", Status: StatusSucceeded}
+	for _, result := range r.Results() {
+		total.RowsAttempted += result.RowsAttempted
+		total.RowsSucceeded += result.RowsSucceeded
+		total.RowsFailed += result.RowsFailed
+		total.RowsSkipped += result.RowsSkipped
+		total.Bytes += result.Bytes
+		if result.Status == StatusFailed {
+			total.Status = StatusFailed
+		}
+	}
+	total.Duration = r.Elapsed()
+	total.setRate()
+	return total
+}
+
+func (r *TableResult) setRate() {
+	if r.Duration <= 0 {
+		return
+	}
+	r.Seconds = r.Duration.Seconds()
+	r.RowsPerSecond = float64(r.RowsSucceeded) / r.Duration.Seconds()
+}
+
+// Summary renders the totals as a single line, e.g.
+// "Transferred 1,234,567 rows (≈2.1 GB) in 3m 12s — 6.4k rows/s".
+func Summary(total TableResult) string {
+	line := fmt.Sprintf("Transferred %s rows", FormatCount(total.RowsSucceeded))
+	if total.Bytes > 0 {
+		line += fmt.Sprintf(" (≈%s)", humanize.Bytes(total.Bytes))
+	}
+	if total.Duration > 0 {
+		line += fmt.Sprintf(" in %s — %s rows/s", humanize.Duration(total.Duration), FormatRate(total.RowsPerSecond))
+	}
+	return line
+}
+
+// FormatCount groups thousands with commas.
+func FormatCount(n int64) string {
+	digits := fmt.Sprintf("%d", n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + digits
+}
+
+// FormatRate shortens a per-second rate, e.g. 6400 becomes 6.4k.
+func FormatRate(rate float64) string {
+	switch {
+	case rate >= 1e6:
+		return fmt.Sprintf("%.1fM", rate/1e6)
+	case rate >= 1e3:
+		return fmt.Sprintf("%.1fk", rate/1e3)
+	default:
+		return fmt.Sprintf("%.0f", rate)
+	}
+}
@@ -0,0 +1,105 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+// SameServer reports whether two PostgreSQL configs point at the same
+// cluster, judged by host and port as the client sees them.
+func SameServer(source, target *config.Config) bool {
+	if source.Database.Type != "postgres" || target.Database.Type != "postgres" {
+		return false
+	}
+	return config.NormalizeHost(source.Database.Host) == config.NormalizeHost(target.Database.Host) &&
+		postgresPort(source) == postgresPort(target)
+}
+
+func postgresPort(cfg *config.Config) int {
+	if cfg.Database.Port == 0 {
+		return 5432
+	}
+	return cfg.Database.Port
+}
+
+// ServerSideCopyQueries builds the statements that copy a table without the
+// rows leaving the server: sourceQuery runs in the source database through
+// dblink and returns each row as JSON, and insertQuery, run on the target
+// with the source connection string as $1 and sourceQuery as $2, rebuilds
+// the rows with the target table's own column types.
+func ServerSideCopyQueries(table schema.Table) (sourceQuery, insertQuery string) {
+	columns := make([]string, len(table.Columns))
+	projected := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = schema.QuoteIdentifier(col.Name)
+		projected[i] = "r." + columns[i]
+	}
+	qualified := schema.QuoteIdentifier(table.Schema) + "." + schema.QuoteIdentifier(table.Name)
+
+	sourceQuery = fmt.Sprintf(`SELECT row_to_json(t) FROM (SELECT %s FROM %s) t`, strings.Join(columns, ", "), qualified)
+	insertQuery = fmt.Sprintf(
+		`INSERT INTO %s (%s) SELECT %s FROM dblink($1, $2) AS source(doc json), LATERAL json_populate_record(NULL::%s, source.doc) AS r ON CONFLICT DO NOTHING`,
+		qualified,
+		strings.Join(columns, ", "),
+		strings.Join(projected, ", "),
+		qualified,
+	)
+	return sourceQuery, insertQuery
+}
+
+const dblinkInstalledQuery = `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'dblink')`
+
+// useServerSideCopy decides once per run whether --same-server-optimize can
+// apply, logging why not when it cannot.
+func (e *postgresEngine) useServerSideCopy() bool {
+	if !e.options.SameServerOptimize {
+		return false
+	}
+
+	reason := ""
+	switch {
+	case !SameServer(e.sourceConfig, e.targetConfig):
+		reason = "source and target are on different servers"
+	case e.options.ConsistentSnapshot:
+		reason = "dblink cannot read from the exported snapshot"
+	case e.options.Throttle != nil:
+		reason = "rate limits cannot be applied to a server-side copy"
+	case schema.FoldsIdentifiers(e.options.IdentifierCase):
+		reason = "identifier case folding renames the target columns"
+	case len(e.options.SchemaMap) > 0:
+		reason = "schemas are mapped to other names on the target"
+	}
+	if reason == "" {
+		var installed bool
+		if err := e.targetConn.DB.QueryRow(dblinkInstalledQuery).Scan(&installed); err != nil {
+			reason = fmt.Sprintf("could not check for dblink: %v", err)
+		} else if !installed {
+			reason = "the dblink extension is not installed in the target database (CREATE EXTENSION dblink)"
+		}
+	}
+
+	if reason != "" {
+		e.options.Logger.Infof("Not copying server-side: %s; using the regular copy.", reason)
+		return false
+	}
+	e.options.Logger.Info("Source and target share a server; copying tables server-side through dblink.")
+	return true
+}
+
+// copyServerSide copies a table in one INSERT ... SELECT on the target and
+// returns the rows inserted. It is a single statement, so a failure leaves
+// the table as it was and the regular copy can take over.
+func (e *postgresEngine) copyServerSide(table schema.Table) (int64, error) {
+	sourceQuery, insertQuery := ServerSideCopyQueries(table)
+
+	// The connection string carries the password, so it is passed as a
+	// parameter rather than appearing in the statement text.
+	result, err := e.targetConn.DB.Exec(insertQuery, e.sourceConfig.GetConnectionString(), sourceQuery)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,35 @@
+package transfer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+// ParseSchemaMapFlags parses "source:target" --map-schema values into a map
+// from source schema to target schema.
+func ParseSchemaMapFlags(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]string, len(values))
+	for _, value := range values {
+		source, target, ok := strings.Cut(value, ":")
+		if !ok || source == "" || target == "" {
+			return nil, fmt.Errorf("invalid schema mapping %q (expected source:target)", value)
+		}
+		if existing, ok := parsed[source]; ok && existing != target {
+			return nil, fmt.Errorf("schema %s is mapped to both %s and %s", source, existing, target)
+		}
+		parsed[source] = target
+	}
+	return parsed, nil
+}
+
+// onTarget returns table as it is named on the target, in its mapped schema.
+func (e *postgresEngine) onTarget(table schema.Table) schema.Table {
+	table.Schema = schema.MapSchema(table.Schema, e.options.SchemaMap)
+	return table
+}
@@ -2,8 +2,11 @@ package transfer
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/concurrency"
 	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
 )
 
@@ -12,11 +15,61 @@ type Options struct {
 	DataOnly        bool
 	ParallelWorkers int
 	BatchSize       int
+	SplitThreshold  int64
+	IdentifierCase  string
+	DisableTriggers bool
+	PreserveStorage bool
+	VerifyChecksums bool
+	Append          bool
+	SchemaDiff      bool
+	ViaDump         bool
+	DumpCompression int
+	MaxConcurrency  int
+	Transforms      map[string]string
+	// MongoTransforms maps MongoDB field paths to remove or a transform name.
+	MongoTransforms map[string]string
+	ExcludeColumns  []string
+	Limiter         *concurrency.Limiter
 	Logger          *logger.Logger
+
+	// ConsistentSnapshot makes every worker read from one exported source
+	// snapshot, so parallel tables are copied as of the same point in time.
+	ConsistentSnapshot bool
+	// RegenerateIDs drops _id from MongoDB documents before inserting them,
+	// so the target assigns new ones.
+	RegenerateIDs bool
+	// RateLimitRows and RateLimitMB cap the copy's combined throughput in
+	// rows and megabytes per second. Zero means unlimited.
+	RateLimitRows int
+	RateLimitMB   float64
+	Throttle      *concurrency.Throttle
+	// SameServerOptimize copies PostgreSQL tables with a server-side
+	// INSERT ... SELECT through dblink when source and target share a server.
+	SameServerOptimize bool
+	// UnkeyedStrategy is how PostgreSQL tables without a primary key or
+	// usable unique index are read: UnkeyedOffset (default) or UnkeyedFullRead.
+	UnkeyedStrategy string
+	// ListenChannel relays NOTIFY messages sent on this channel on the source
+	// or target to the log while a PostgreSQL transfer runs.
+	ListenChannel string
+	// UseCopy loads PostgreSQL batches with COPY FROM STDIN instead of
+	// INSERT ... ON CONFLICT DO NOTHING, so a row that conflicts with one
+	// already on the target fails its whole batch.
+	UseCopy bool
+	// Query, with TargetTable, copies the result of one PostgreSQL query
+	// into a table instead of copying the source's tables.
+	Query       string
+	TargetTable string
+	// SchemaMap maps PostgreSQL source schemas to the target schemas their
+	// objects are created and copied into.
+	SchemaMap map[string]string
+	// RefreshMatViews refreshes the target's materialized views after the
+	// data is copied. Otherwise they stay empty until refreshed by hand.
+	RefreshMatViews bool
 }
 
 type Engine interface {
-	Execute() error
+	Execute() (*TransferReport, error)
 }
 
 type Service struct {
@@ -31,6 +84,99 @@ func NewService(sourceConfig, targetConfig *config.Config, options Options) (*Se
 		return nil, fmt.Errorf("cross-engine transfers are not supported between %s and %s", sourceType, targetType)
 	}
 
+	if err := schema.ValidateIdentifierCase(options.IdentifierCase); err != nil {
+		return nil, err
+	}
+	if err := ValidateUnkeyedStrategy(options.UnkeyedStrategy); err != nil {
+		return nil, err
+	}
+
+	if len(options.Transforms) > 0 && sourceType != "postgres" {
+		return nil, fmt.Errorf("column transforms are only supported for PostgreSQL transfers")
+	}
+
+	if len(options.MongoTransforms) > 0 && sourceType != "mongo" {
+		return nil, fmt.Errorf("--mongo-transform is only supported for MongoDB transfers")
+	}
+
+	if len(options.ExcludeColumns) > 0 && sourceType != "postgres" {
+		return nil, fmt.Errorf("--exclude-column is only supported for PostgreSQL transfers")
+	}
+	for _, column := range options.ExcludeColumns {
+		if _, ok := options.Transforms[column]; ok {
+			return nil, fmt.Errorf("%s is both excluded and transformed", column)
+		}
+	}
+
+	if len(options.SchemaMap) > 0 && sourceType != "postgres" {
+		return nil, fmt.Errorf("--map-schema is only supported for PostgreSQL transfers")
+	}
+	if len(options.SchemaMap) > 0 && options.SchemaDiff {
+		return nil, fmt.Errorf("--map-schema cannot be combined with --schema-diff")
+	}
+
+	if options.SchemaDiff && sourceType != "postgres" {
+		return nil, fmt.Errorf("--schema-diff is only supported for PostgreSQL transfers")
+	}
+	if options.SchemaDiff && options.DataOnly {
+		return nil, fmt.Errorf("--schema-diff cannot be combined with --data-only")
+	}
+
+	if options.RegenerateIDs && sourceType != "mongo" {
+		return nil, fmt.Errorf("--regenerate-ids is only supported for MongoDB transfers")
+	}
+
+	if options.ListenChannel != "" && sourceType != "postgres" {
+		return nil, fmt.Errorf("--listen is only supported for PostgreSQL transfers")
+	}
+
+	if options.RefreshMatViews && sourceType != "postgres" {
+		return nil, fmt.Errorf("--refresh-matviews is only supported for PostgreSQL transfers")
+	}
+	// Views are not created when either renames objects on the target.
+	if options.RefreshMatViews && len(options.SchemaMap) > 0 {
+		return nil, fmt.Errorf("--refresh-matviews cannot be combined with --map-schema")
+	}
+	if options.RefreshMatViews && schema.FoldsIdentifiers(options.IdentifierCase) {
+		return nil, fmt.Errorf("--refresh-matviews cannot be combined with --identifier-case")
+	}
+
+	if options.UseCopy && sourceType != "postgres" {
+		return nil, fmt.Errorf("--copy is only supported for PostgreSQL transfers")
+	}
+
+	if options.SameServerOptimize && sourceType != "postgres" {
+		return nil, fmt.Errorf("--same-server-optimize is only supported for PostgreSQL transfers")
+	}
+
+	if options.ConsistentSnapshot && sourceType != "postgres" {
+		return nil, fmt.Errorf("--consistent-snapshot is only supported for PostgreSQL transfers")
+	}
+
+	if options.RateLimitRows < 0 || options.RateLimitMB < 0 {
+		return nil, fmt.Errorf("rate limits cannot be negative")
+	}
+
+	if options.Query != "" || options.TargetTable != "" {
+		if err := validateQueryTransfer(sourceType, options); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.ViaDump {
+		if err := validateViaDump(sourceType, options); err != nil {
+			return nil, err
+		}
+		return &Service{engine: newDumpEngine(sourceConfig, targetConfig, options)}, nil
+	}
+
+	if options.Limiter == nil {
+		options.Limiter = concurrency.NewLimiter(options.MaxConcurrency)
+	}
+	if options.Throttle == nil {
+		options.Throttle = concurrency.NewThrottle(options.RateLimitRows, options.RateLimitMB)
+	}
+
 	var engine Engine
 	switch sourceType {
 	case "postgres":
@@ -48,6 +194,106 @@ func NewService(sourceConfig, targetConfig *config.Config, options Options) (*Se
 	return &Service{engine: engine}, nil
 }
 
-func (s *Service) Execute() error {
+// validateViaDump rejects options the pg_dump | pg_restore pipeline cannot
+// honour, rather than silently ignoring them.
+func validateViaDump(sourceType string, options Options) error {
+	if sourceType != "postgres" {
+		return fmt.Errorf("--via-dump is only supported for PostgreSQL transfers")
+	}
+
+	var unsupported []string
+	if len(options.Transforms) > 0 {
+		unsupported = append(unsupported, "--transform")
+	}
+	if len(options.ExcludeColumns) > 0 {
+		unsupported = append(unsupported, "--exclude-column")
+	}
+	if options.SchemaDiff {
+		unsupported = append(unsupported, "--schema-diff")
+	}
+	if options.SplitThreshold > 0 {
+		unsupported = append(unsupported, "--split-threshold")
+	}
+	if options.VerifyChecksums {
+		unsupported = append(unsupported, "--verify-checksums")
+	}
+	if options.PreserveStorage {
+		unsupported = append(unsupported, "--preserve-storage")
+	}
+	if options.RateLimitRows > 0 || options.RateLimitMB > 0 {
+		unsupported = append(unsupported, "--rate-limit-rows/--rate-limit-mb")
+	}
+	if options.SameServerOptimize {
+		unsupported = append(unsupported, "--same-server-optimize")
+	}
+	if options.UseCopy {
+		unsupported = append(unsupported, "--copy")
+	}
+	if len(options.SchemaMap) > 0 {
+		unsupported = append(unsupported, "--map-schema")
+	}
+	if options.RefreshMatViews {
+		unsupported = append(unsupported, "--refresh-matviews")
+	}
+	if schema.FoldsIdentifiers(options.IdentifierCase) {
+		unsupported = append(unsupported, "--identifier-case")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("--via-dump cannot be combined with %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+func validateQueryTransfer(sourceType string, options Options) error {
+	if sourceType != "postgres" {
+		return fmt.Errorf("--query is only supported for PostgreSQL transfers")
+	}
+	if options.Query == "" || options.TargetTable == "" {
+		return fmt.Errorf("--query and --target-table must be given together")
+	}
+
+	var unsupported []string
+	if options.SchemaOnly || options.DataOnly {
+		unsupported = append(unsupported, "--schema-only/--data-only")
+	}
+	if options.ViaDump {
+		unsupported = append(unsupported, "--via-dump")
+	}
+	if len(options.Transforms) > 0 {
+		unsupported = append(unsupported, "--transform")
+	}
+	if len(options.ExcludeColumns) > 0 {
+		unsupported = append(unsupported, "--exclude-column")
+	}
+	if options.SchemaDiff {
+		unsupported = append(unsupported, "--schema-diff")
+	}
+	if options.SplitThreshold > 0 {
+		unsupported = append(unsupported, "--split-threshold")
+	}
+	if options.VerifyChecksums {
+		unsupported = append(unsupported, "--verify-checksums")
+	}
+	if options.ConsistentSnapshot {
+		unsupported = append(unsupported, "--consistent-snapshot")
+	}
+	if options.SameServerOptimize {
+		unsupported = append(unsupported, "--same-server-optimize")
+	}
+	if len(options.SchemaMap) > 0 {
+		unsupported = append(unsupported, "--map-schema")
+	}
+	if options.RefreshMatViews {
+		unsupported = append(unsupported, "--refresh-matviews")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("--query cannot be combined with %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// Execute runs the transfer. The report is returned even when the transfer
+// fails part-way, covering every table reached before the failure.
+func (s *Service) Execute() (*TransferReport, error) {
 	return s.engine.Execute()
 }
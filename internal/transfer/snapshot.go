@@ -0,0 +1,99 @@
+package transfer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+// ExportSnapshotQuery exports the snapshot of the current transaction so
+// other sessions can read the same point in time.
+const ExportSnapshotQuery = "SELECT pg_export_snapshot()"
+
+// SnapshotTxOptions are the options of both the exporting transaction and
+// every worker transaction importing its snapshot. Importing requires
+// REPEATABLE READ or stricter.
+func SnapshotTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+}
+
+// SetTransactionSnapshotSQL imports an exported snapshot. It must be the
+// first statement of the worker's transaction.
+func SetTransactionSnapshotSQL(id string) string {
+	return "SET TRANSACTION SNAPSHOT '" + strings.ReplaceAll(id, "'", "''") + "'"
+}
+
+// sourceSnapshot holds the transaction that exported a snapshot. The snapshot
+// is only importable while this transaction stays open.
+type sourceSnapshot struct {
+	tx *sql.Tx
+	ID string
+}
+
+func exportSnapshot(db *sql.DB) (*sourceSnapshot, error) {
+	tx, err := db.BeginTx(context.Background(), SnapshotTxOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin snapshot transaction: %w", err)
+	}
+
+	var id string
+	if err := tx.QueryRow(ExportSnapshotQuery).Scan(&id); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to export snapshot: %w", err)
+	}
+	return &sourceSnapshot{tx: tx, ID: id}, nil
+}
+
+func (s *sourceSnapshot) Close() error {
+	return s.tx.Rollback()
+}
+
+// querySnapshot runs a read-only query inside a transaction that imports the
+// snapshot. Closing the returned function ends the transaction.
+func querySnapshot(db *sql.DB, snapshotID, query string, args ...interface{}) (*sql.Rows, func(), error) {
+	tx, err := db.BeginTx(context.Background(), SnapshotTxOptions())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin snapshot transaction: %w", err)
+	}
+	if _, err := tx.Exec(SetTransactionSnapshotSQL(snapshotID)); err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("failed to import snapshot %s: %w", snapshotID, err)
+	}
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	return rows, func() {
+		rows.Close()
+		tx.Rollback()
+	}, nil
+}
+
+// SnapshotRowCount counts a table's rows as the exported snapshot sees them.
+// The counts taken while listing tables read the live table, which can miss
+// rows deleted after the snapshot and end an offset-paged copy early.
+func SnapshotRowCount(db *sql.DB, snapshotID string, table schema.Table) (int64, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", schema.QuoteIdentifier(table.Schema), schema.QuoteIdentifier(table.Name))
+	rows, done, err := querySnapshot(db, snapshotID, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows of %s.%s: %w", table.Schema, table.Name, err)
+	}
+	defer done()
+
+	var count int64
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, sql.ErrNoRows
+	}
+	if err := rows.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,106 @@
+package transfer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+// RowRange is a half-open primary key interval [Start, End) handled by a single job.
+type RowRange struct {
+	Start int64
+	End   int64
+	// ToMax makes the range cover every key from Start up. It is set on the
+	// last range of an interval ending at math.MaxInt64, whose exclusive end
+	// cannot be represented; End is then math.MaxInt64 and included.
+	ToMax bool
+}
+
+func (r RowRange) String() string {
+	if r.ToMax {
+		return fmt.Sprintf("[%d, %d]", r.Start, r.End)
+	}
+	return fmt.Sprintf("[%d, %d)", r.Start, r.End)
+}
+
+var splittableKeyTypes = map[string]bool{
+	"smallint": true,
+	"integer":  true,
+	"bigint":   true,
+}
+
+// SplitRange divides the inclusive key interval [min, max] into at most parts
+// contiguous ranges of roughly equal width. Widths are computed in uint64,
+// since an interval spanning most of the int64 keys overflows int64.
+func SplitRange(min, max int64, parts int) []RowRange {
+	if max < min {
+		return nil
+	}
+	if parts < 1 {
+		parts = 1
+	}
+
+	// The span is lastOffset+1 keys, which for the full int64 range is one
+	// more than a uint64 holds.
+	lastOffset := uint64(max) - uint64(min)
+	if uint64(parts)-1 > lastOffset {
+		parts = int(lastOffset + 1)
+	}
+	// Each range holds ceil(span/parts) keys, which is lastOffset/parts+1.
+	widthOffset := lastOffset / uint64(parts)
+
+	ranges := make([]RowRange, 0, parts)
+	for start := min; ; {
+		if uint64(max)-uint64(start) <= widthOffset {
+			if max == math.MaxInt64 {
+				return append(ranges, RowRange{Start: start, End: max, ToMax: true})
+			}
+			return append(ranges, RowRange{Start: start, End: max + 1})
+		}
+		end := int64(uint64(start) + widthOffset + 1)
+		ranges = append(ranges, RowRange{Start: start, End: end})
+		start = end
+	}
+}
+
+// SplittablePrimaryKey returns the primary key column used to range-partition
+// a table, or false when the table has to be copied by a single worker.
+func SplittablePrimaryKey(table schema.Table) (string, bool) {
+	if len(table.PrimaryKeys) != 1 {
+		return "", false
+	}
+
+	for _, col := range table.Columns {
+		if col.Name == table.PrimaryKeys[0] {
+			return col.Name, splittableKeyTypes[col.DataType]
+		}
+	}
+
+	return "", false
+}
+
+// PartitionByRows separates tables that have rows to copy from empty ones
+// and totals the rows to copy, so an empty source never starts a transfer.
+func PartitionByRows(tables []schema.Table) (pending, empty []schema.Table, totalRows int64) {
+	for _, table := range tables {
+		if table.RowCount == 0 {
+			empty = append(empty, table)
+			continue
+		}
+		pending = append(pending, table)
+		totalRows += table.RowCount
+	}
+	return pending, empty, totalRows
+}
+
+// ShouldSplitTable reports whether a table is large enough, and keyed suitably,
+// to be transferred as several concurrent ranges.
+func ShouldSplitTable(table schema.Table, threshold int64) bool {
+	if threshold <= 0 || table.RowCount <= threshold {
+		return false
+	}
+
+	_, ok := SplittablePrimaryKey(table)
+	return ok
+}
@@ -0,0 +1,212 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+// TransformFunc rewrites a single column value before it is written to the target.
+type TransformFunc func(value interface{}) interface{}
+
+// TransformFactory builds a TransformFunc from the optional argument in a
+// transform spec such as "const=0".
+type TransformFactory func(arg string) (TransformFunc, error)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]TransformFactory{
+		"mask":    noArg("mask", MaskValue),
+		"hash":    noArg("hash", HashValue),
+		"nullify": noArg("nullify", func(interface{}) interface{} { return nil }),
+		"const":   constTransform,
+	}
+)
+
+// RegisterTransform makes a named transform available to Options.Transforms
+// and --transform. Registering an existing name replaces it.
+func RegisterTransform(name string, factory TransformFactory) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = factory
+}
+
+// TransformNames lists the registered transforms in sorted order.
+func TransformNames() []string {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTransform resolves a spec of the form "name" or "name=arg".
+func NewTransform(spec string) (TransformFunc, error) {
+	name, arg, _ := strings.Cut(spec, "=")
+
+	transformsMu.RLock()
+	factory, ok := transforms[name]
+	transformsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(TransformNames(), ", "))
+	}
+	return factory(arg)
+}
+
+// ParseTransformFlags turns "schema.table.column:spec" flag values into the
+// map stored in Options.Transforms.
+func ParseTransformFlags(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]string, len(values))
+	for _, value := range values {
+		column, spec, ok := strings.Cut(value, ":")
+		if !ok || spec == "" || strings.Count(column, ".") != 2 {
+			return nil, fmt.Errorf("invalid transform %q (expected schema.table.column:transform)", value)
+		}
+		if _, err := NewTransform(spec); err != nil {
+			return nil, err
+		}
+		parsed[column] = spec
+	}
+	return parsed, nil
+}
+
+// ColumnTransforms returns one TransformFunc per table column, nil where the
+// column has no transform configured. mask and hash produce text, so they are
+// rejected on columns of other types, where the insert would fail.
+func ColumnTransforms(table schema.Table, specs map[string]string) ([]TransformFunc, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	var funcs []TransformFunc
+	for i, col := range table.Columns {
+		spec, ok := specs[fmt.Sprintf("%s.%s.%s", table.Schema, table.Name, col.Name)]
+		if !ok {
+			continue
+		}
+
+		fn, err := NewTransform(spec)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTextTransform(table, col, spec); err != nil {
+			return nil, err
+		}
+		if funcs == nil {
+			funcs = make([]TransformFunc, len(table.Columns))
+		}
+		funcs[i] = fn
+	}
+	return funcs, nil
+}
+
+// ApplyTransforms rewrites values in place using the column-aligned funcs.
+func ApplyTransforms(values []interface{}, funcs []TransformFunc) {
+	for i, fn := range funcs {
+		if fn != nil && i < len(values) {
+			values[i] = fn(values[i])
+		}
+	}
+}
+
+// MaskValue keeps the first character of a value and replaces the rest with
+// '*'. For email addresses the domain is kept so the value stays recognisable.
+func MaskValue(value interface{}) interface{} {
+	text, ok := textValue(value)
+	if !ok {
+		return value
+	}
+
+	local, domain, isEmail := strings.Cut(text, "@")
+	masked := maskRunes(local)
+	if isEmail {
+		masked += "@" + domain
+	}
+	return masked
+}
+
+// HashValue replaces a value with the hex SHA-256 of its text form, which
+// keeps equal inputs equal so joins on the column still line up.
+func HashValue(value interface{}) interface{} {
+	text, ok := textValue(value)
+	if !ok {
+		return value
+	}
+
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// textTypes are the column types mask and hash can write their text into.
+var textTypes = map[string]bool{
+	"text":              true,
+	"character varying": true,
+	"varchar":           true,
+	"character":         true,
+	"char":              true,
+	"bpchar":            true,
+	"citext":            true,
+}
+
+func checkTextTransform(table schema.Table, col schema.Column, spec string) error {
+	name, _, _ := strings.Cut(spec, "=")
+	if name != "mask" && name != "hash" {
+		return nil
+	}
+
+	column := fmt.Sprintf("%s.%s.%s", table.Schema, table.Name, col.Name)
+	if !textTypes[col.DataType] {
+		return fmt.Errorf("transform %q on %s needs a text column, not %s", name, column, col.DataType)
+	}
+	if name == "hash" && col.MaxLength != nil && *col.MaxLength < sha256.Size*2 {
+		return fmt.Errorf("transform \"hash\" on %s needs room for %d characters, but the column holds %d", column, sha256.Size*2, *col.MaxLength)
+	}
+	return nil
+}
+
+func constTransform(arg string) (TransformFunc, error) {
+	return func(interface{}) interface{} { return arg }, nil
+}
+
+func noArg(name string, fn TransformFunc) TransformFactory {
+	return func(arg string) (TransformFunc, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("transform %q does not take an argument", name)
+		}
+		return fn, nil
+	}
+}
+
+func textValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+func maskRunes(text string) string {
+	runes := []rune(text)
+	for i := 1; i < len(runes); i++ {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
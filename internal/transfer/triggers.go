@@ -0,0 +1,42 @@
+package transfer
+
+import (
+	"fmt"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+)
+
+// TriggerStatements returns the statements that disable and re-enable all
+// triggers on the target copy of a table.
+func TriggerStatements(table schema.Table, identifierCase string) (string, string) {
+	target := fmt.Sprintf("%s.%s",
+		schema.QuoteIdentifier(table.Schema),
+		schema.QuoteIdentifier(schema.FoldIdentifier(table.Name, identifierCase)),
+	)
+	return fmt.Sprintf("ALTER TABLE %s DISABLE TRIGGER ALL", target),
+		fmt.Sprintf("ALTER TABLE %s ENABLE TRIGGER ALL", target)
+}
+
+// WithTriggersDisabled runs load with the table's triggers disabled on the
+// target. When triggers cannot be disabled (usually because the user does not
+// own the table) a warning is logged and the load runs with triggers active.
+func WithTriggersDisabled(exec func(query string) error, log *logger.Logger, table schema.Table, identifierCase string, load func() error) error {
+	disable, enable := TriggerStatements(table, identifierCase)
+
+	if err := exec(disable); err != nil {
+		log.Warnf("Could not disable triggers on %s.%s (table owner privileges required): %v", table.Schema, table.Name, err)
+		return load()
+	}
+
+	loadErr := load()
+
+	if err := exec(enable); err != nil {
+		if loadErr != nil {
+			return fmt.Errorf("%w (re-enabling triggers also failed: %v)", loadErr, err)
+		}
+		return fmt.Errorf("failed to re-enable triggers on %s.%s: %w", table.Schema, table.Name, err)
+	}
+
+	return loadErr
+}
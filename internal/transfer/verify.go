@@ -0,0 +1,238 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+)
+
+// RowHasher folds rows into a single order-sensitive digest without holding
+// them in memory. Each value is length-prefixed so adjacent values cannot run
+// together, and NULL is distinct from an empty string.
+type RowHasher struct {
+	digest hash.Hash
+	rows   int64
+}
+
+func NewRowHasher() *RowHasher {
+	return &RowHasher{digest: sha256.New()}
+}
+
+func (h *RowHasher) Add(values []interface{}) {
+	row := sha256.New()
+	var length [8]byte
+	for _, value := range values {
+		if value == nil {
+			row.Write([]byte{0})
+			continue
+		}
+
+		text := canonicalValue(value)
+		binary.BigEndian.PutUint64(length[:], uint64(len(text)))
+		row.Write([]byte{1})
+		row.Write(length[:])
+		row.Write([]byte(text))
+	}
+
+	h.digest.Write(row.Sum(nil))
+	h.rows++
+}
+
+func (h *RowHasher) Rows() int64 {
+	return h.rows
+}
+
+func (h *RowHasher) Sum() string {
+	return hex.EncodeToString(h.digest.Sum(nil))
+}
+
+func canonicalValue(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type ChecksumResult struct {
+	Schema     string
+	Table      string
+	SourceRows int64
+	TargetRows int64
+	SourceSum  string
+	TargetSum  string
+}
+
+func (r ChecksumResult) Match() bool {
+	return r.SourceRows == r.TargetRows && r.SourceSum == r.TargetSum
+}
+
+// BuildChecksumQuery selects every column ordered by the primary key, using the
+// target's folded identifiers when identifierCase is set.
+func BuildChecksumQuery(table schema.Table, identifierCase string) string {
+	ident := func(name string) string {
+		return schema.QuoteIdentifier(schema.FoldIdentifier(name, identifierCase))
+	}
+
+	columns := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = ident(col.Name)
+	}
+	keys := make([]string, len(table.PrimaryKeys))
+	for i, pk := range table.PrimaryKeys {
+		keys[i] = ident(pk)
+	}
+
+	return fmt.Sprintf(`SELECT %s FROM %s.%s ORDER BY %s`,
+		strings.Join(columns, ", "),
+		schema.QuoteIdentifier(table.Schema),
+		ident(table.Name),
+		strings.Join(keys, ", "),
+	)
+}
+
+// WithoutTransformedColumns leaves the columns --transform rewrites out of a
+// table's checksum, since their target values differ from the source's by
+// design. It returns the names left out, and false when nothing meaningful
+// is left to compare: every column is transformed, or a primary key column
+// is, which reorders the target's rows.
+func WithoutTransformedColumns(table schema.Table, specs map[string]string) (schema.Table, []string, bool) {
+	isTransformed := func(column string) bool {
+		_, ok := specs[table.Schema+"."+table.Name+"."+column]
+		return ok
+	}
+
+	for _, pk := range table.PrimaryKeys {
+		if isTransformed(pk) {
+			return table, []string{pk}, false
+		}
+	}
+
+	var skipped []string
+	columns := make([]schema.Column, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		if isTransformed(col.Name) {
+			skipped = append(skipped, col.Name)
+			continue
+		}
+		columns = append(columns, col)
+	}
+	table.Columns = columns
+	return table, skipped, len(columns) > 0
+}
+
+// TableChecksum streams every row of the table through a RowHasher.
+func TableChecksum(conn *database.Connection, query string) (string, int64, error) {
+	rows, err := conn.DB.Query(query)
+	if err != nil {
+		return "", 0, err
+	}
+	defer rows.Close()
+	return checksumRows(rows)
+}
+
+// SnapshotTableChecksum is TableChecksum read from an exported snapshot, so
+// a --consistent-snapshot copy is compared with the rows it actually read
+// rather than with the live table.
+func SnapshotTableChecksum(db *sql.DB, snapshotID, query string) (string, int64, error) {
+	rows, done, err := querySnapshot(db, snapshotID, query)
+	if err != nil {
+		return "", 0, err
+	}
+	defer done()
+	return checksumRows(rows)
+}
+
+func checksumRows(rows *sql.Rows) (string, int64, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", 0, err
+	}
+
+	hasher := NewRowHasher()
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return "", 0, err
+		}
+		hasher.Add(values)
+	}
+	if err := rows.Err(); err != nil {
+		return "", 0, err
+	}
+
+	return hasher.Sum(), hasher.Rows(), nil
+}
+
+func (e *postgresEngine) verifyChecksums(tables []schema.Table) error {
+	if len(tables) == 0 {
+		e.options.Logger.Info("No tables to verify.")
+		return nil
+	}
+
+	e.options.Logger.Info("Verifying table checksums...")
+
+	mismatches := 0
+	for _, table := range tables {
+		if len(table.PrimaryKeys) == 0 {
+			e.options.Logger.Warnf("Checksum skipped for %s.%s: no primary key to order rows by", table.Schema, table.Name)
+			continue
+		}
+
+		table, skipped, ok := WithoutTransformedColumns(table, e.options.Transforms)
+		if !ok {
+			e.options.Logger.Warnf("Checksum skipped for %s.%s: its transformed key or columns (%s) cannot be compared",
+				table.Schema, table.Name, strings.Join(skipped, ", "))
+			continue
+		}
+		if len(skipped) > 0 {
+			e.options.Logger.Infof("Checksum of %s.%s leaves out transformed column(s) %s", table.Schema, table.Name, strings.Join(skipped, ", "))
+		}
+
+		result := ChecksumResult{Schema: table.Schema, Table: table.Name}
+
+		var err error
+		if e.snapshotID != "" {
+			result.SourceSum, result.SourceRows, err = SnapshotTableChecksum(e.sourceConn.DB, e.snapshotID, BuildChecksumQuery(table, ""))
+		} else {
+			result.SourceSum, result.SourceRows, err = TableChecksum(e.sourceConn, BuildChecksumQuery(table, ""))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to checksum source table %s.%s: %w", table.Schema, table.Name, err)
+		}
+		result.TargetSum, result.TargetRows, err = TableChecksum(e.targetConn, BuildChecksumQuery(e.onTarget(table), e.options.IdentifierCase))
+		if err != nil {
+			return fmt.Errorf("failed to checksum target table %s.%s: %w", table.Schema, table.Name, err)
+		}
+
+		if result.Match() {
+			e.options.Logger.Infof("Checksum OK for %s.%s (%d rows)", table.Schema, table.Name, result.SourceRows)
+			continue
+		}
+
+		mismatches++
+		e.options.Logger.Errorf("Checksum MISMATCH for %s.%s: source %d rows %s, target %d rows %s",
+			table.Schema, table.Name, result.SourceRows, result.SourceSum[:12], result.TargetRows, result.TargetSum[:12])
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("checksum verification failed for %d table(s)", mismatches)
+	}
+	return nil
+}
@@ -2,36 +2,112 @@ package transfer
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/database"
 	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/concurrency"
 	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
 	"github.com/kadirbelkuyu/DBRTS/pkg/progress"
+
+	"github.com/lib/pq"
 )
 
+// WorkerPool runs queued jobs on a fixed number of goroutines. Call Start,
+// submit the jobs, then Wait.
 type WorkerPool struct {
 	workers   int
 	batchSize int
 	jobs      chan Job
+
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	firstErr error
 }
 
 type Job interface {
 	Execute() error
 }
 
+// JobFunc runs a plain function as a Job.
+type JobFunc func() error
+
+func (f JobFunc) Execute() error {
+	return f()
+}
+
 type DataTransferJob struct {
-	Table       schema.Table
-	SourceConn  *database.Connection
-	TargetConn  *database.Connection
-	BatchSize   int
-	ProgressBar *progress.Bar
-	Logger      *logger.Logger
+	Table          schema.Table
+	SourceConn     *database.Connection
+	TargetConn     *database.Connection
+	BatchSize      int
+	ProgressBar    *progress.TableBar
+	Logger         *logger.Logger
+	Range          *RowRange
+	RangeKey       string
+	IdentifierCase string
+	Transforms     []TransformFunc
+	// Snapshot, when set, is an exported source snapshot every batch reads from.
+	Snapshot string
+	// Throttle, when set, paces every row written against the run's rate limit.
+	Throttle *concurrency.Throttle
+	// FullRead reads the whole table in one query instead of OFFSET/LIMIT
+	// pages, for tables without a stable order key.
+	FullRead bool
+	// UseCopy loads each batch with COPY FROM STDIN instead of INSERT.
+	UseCopy bool
+	// Query, when set, is read in full instead of the table, whose columns
+	// must match the query's.
+	Query string
+	// SchemaMap names the target schema rows are written to when it differs
+	// from the source's.
+	SchemaMap map[string]string
+
+	rowsRead     int64
+	rowsWritten  int64
+	bytesWritten int64
+
+	// keysetKey names the column whose last copied value insertRows keeps
+	// in lastKey, for keyset pagination.
+	keysetKey string
+	lastKey   interface{}
+}
+
+// keysetKeyTypes are the primary key types batches can be paged by with
+// WHERE key > last rather than OFFSET.
+var keysetKeyTypes = map[string]bool{
+	"smallint": true,
+	"integer":  true,
+	"bigint":   true,
+	"uuid":     true,
+}
+
+// KeysetKey returns the single-column primary key a table is paged by with
+// keyset pagination, or false when batches fall back to OFFSET/LIMIT.
+func KeysetKey(table schema.Table) (string, bool) {
+	if len(table.PrimaryKeys) != 1 {
+		return "", false
+	}
+
+	for _, col := range table.Columns {
+		if col.Name == table.PrimaryKeys[0] {
+			return col.Name, keysetKeyTypes[col.DataType]
+		}
+	}
+
+	return "", false
 }
 
+// NewWorkerPool creates a pool of workers goroutines; fewer than one runs
+// the jobs one at a time.
 func NewWorkerPool(workers, batchSize int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
 	return &WorkerPool{
 		workers:   workers,
 		batchSize: batchSize,
@@ -39,17 +115,87 @@ func NewWorkerPool(workers, batchSize int) *WorkerPool {
 	}
 }
 
+// Start launches the workers. Each runs queued jobs until Wait closes the
+// queue. Jobs still queued once ctx is cancelled are dropped and fail with
+// its error.
+func (wp *WorkerPool) Start(ctx context.Context) {
+	for i := 0; i < wp.workers; i++ {
+		wp.wg.Add(1)
+		go func() {
+			defer wp.wg.Done()
+			for job := range wp.jobs {
+				if err := ctx.Err(); err != nil {
+					wp.fail(err)
+					continue
+				}
+				if err := job.Execute(); err != nil {
+					wp.fail(err)
+				}
+			}
+		}()
+	}
+}
+
+// SubmitJob queues a job for the workers, blocking while the queue is full.
+// It does not wait for the job to run.
 func (wp *WorkerPool) SubmitJob(ctx context.Context, job Job) error {
 	select {
 	case wp.jobs <- job:
-		return job.Execute()
+		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 }
 
+// Wait closes the queue, waits for every queued job to finish and returns
+// the first error a job returned. No job can be submitted afterwards.
+func (wp *WorkerPool) Wait() error {
+	close(wp.jobs)
+	wp.wg.Wait()
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return wp.firstErr
+}
+
+// RunTableJobs runs run for every table on a pool of workers. A failed table
+// does not stop the others; once all of them have finished, the first error
+// is returned.
+func RunTableJobs(ctx context.Context, workers, batchSize int, tables []schema.Table, run func(schema.Table) error) error {
+	pool := NewWorkerPool(workers, batchSize)
+	pool.Start(ctx)
+
+	for _, t := range tables {
+		table := t
+		if err := pool.SubmitJob(ctx, JobFunc(func() error { return run(table) })); err != nil {
+			pool.fail(err)
+			break
+		}
+	}
+	return pool.Wait()
+}
+
+func (wp *WorkerPool) fail(err error) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	if wp.firstErr == nil {
+		wp.firstErr = err
+	}
+}
+
 func (dt *DataTransferJob) Execute() error {
+	if dt.FullRead {
+		return dt.executeFullRead()
+	}
+	if key, ok := KeysetKey(dt.Table); ok {
+		return dt.executeKeyset(key)
+	}
+	if dt.Range != nil {
+		return dt.executeRange()
+	}
+
 	dt.Logger.Logger.Infof("Starting table transfer: %s.%s (%d rows)", dt.Table.Schema, dt.Table.Name, dt.Table.RowCount)
+	dt.ProgressBar.Start()
 
 	offset := int64(0)
 	batchSize := int64(dt.BatchSize)
@@ -60,7 +206,7 @@ func (dt *DataTransferJob) Execute() error {
 			limit = dt.Table.RowCount - offset
 		}
 
-		if err := dt.transferBatch(offset, limit); err != nil {
+		if _, err := dt.transferBatch(offset, limit); err != nil {
 			return fmt.Errorf("batch transfer failed: %w", err)
 		}
 
@@ -72,35 +218,156 @@ func (dt *DataTransferJob) Execute() error {
 	return nil
 }
 
-func (dt *DataTransferJob) transferBatch(offset, limit int64) error {
-	selectQuery := dt.buildSelectQuery(offset, limit)
+// executeKeyset reads each batch after the last key copied, so a batch deep
+// into a large table costs the same index scan as the first. A short batch
+// ends the table, or the job's range.
+func (dt *DataTransferJob) executeKeyset(key string) error {
+	if dt.Range != nil {
+		dt.Logger.Logger.Debugf("Starting range transfer: %s.%s %s", dt.Table.Schema, dt.Table.Name, dt.Range)
+	} else {
+		dt.Logger.Logger.Infof("Starting table transfer: %s.%s (%d rows)", dt.Table.Schema, dt.Table.Name, dt.Table.RowCount)
+	}
+	dt.ProgressBar.Start()
+
+	dt.keysetKey = key
+	dt.lastKey = nil
+	batchSize := int64(dt.BatchSize)
+
+	for {
+		var args []interface{}
+		if dt.lastKey != nil {
+			args = append(args, dt.lastKey)
+		}
+
+		rows, done, err := dt.querySource(dt.BuildKeysetQuery(key, dt.lastKey != nil, batchSize), args...)
+		if err != nil {
+			return fmt.Errorf("failed to query source data: %w", err)
+		}
+		transferred, err := dt.insertRows(rows, 0)
+		done()
+		if err != nil {
+			if dt.Range != nil {
+				return fmt.Errorf("batch transfer failed for range %s: %w", dt.Range, err)
+			}
+			return fmt.Errorf("batch transfer failed: %w", err)
+		}
+
+		dt.ProgressBar.IncrementBy(transferred)
+
+		if batchSize <= 0 || transferred < batchSize {
+			break
+		}
+	}
+
+	if dt.Range == nil {
+		dt.Logger.Logger.Infof("Table transfer completed: %s.%s", dt.Table.Schema, dt.Table.Name)
+	}
+	return nil
+}
+
+// executeRange pages through the job's key range until a short batch signals
+// that the range is exhausted, since per-range row counts are not known upfront.
+func (dt *DataTransferJob) executeRange() error {
+	dt.Logger.Logger.Debugf("Starting range transfer: %s.%s %s", dt.Table.Schema, dt.Table.Name, dt.Range)
+	dt.ProgressBar.Start()
+
+	offset := int64(0)
+	batchSize := int64(dt.BatchSize)
+
+	for {
+		transferred, err := dt.transferBatch(offset, batchSize)
+		if err != nil {
+			return fmt.Errorf("batch transfer failed for range %s: %w", dt.Range, err)
+		}
+
+		dt.ProgressBar.IncrementBy(transferred)
+		offset += transferred
 
-	rows, err := dt.SourceConn.DB.Query(selectQuery)
+		if transferred < batchSize {
+			return nil
+		}
+	}
+}
+
+// executeFullRead streams the table from a single query and commits every
+// BatchSize rows, so the copy does not depend on a stable row order.
+func (dt *DataTransferJob) executeFullRead() error {
+	if dt.Query != "" {
+		dt.Logger.Logger.Infof("Starting query transfer into %s.%s", dt.Table.Schema, dt.Table.Name)
+	} else {
+		dt.Logger.Logger.Infof("Starting full-read table transfer: %s.%s (%d rows)", dt.Table.Schema, dt.Table.Name, dt.Table.RowCount)
+	}
+	dt.ProgressBar.Start()
+
+	rows, done, err := dt.querySource(dt.BuildFullReadQuery())
 	if err != nil {
 		return fmt.Errorf("failed to query source data: %w", err)
 	}
-	defer rows.Close()
+	defer done()
+
+	batchSize := int64(dt.BatchSize)
+	for {
+		transferred, err := dt.insertRows(rows, batchSize)
+		if err != nil {
+			return fmt.Errorf("batch transfer failed: %w", err)
+		}
+		dt.ProgressBar.IncrementBy(transferred)
+
+		if batchSize <= 0 || transferred < batchSize {
+			break
+		}
+	}
+
+	dt.Logger.Logger.Infof("Table transfer completed: %s.%s", dt.Table.Schema, dt.Table.Name)
+	return nil
+}
+
+func (dt *DataTransferJob) transferBatch(offset, limit int64) (int64, error) {
+	selectQuery := dt.BuildSelectQuery(offset, limit)
+
+	rows, done, err := dt.querySource(selectQuery)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query source data: %w", err)
+	}
+	defer done()
 
-	insertQuery := dt.buildInsertQuery()
+	return dt.insertRows(rows, 0)
+}
+
+// insertRows copies up to limit rows (all remaining rows when limit is not
+// positive) from rows to the target in one transaction.
+func (dt *DataTransferJob) insertRows(rows *sql.Rows, limit int64) (int64, error) {
+	insertQuery := dt.BuildInsertQuery()
+	if dt.UseCopy {
+		insertQuery = dt.BuildCopyQuery()
+	}
 
 	tx, err := dt.TargetConn.DB.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(insertQuery)
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
 	columns, err := rows.Columns()
 	if err != nil {
-		return fmt.Errorf("failed to fetch column metadata: %w", err)
+		return 0, fmt.Errorf("failed to fetch column metadata: %w", err)
+	}
+
+	keyIndex := -1
+	for i, column := range columns {
+		if column == dt.keysetKey {
+			keyIndex = i
+		}
 	}
 
-	for rows.Next() {
+	var transferred, batchBytes int64
+	for (limit <= 0 || transferred < limit) && rows.Next() {
 		values := make([]interface{}, len(columns))
 		valuePtrs := make([]interface{}, len(columns))
 
@@ -109,63 +376,222 @@ func (dt *DataTransferJob) transferBatch(offset, limit int64) error {
 		}
 
 		if err := rows.Scan(valuePtrs...); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		dt.rowsRead++
+		// Kept before transforms run, since the next batch reads after it.
+		if keyIndex >= 0 {
+			dt.lastKey = keysetValue(values[keyIndex])
 		}
+		ApplyTransforms(values, dt.Transforms)
 
+		rowSize := approxRowSize(values)
+		if err := dt.Throttle.Wait(context.Background(), 1, rowSize); err != nil {
+			return 0, fmt.Errorf("rate limit wait failed: %w", err)
+		}
 		if _, err := stmt.Exec(values...); err != nil {
-			return fmt.Errorf("failed to insert row: %w", err)
+			return 0, fmt.Errorf("failed to insert row: %w", err)
+		}
+		transferred++
+		batchBytes += rowSize
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read source rows: %w", err)
+	}
+
+	// With COPY the rows above were only buffered; an Exec without
+	// arguments sends them and ends the COPY.
+	if dt.UseCopy {
+		if _, err := stmt.Exec(); err != nil {
+			return 0, fmt.Errorf("failed to copy rows: %w", err)
 		}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return nil
+	dt.rowsWritten += transferred
+	dt.bytesWritten += batchBytes
+	return transferred, nil
 }
 
-func (dt *DataTransferJob) buildSelectQuery(offset, limit int64) string {
-	columnNames := make([]string, len(dt.Table.Columns))
-	for i, col := range dt.Table.Columns {
-		columnNames[i] = fmt.Sprintf(`"%s"`, col.Name)
+func (dt *DataTransferJob) querySource(query string, args ...interface{}) (*sql.Rows, func(), error) {
+	if dt.Snapshot != "" {
+		return querySnapshot(dt.SourceConn.DB, dt.Snapshot, query, args...)
+	}
+
+	rows, err := dt.SourceConn.DB.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rows, func() { rows.Close() }, nil
+}
+
+// keysetValue turns a scanned key into a query argument. lib/pq scans uuid
+// as text bytes but would send bytes back as bytea.
+func keysetValue(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+// approxRowSize estimates a row's payload from its scanned values. Text and
+// binary count their length; other values count as 8 bytes.
+func approxRowSize(values []interface{}) int64 {
+	var size int64
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case []byte:
+			size += int64(len(v))
+		case string:
+			size += int64(len(v))
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+// Result reports the rows this job read from the source and committed to the
+// target. Rows read in a batch that was rolled back count as failed.
+func (dt *DataTransferJob) Result() TableResult {
+	return TableResult{
+		Schema:        dt.Table.Schema,
+		Table:         dt.Table.Name,
+		RowsAttempted: dt.rowsRead,
+		RowsSucceeded: dt.rowsWritten,
+		Bytes:         dt.bytesWritten,
+	}
+}
+
+// BuildSelectQuery renders the OFFSET/LIMIT SELECT used to read a batch of a
+// table that has no key to page by.
+func (dt *DataTransferJob) BuildSelectQuery(offset, limit int64) string {
+	whereClause := ""
+	if dt.Range != nil {
+		whereClause = " WHERE " + dt.rangeCondition()
 	}
 
 	return fmt.Sprintf(
-		`SELECT %s FROM "%s"."%s" ORDER BY %s OFFSET %d LIMIT %d`,
-		strings.Join(columnNames, ", "),
+		`SELECT %s FROM "%s"."%s"%s ORDER BY %s OFFSET %d LIMIT %d`,
+		dt.selectColumns(),
 		dt.Table.Schema,
 		dt.Table.Name,
+		whereClause,
 		dt.buildOrderByClause(),
 		offset,
 		limit,
 	)
 }
 
-func (dt *DataTransferJob) buildInsertQuery() string {
+// BuildKeysetQuery renders the SELECT that reads a batch by key. With
+// afterKey it reads the rows after the key passed as $1; without it, the
+// first batch of the table or range.
+func (dt *DataTransferJob) BuildKeysetQuery(key string, afterKey bool, limit int64) string {
+	var conditions []string
+	if dt.Range != nil {
+		conditions = append(conditions, dt.rangeCondition())
+	}
+	if afterKey {
+		conditions = append(conditions, fmt.Sprintf(`"%s" > $1`, key))
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return fmt.Sprintf(
+		`SELECT %s FROM "%s"."%s"%s ORDER BY "%s" LIMIT %d`,
+		dt.selectColumns(),
+		dt.Table.Schema,
+		dt.Table.Name,
+		whereClause,
+		key,
+		limit,
+	)
+}
+
+func (dt *DataTransferJob) selectColumns() string {
+	columnNames := make([]string, len(dt.Table.Columns))
+	for i, col := range dt.Table.Columns {
+		columnNames[i] = fmt.Sprintf(`"%s"`, col.Name)
+	}
+	return strings.Join(columnNames, ", ")
+}
+
+func (dt *DataTransferJob) rangeCondition() string {
+	if dt.Range.ToMax {
+		return fmt.Sprintf(`"%s" >= %d`, dt.RangeKey, dt.Range.Start)
+	}
+	return fmt.Sprintf(`"%s" >= %d AND "%s" < %d`, dt.RangeKey, dt.Range.Start, dt.RangeKey, dt.Range.End)
+}
+
+// BuildFullReadQuery renders the single unpaged SELECT of a full-read copy.
+func (dt *DataTransferJob) BuildFullReadQuery() string {
+	if dt.Query != "" {
+		return dt.Query
+	}
+
+	columnNames := make([]string, len(dt.Table.Columns))
+	for i, col := range dt.Table.Columns {
+		columnNames[i] = schema.QuoteIdentifier(col.Name)
+	}
+
+	return fmt.Sprintf(
+		`SELECT %s FROM %s.%s`,
+		strings.Join(columnNames, ", "),
+		schema.QuoteIdentifier(dt.Table.Schema),
+		schema.QuoteIdentifier(dt.Table.Name),
+	)
+}
+
+// BuildInsertQuery renders the parameterised INSERT used to write rows to the target.
+func (dt *DataTransferJob) BuildInsertQuery() string {
 	columnNames := make([]string, len(dt.Table.Columns))
 	placeholders := make([]string, len(dt.Table.Columns))
 
 	for i, col := range dt.Table.Columns {
-		columnNames[i] = fmt.Sprintf(`"%s"`, col.Name)
+		columnNames[i] = schema.QuoteIdentifier(schema.FoldIdentifier(col.Name, dt.IdentifierCase))
 		placeholders[i] = fmt.Sprintf("$%d", i+1)
 	}
 
 	return fmt.Sprintf(
-		`INSERT INTO "%s"."%s" (%s) VALUES (%s) ON CONFLICT DO NOTHING`,
-		dt.Table.Schema,
-		dt.Table.Name,
+		`INSERT INTO %s.%s (%s) VALUES (%s) ON CONFLICT DO NOTHING`,
+		schema.QuoteIdentifier(schema.MapSchema(dt.Table.Schema, dt.SchemaMap)),
+		schema.QuoteIdentifier(schema.FoldIdentifier(dt.Table.Name, dt.IdentifierCase)),
 		strings.Join(columnNames, ", "),
 		strings.Join(placeholders, ", "),
 	)
 }
 
+// BuildCopyQuery renders the COPY FROM STDIN statement that loads a batch
+// when UseCopy is set.
+func (dt *DataTransferJob) BuildCopyQuery() string {
+	columnNames := make([]string, len(dt.Table.Columns))
+	for i, col := range dt.Table.Columns {
+		columnNames[i] = schema.FoldIdentifier(col.Name, dt.IdentifierCase)
+	}
+
+	return pq.CopyInSchema(
+		schema.MapSchema(dt.Table.Schema, dt.SchemaMap),
+		schema.FoldIdentifier(dt.Table.Name, dt.IdentifierCase),
+		columnNames...,
+	)
+}
+
 func (dt *DataTransferJob) buildOrderByClause() string {
-	if len(dt.Table.PrimaryKeys) > 0 {
-		pkCols := make([]string, len(dt.Table.PrimaryKeys))
-		for i, pk := range dt.Table.PrimaryKeys {
-			pkCols[i] = fmt.Sprintf(`"%s"`, pk)
+	if key, _ := OrderKey(dt.Table); len(key) > 0 {
+		keyCols := make([]string, len(key))
+		for i, column := range key {
+			keyCols[i] = fmt.Sprintf(`"%s"`, column)
 		}
-		return strings.Join(pkCols, ", ")
+		return strings.Join(keyCols, ", ")
 	}
 
 	if len(dt.Table.Columns) > 0 {
@@ -0,0 +1,48 @@
+package concurrency
+
+import (
+	"context"
+	"runtime"
+)
+
+// Limiter is a counting semaphore shared by every parallel operation in a run so
+// that combined features cannot exceed one overall concurrency budget.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a limiter allowing max concurrent holders. Values below one
+// fall back to runtime.NumCPU.
+func NewLimiter(max int) *Limiter {
+	if max < 1 {
+		max = runtime.NumCPU()
+	}
+	return &Limiter{slots: make(chan struct{}, max)}
+}
+
+func (l *Limiter) Cap() int {
+	return cap(l.slots)
+}
+
+// Acquire blocks until a slot is free or ctx is done.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) Release() {
+	<-l.slots
+}
+
+// Do runs fn while holding a slot.
+func (l *Limiter) Do(ctx context.Context, fn func() error) error {
+	if err := l.Acquire(ctx); err != nil {
+		return err
+	}
+	defer l.Release()
+	return fn()
+}
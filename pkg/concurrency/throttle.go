@@ -0,0 +1,66 @@
+package concurrency
+
+import (
+	"context"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// Throttle is a row and byte rate budget shared by every worker in a run, so
+// the combined throughput stays under the limit however many tables are copied
+// at once. A nil Throttle never waits.
+type Throttle struct {
+	rows  *rate.Limiter
+	bytes *rate.Limiter
+}
+
+// NewThrottle limits throughput to rowsPerSec rows and mbPerSec megabytes per
+// second. Zero disables either limit; it returns nil when both are disabled.
+// Each bucket holds one second of budget, so a run may burst that far ahead.
+func NewThrottle(rowsPerSec int, mbPerSec float64) *Throttle {
+	if rowsPerSec <= 0 && mbPerSec <= 0 {
+		return nil
+	}
+
+	t := &Throttle{}
+	if rowsPerSec > 0 {
+		t.rows = rate.NewLimiter(rate.Limit(rowsPerSec), rowsPerSec)
+	}
+	if mbPerSec > 0 {
+		bytesPerSec := mbPerSec * 1024 * 1024
+		t.bytes = rate.NewLimiter(rate.Limit(bytesPerSec), int(math.Max(1, bytesPerSec)))
+	}
+	return t
+}
+
+// Wait blocks until rows rows and bytes bytes fit in the budget, or ctx is done.
+func (t *Throttle) Wait(ctx context.Context, rows, bytes int64) error {
+	if t == nil {
+		return nil
+	}
+	if err := waitN(ctx, t.rows, rows); err != nil {
+		return err
+	}
+	return waitN(ctx, t.bytes, bytes)
+}
+
+// waitN takes n tokens in burst-sized steps, since rate.Limiter rejects a
+// single request larger than its bucket.
+func waitN(ctx context.Context, limiter *rate.Limiter, n int64) error {
+	if limiter == nil {
+		return nil
+	}
+	burst := int64(limiter.Burst())
+	for n > 0 {
+		step := n
+		if step > burst {
+			step = burst
+		}
+		if err := limiter.WaitN(ctx, int(step)); err != nil {
+			return err
+		}
+		n -= step
+	}
+	return nil
+}
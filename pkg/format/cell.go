@@ -0,0 +1,71 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	BoolTrueFalse = "true/false"
+	BoolTF        = "t/f"
+	BoolOneZero   = "1/0"
+	BoolYesNo     = "yes/no"
+)
+
+const DefaultNullString = "NULL"
+
+// CellOptions controls how NULL and boolean values are rendered in previews and exports.
+type CellOptions struct {
+	NullString string
+	BoolFormat string
+}
+
+func DefaultCellOptions() CellOptions {
+	return CellOptions{
+		NullString: DefaultNullString,
+		BoolFormat: BoolTrueFalse,
+	}
+}
+
+// ValidateBoolFormat reports an error for bool formats that are not recognised.
+func ValidateBoolFormat(boolFormat string) error {
+	switch strings.ToLower(strings.TrimSpace(boolFormat)) {
+	case "", BoolTrueFalse, BoolTF, BoolOneZero, BoolYesNo:
+		return nil
+	default:
+		return fmt.Errorf("unsupported bool format %q (expected %s, %s, %s or %s)", boolFormat, BoolTrueFalse, BoolTF, BoolOneZero, BoolYesNo)
+	}
+}
+
+func (o CellOptions) Cell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return o.NullString
+	case bool:
+		return o.Bool(v)
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (o CellOptions) Bool(value bool) string {
+	trueValue, falseValue := "true", "false"
+	switch strings.ToLower(strings.TrimSpace(o.BoolFormat)) {
+	case BoolTF:
+		trueValue, falseValue = "t", "f"
+	case BoolOneZero:
+		trueValue, falseValue = "1", "0"
+	case BoolYesNo:
+		trueValue, falseValue = "yes", "no"
+	}
+
+	if value {
+		return trueValue
+	}
+	return falseValue
+}
@@ -0,0 +1,61 @@
+package humanize
+
+import (
+	"fmt"
+	"time"
+)
+
+var byteUnits = []string{"kB", "MB", "GB", "TB", "PB", "EB"}
+
+// Bytes renders a size in 1024-byte units the way pg_size_pretty does, with
+// one decimal, e.g. "512 B", "1.2 GB".
+func Bytes(n int64) string {
+	if n < 0 {
+		return "-" + Bytes(-n)
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	i := -1
+	for value >= unit && i < len(byteUnits)-1 {
+		value /= unit
+		i++
+	}
+	// Rounding can carry 1023.96 kB up to "1024.0 kB".
+	if value >= unit-0.05 && i < len(byteUnits)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, byteUnits[i])
+}
+
+// Duration renders d with its two largest units, e.g. "850ms", "42s",
+// "3m 12s", "2h 5m" or "1d 4h".
+func Duration(d time.Duration) string {
+	if d < 0 {
+		return "-" + Duration(-d)
+	}
+	if d = d.Round(time.Millisecond); d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+
+	d = d.Round(time.Second)
+	days := int64(d / (24 * time.Hour))
+	hours := int64(d/time.Hour) % 24
+	minutes := int64(d/time.Minute) % 60
+	seconds := int64(d/time.Second) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
@@ -3,25 +3,56 @@ package interactive
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/engine"
 )
 
 type DatabaseSelector struct {
-	reader *bufio.Reader
-	dbType string
+	reader   *bufio.Reader
+	dbType   string
+	format   string
+	defaults BackupDefaults
+}
+
+// BackupDefaults replace the engine's defaults as the answers GetBackupOptions
+// suggests. Format is expected to be normalized already; a nil Compression
+// keeps the engine's level.
+type BackupDefaults struct {
+	Format      string
+	Compression *int
 }
 
 func NewDatabaseSelector(dbType string) *DatabaseSelector {
+	return NewDatabaseSelectorWithReader(dbType, os.Stdin)
+}
+
+// NewDatabaseSelectorWithReader reads answers from input instead of stdin.
+func NewDatabaseSelectorWithReader(dbType string, input io.Reader) *DatabaseSelector {
 	return &DatabaseSelector{
-		reader: bufio.NewReader(os.Stdin),
+		reader: bufio.NewReader(input),
 		dbType: strings.ToLower(strings.TrimSpace(dbType)),
 	}
 }
 
+// WithFormat fixes the PostgreSQL backup format so GetBackupOptions does not
+// ask for it. The format is expected to be normalized already.
+func (ds *DatabaseSelector) WithFormat(format string) *DatabaseSelector {
+	ds.format = format
+	return ds
+}
+
+// WithDefaults sets the format and compression GetBackupOptions offers when
+// an answer is left empty.
+func (ds *DatabaseSelector) WithDefaults(defaults BackupDefaults) *DatabaseSelector {
+	ds.defaults = defaults
+	return ds
+}
+
 func (ds *DatabaseSelector) SelectDatabase(databases []backup.DatabaseInfo) (*backup.DatabaseInfo, error) {
 	if len(databases) == 0 {
 		return nil, fmt.Errorf("no databases found")
@@ -49,12 +80,50 @@ func (ds *DatabaseSelector) SelectDatabase(databases []backup.DatabaseInfo) (*ba
 
 	fmt.Println(strings.Repeat("=", 80))
 
+	choice, err := ds.promptChoice("database", len(databases))
+	if err != nil {
+		return nil, err
+	}
+
+	selected := &databases[choice-1]
+	fmt.Printf("\nSelected database: %s\n", selected.Name)
+	return selected, nil
+}
+
+// SelectBackup lists recorded backups and asks the user to pick one.
+func (ds *DatabaseSelector) SelectBackup(entries []backup.RegistryEntry) (*backup.RegistryEntry, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no recorded backups found")
+	}
+
+	fmt.Println()
+	fmt.Println("Recorded backups:")
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("%-4s %-20s %-20s %-10s %-12s\n", "No", "Created", "Database", "Format", "Size")
+	fmt.Println(strings.Repeat("-", 80))
+	for i, entry := range entries {
+		fmt.Printf("%-4d %-20s %-20s %-10s %-12d\n",
+			i+1, entry.CreatedAt.Local().Format("2006-01-02 15:04:05"), entry.Database, entry.Format, entry.Size)
+	}
+	fmt.Println(strings.Repeat("=", 80))
+
+	choice, err := ds.promptChoice("backup", len(entries))
+	if err != nil {
+		return nil, err
+	}
+
+	selected := &entries[choice-1]
+	fmt.Printf("\nSelected backup: %s\n", selected.Path)
+	return selected, nil
+}
+
+func (ds *DatabaseSelector) promptChoice(label string, count int) (int, error) {
 	for {
-		fmt.Printf("\nSelect the database number (1-%d): ", len(databases))
+		fmt.Printf("\nSelect the %s number (1-%d): ", label, count)
 
 		input, err := ds.reader.ReadString('\n')
 		if err != nil {
-			return nil, fmt.Errorf("unable to read input: %w", err)
+			return 0, fmt.Errorf("unable to read input: %w", err)
 		}
 
 		input = strings.TrimSpace(input)
@@ -70,14 +139,12 @@ func (ds *DatabaseSelector) SelectDatabase(databases []backup.DatabaseInfo) (*ba
 			continue
 		}
 
-		if choice < 1 || choice > len(databases) {
-			fmt.Printf("Please select a number between 1 and %d.\n", len(databases))
+		if choice < 1 || choice > count {
+			fmt.Printf("Please select a number between 1 and %d.\n", count)
 			continue
 		}
 
-		selected := &databases[choice-1]
-		fmt.Printf("\nSelected database: %s\n", selected.Name)
-		return selected, nil
+		return choice, nil
 	}
 }
 
@@ -99,25 +166,41 @@ func (ds *DatabaseSelector) GetBackupOptions(dbType string) backup.BackupOptions
 		dbType = ds.dbType
 	}
 
+	features, err := engine.Capabilities(dbType)
+	if err != nil {
+		features, _ = engine.Capabilities("postgres")
+	}
+
 	options := backup.BackupOptions{
-		Format:      "custom",
-		Compression: 6,
+		Format:      features.DefaultFormat,
+		Compression: features.DefaultCompression,
 		Verbose:     true,
 	}
+	if ds.defaults.Format != "" && features.SupportsFormat(ds.defaults.Format) {
+		options.Format = ds.defaults.Format
+	}
+	if ds.defaults.Compression != nil {
+		options.Compression = *ds.defaults.Compression
+	}
 
-	if dbType == "mongo" {
-		fmt.Println()
-		fmt.Println("Backup options (MongoDB):")
+	fmt.Println()
+	fmt.Printf("Backup options (%s):\n", features.Name)
+	if len(features.Formats) == 1 {
 		fmt.Println("1. Archive format (.archive)")
 		fmt.Println("2. Compressed archive (.archive.gz)")
 
+		defaultChoice := "2"
+		if options.Compression == 0 {
+			defaultChoice = "1"
+		}
+
 		for {
-			fmt.Print("\nChoose archive type (1-2) [2]: ")
+			fmt.Printf("\nChoose archive type (1-2) [%s]: ", defaultChoice)
 			input, _ := ds.reader.ReadString('\n')
 			input = strings.TrimSpace(input)
 
 			if input == "" {
-				input = "2"
+				input = defaultChoice
 			}
 
 			switch input {
@@ -134,20 +217,30 @@ func (ds *DatabaseSelector) GetBackupOptions(dbType string) backup.BackupOptions
 			break
 		}
 	} else {
-		fmt.Println()
-		fmt.Println("Backup options (PostgreSQL):")
-		fmt.Println("1. SQL format (plain text)")
-		fmt.Println("2. Custom format (compressed, recommended)")
-		fmt.Println("3. Tar format")
-		fmt.Println("4. Directory format")
+		if ds.format != "" {
+			options.Format = ds.format
+			fmt.Printf("Format: %s\n", ds.format)
+		} else {
+			fmt.Println("1. SQL format (plain text)")
+			fmt.Println("2. Custom format (compressed, recommended)")
+			fmt.Println("3. Tar format")
+			fmt.Println("4. Directory format")
+		}
 
-		for {
-			fmt.Print("\nSelect format (1-4) [2]: ")
+		defaultChoice := "2"
+		for i, format := range []string{"sql", "custom", "tar", "directory"} {
+			if format == options.Format {
+				defaultChoice = strconv.Itoa(i + 1)
+			}
+		}
+
+		for ds.format == "" {
+			fmt.Printf("\nSelect format (1-4) [%s]: ", defaultChoice)
 			input, _ := ds.reader.ReadString('\n')
 			input = strings.TrimSpace(input)
 
 			if input == "" {
-				input = "2"
+				input = defaultChoice
 			}
 
 			switch input {
@@ -167,7 +260,7 @@ func (ds *DatabaseSelector) GetBackupOptions(dbType string) backup.BackupOptions
 		}
 
 		if options.Format == "custom" || options.Format == "tar" {
-			fmt.Print("Compression level (0-9) [6]: ")
+			fmt.Printf("Compression level (0-9) [%d]: ", options.Compression)
 			compressionInput, _ := ds.reader.ReadString('\n')
 			compressionInput = strings.TrimSpace(compressionInput)
 
@@ -178,6 +271,28 @@ func (ds *DatabaseSelector) GetBackupOptions(dbType string) backup.BackupOptions
 			}
 		}
 
+		if options.Format == "directory" {
+			fmt.Print("Parallel jobs (1-64) [1]: ")
+			jobsInput, _ := ds.reader.ReadString('\n')
+			jobsInput = strings.TrimSpace(jobsInput)
+
+			if jobsInput != "" {
+				if jobs, err := strconv.Atoi(jobsInput); err == nil && jobs >= 1 && jobs <= 64 {
+					options.Jobs = jobs
+				}
+			}
+		}
+
+		fmt.Print("Exclude tables (comma-separated patterns, e.g. audit.*; empty for none): ")
+		tablesInput, _ := ds.reader.ReadString('\n')
+		options.ExcludeTables = splitList(tablesInput)
+
+		fmt.Print("Exclude schemas (comma-separated patterns; empty for none): ")
+		schemasInput, _ := ds.reader.ReadString('\n')
+		options.ExcludeSchemas = splitList(schemasInput)
+	}
+
+	if features.SupportsSchemaOnly {
 		fmt.Print("Backup schema only? (y/N): ")
 		schemaInput, _ := ds.reader.ReadString('\n')
 		schemaInput = strings.ToLower(strings.TrimSpace(schemaInput))
@@ -199,6 +314,12 @@ func (ds *DatabaseSelector) GetBackupOptions(dbType string) backup.BackupOptions
 }
 
 func (ds *DatabaseSelector) GetRestoreOptions(dbType string) backup.RestoreOptions {
+	return ds.GetRestoreOptionsFrom(dbType, nil)
+}
+
+// GetRestoreOptionsFrom collects restore options, pre-filling the backup path
+// and suggesting the original database when a recorded backup was chosen.
+func (ds *DatabaseSelector) GetRestoreOptionsFrom(dbType string, entry *backup.RegistryEntry) backup.RestoreOptions {
 	dbType = strings.ToLower(strings.TrimSpace(dbType))
 	if dbType == "" {
 		dbType = ds.dbType
@@ -209,13 +330,20 @@ func (ds *DatabaseSelector) GetRestoreOptions(dbType string) backup.RestoreOptio
 		ExitOnError: true,
 	}
 
-	fmt.Print("Backup file path (look under backup/): ")
-	backupInput, _ := ds.reader.ReadString('\n')
-	options.BackupPath = strings.TrimSpace(backupInput)
+	if entry != nil {
+		options.BackupPath = entry.Path
+		fmt.Printf("Target database name [%s]: ", entry.Database)
+		dbInput, _ := ds.reader.ReadString('\n')
+		options.TargetDatabase = safeValue(strings.TrimSpace(dbInput), entry.Database)
+	} else {
+		fmt.Print("Backup file path (look under backup/): ")
+		backupInput, _ := ds.reader.ReadString('\n')
+		options.BackupPath = strings.TrimSpace(backupInput)
 
-	fmt.Print("Target database name: ")
-	dbInput, _ := ds.reader.ReadString('\n')
-	options.TargetDatabase = strings.TrimSpace(dbInput)
+		fmt.Print("Target database name: ")
+		dbInput, _ := ds.reader.ReadString('\n')
+		options.TargetDatabase = strings.TrimSpace(dbInput)
+	}
 
 	if dbType == "postgres" {
 		fmt.Print("Create the database if it does not exist? (Y/n): ")
@@ -250,6 +378,17 @@ func (ds *DatabaseSelector) GetRestoreOptions(dbType string) backup.RestoreOptio
 	return options
 }
 
+// splitList splits a comma-separated answer, dropping empty entries.
+func splitList(input string) []string {
+	var items []string
+	for _, item := range strings.Split(input, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func safeValue(value, fallback string) string {
 	if strings.TrimSpace(value) == "" {
 		return fallback
@@ -0,0 +1,222 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	multiBarWidth   = 30
+	multiNameWidth  = 32
+	multiRenderRate = 100 * time.Millisecond
+)
+
+// Multi tracks progress per table and, on a terminal, draws one line per
+// active table above an aggregate total. Elsewhere it feeds a single
+// aggregate bar, since redrawing lines only garbles logs and pipes.
+type Multi struct {
+	mu          sync.Mutex
+	w           io.Writer
+	perTable    bool
+	description string
+	total       int64
+	current     int64
+	tables      []*TableBar
+	finished    []*TableBar
+	aggregate   *Bar
+	drawn       int
+	lastRender  time.Time
+}
+
+// TableBar is one table's share of a Multi. It is safe to use from several
+// workers at once, e.g. the ranges of a split table.
+type TableBar struct {
+	multi   *Multi
+	name    string
+	total   int64
+	current int64
+	started bool
+	done    bool
+}
+
+// TableStatus is a point-in-time view of one table's progress.
+type TableStatus struct {
+	Name    string
+	Current int64
+	Total   int64
+	Started bool
+	Done    bool
+}
+
+// NewMulti writes to the progress output, drawing per-table lines only when
+// that output is a terminal.
+func NewMulti(total int64, description string) *Multi {
+	return NewMultiTo(output, total, description, isTerminal(output))
+}
+
+// NewMultiTo writes to w; perTable selects the multi-line display over the
+// single aggregate bar.
+func NewMultiTo(w io.Writer, total int64, description string, perTable bool) *Multi {
+	m := &Multi{w: w, perTable: perTable, description: description, total: total}
+	if !perTable {
+		m.aggregate = newBar(w, total, description)
+	}
+	return m
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Table registers a table expected to copy total rows. It is not displayed
+// until Start is called, so tables waiting for a worker stay hidden.
+func (m *Multi) Table(name string, total int64) *TableBar {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table := &TableBar{multi: m, name: name, total: total}
+	m.tables = append(m.tables, table)
+	return table
+}
+
+// Current returns the rows copied across all tables.
+func (m *Multi) Current() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
+
+// Tables returns the status of every registered table in registration order.
+func (m *Multi) Tables() []TableStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]TableStatus, 0, len(m.tables))
+	for _, table := range m.tables {
+		statuses = append(statuses, TableStatus{
+			Name:    table.name,
+			Current: table.current,
+			Total:   table.total,
+			Started: table.started,
+			Done:    table.done,
+		})
+	}
+	return statuses
+}
+
+// Finish draws the final state.
+func (m *Multi) Finish() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.aggregate != nil {
+		m.aggregate.Finish()
+		return
+	}
+	m.render(true)
+}
+
+// Start marks the table as being copied.
+func (t *TableBar) Start() {
+	m := t.multi
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !t.started {
+		t.started = true
+		m.render(true)
+	}
+}
+
+func (t *TableBar) IncrementBy(amount int64) {
+	m := t.multi
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t.started = true
+	t.current += amount
+	m.current += amount
+	if m.aggregate != nil {
+		m.aggregate.IncrementBy(amount)
+		return
+	}
+	m.render(false)
+}
+
+// Done marks the table as finished; its line moves above the active ones.
+func (t *TableBar) Done() {
+	m := t.multi
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t.done {
+		return
+	}
+	t.done = true
+	if t.started {
+		m.finished = append(m.finished, t)
+	}
+	m.render(true)
+}
+
+// render redraws the active lines in place. It must be called with mu held.
+func (m *Multi) render(force bool) {
+	if !m.perTable {
+		return
+	}
+	if !force && time.Since(m.lastRender) < multiRenderRate {
+		return
+	}
+	m.lastRender = time.Now()
+
+	var b strings.Builder
+	if m.drawn > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", m.drawn)
+	}
+	for _, table := range m.finished {
+		fmt.Fprintf(&b, "\x1b[2K%s\n", progressLine(table.name, table.current, table.total))
+	}
+	m.finished = m.finished[:0]
+
+	m.drawn = 0
+	for _, table := range m.tables {
+		if table.started && !table.done {
+			fmt.Fprintf(&b, "\x1b[2K%s\n", progressLine(table.name, table.current, table.total))
+			m.drawn++
+		}
+	}
+	fmt.Fprintf(&b, "\x1b[2K%s\n", progressLine(m.description, m.current, m.total))
+	m.drawn++
+
+	io.WriteString(m.w, b.String())
+}
+
+func progressLine(name string, current, total int64) string {
+	if len(name) > multiNameWidth {
+		name = "..." + name[len(name)-multiNameWidth+3:]
+	}
+
+	filled := 0
+	percent := 0
+	if total > 0 {
+		ratio := float64(current) / float64(total)
+		if ratio > 1 {
+			ratio = 1
+		}
+		filled = int(ratio * multiBarWidth)
+		percent = int(ratio * 100)
+	}
+
+	bar := strings.Repeat("=", filled)
+	if filled < multiBarWidth {
+		bar += ">" + strings.Repeat(" ", multiBarWidth-filled-1)
+	}
+	return fmt.Sprintf("%-*s [%s] %3d%% (%d/%d)", multiNameWidth, name, bar, percent, current, total)
+}
@@ -2,18 +2,33 @@ package progress
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
 )
 
+var output io.Writer = os.Stdout
+
+// SetOutput redirects progress bars created afterwards, e.g. to stderr when
+// stdout carries machine-readable output.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
 type Bar struct {
 	*progressbar.ProgressBar
 }
 
 func NewBar(max int64, description string) *Bar {
+	return newBar(output, max, description)
+}
+
+func newBar(w io.Writer, max int64, description string) *Bar {
 	bar := progressbar.NewOptions64(max,
 		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWriter(w),
 		progressbar.OptionSetWidth(50),
 		progressbar.OptionShowCount(),
 		progressbar.OptionShowIts(),
@@ -26,7 +41,7 @@ func NewBar(max int64, description string) *Bar {
 		}),
 		progressbar.OptionThrottle(100*time.Millisecond),
 		progressbar.OptionOnCompletion(func() {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}),
 	)
 
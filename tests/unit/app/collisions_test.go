@@ -0,0 +1,44 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func savedEndpoints() []app.ProfileEndpoint {
+	return []app.ProfileEndpoint{
+		{Name: "orders-pg.yaml", Type: "postgres", Host: "localhost", Port: 5432},
+		{Name: "events-mongo.yaml", Type: "mongo", Host: "127.0.0.1", Port: 27017},
+		{Name: "staging.yaml", Type: "postgres", Host: "staging.internal", Port: 5432},
+	}
+}
+
+func TestPortCollisionsFlagsOtherEngineOnSamePort(t *testing.T) {
+	candidate := app.ProfileEndpoint{Type: "postgres", Host: "localhost", Port: 27017}
+
+	collisions := app.PortCollisions(savedEndpoints(), candidate)
+	require.Len(t, collisions, 1, "127.0.0.1 and localhost are the same host")
+	assert.Equal(t, "events-mongo.yaml", collisions[0].Name)
+	assert.Contains(t, app.CollisionWarning(candidate, collisions[0]), `mongo profile "events-mongo.yaml"`)
+}
+
+func TestPortCollisionsFlagsOtherProfileOfSameEngine(t *testing.T) {
+	candidate := app.ProfileEndpoint{Name: "inventory-pg.yaml", Type: "postgres", Host: "LOCALHOST", Port: 5432}
+
+	collisions := app.PortCollisions(savedEndpoints(), candidate)
+	require.Len(t, collisions, 1)
+	assert.Equal(t, "orders-pg.yaml", collisions[0].Name)
+	assert.Equal(t, `LOCALHOST:5432 is also used by profile "orders-pg.yaml"`, app.CollisionWarning(candidate, collisions[0]))
+}
+
+func TestPortCollisionsIgnoresOtherHostsAndItself(t *testing.T) {
+	existing := savedEndpoints()
+
+	assert.Empty(t, app.PortCollisions(existing, app.ProfileEndpoint{Type: "postgres", Host: "prod.internal", Port: 5432}))
+	assert.Empty(t, app.PortCollisions(existing, existing[0]), "a profile does not collide with itself")
+	assert.Empty(t, app.PortCollisions(existing, app.ProfileEndpoint{Type: "mongo"}), "URI-only profiles have no host to compare")
+}
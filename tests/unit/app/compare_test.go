@@ -0,0 +1,59 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareCountsClassifiesTables(t *testing.T) {
+	comparisons := app.CompareCounts(
+		map[string]int64{"public.users": 10, "public.orders": 5, "public.audit": 1},
+		map[string]int64{"public.users": 10, "public.orders": 4, "public.sessions": 0},
+	)
+
+	statuses := make(map[string]string)
+	for _, comparison := range comparisons {
+		statuses[comparison.Table] = comparison.Status
+	}
+	assert.Equal(t, map[string]string{
+		"public.audit":    app.CountSourceOnly,
+		"public.orders":   app.CountMismatch,
+		"public.sessions": app.CountTargetOnly,
+		"public.users":    app.CountMatch,
+	}, statuses)
+	assert.Equal(t, "public.audit", comparisons[0].Table, "results are sorted by name")
+	assert.Nil(t, comparisons[0].TargetRows)
+}
+
+func TestWriteCountComparisonReportsDifferences(t *testing.T) {
+	comparisons := app.CompareCounts(
+		map[string]int64{"events": 3, "users": 2},
+		map[string]int64{"events": 3},
+	)
+
+	var out bytes.Buffer
+	err := app.WriteCountComparison(&out, comparisons, "text")
+	require.Error(t, err)
+	assert.Equal(t, "1 of 2 tables differ", err.Error())
+	assert.Contains(t, out.String(), "users   2       -       source_only")
+
+	out.Reset()
+	require.Error(t, app.WriteCountComparison(&out, comparisons, "json"))
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	assert.Nil(t, decoded[1]["target_rows"])
+}
+
+func TestWriteCountComparisonAllMatching(t *testing.T) {
+	comparisons := app.CompareCounts(map[string]int64{"users": 2}, map[string]int64{"users": 2})
+
+	var out bytes.Buffer
+	assert.NoError(t, app.WriteCountComparison(&out, comparisons, "text"))
+}
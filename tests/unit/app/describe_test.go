@@ -0,0 +1,69 @@
+package app_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFormatTableDescription(t *testing.T) {
+	length := 120
+	defaultValue := "nextval('orders_id_seq'::regclass)"
+	table := schema.Table{
+		Name:   "orders",
+		Schema: "sales",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "integer", DefaultValue: &defaultValue},
+			{Name: "note", DataType: "character varying", IsNullable: true, MaxLength: &length},
+			{Name: "customer_id", DataType: "integer"},
+		},
+		PrimaryKeys: []string{"id"},
+		Indexes: []schema.Index{
+			{Name: "orders_pkey", Columns: []string{"id"}, IsPrimary: true, IsUnique: true, IndexType: "BTREE"},
+			{Name: "orders_customer_idx", Columns: []string{"customer_id"}, IndexType: "BTREE"},
+		},
+		ForeignKeys: []schema.ForeignKey{
+			{Name: "orders_customer_fk", ColumnName: "customer_id", ReferencedSchema: "sales", ReferencedTable: "customers", ReferencedColumn: "id"},
+		},
+		RowCount: 42,
+	}
+
+	var out bytes.Buffer
+	app.FormatTableDescription(&out, table)
+	text := out.String()
+
+	assert.Contains(t, text, "Table sales.orders (42 rows)")
+	assert.Regexp(t, `id\s+integer\s+no\s+nextval\('orders_id_seq'::regclass\)`, text)
+	assert.Regexp(t, `note\s+character varying\(120\)\s+yes`, text)
+	assert.Contains(t, text, "Primary key: id")
+	assert.Contains(t, text, "orders_pkey (id) [BTREE, primary]")
+	assert.Contains(t, text, "orders_customer_idx (customer_id) [BTREE]")
+	assert.Contains(t, text, "orders_customer_fk: customer_id -> sales.customers(id)")
+}
+
+func TestFormatTableDescriptionWithoutKeys(t *testing.T) {
+	var out bytes.Buffer
+	app.FormatTableDescription(&out, schema.Table{Name: "log", Schema: "public"})
+
+	assert.Contains(t, out.String(), "Primary key: none")
+	assert.Contains(t, out.String(), "Indexes:\n  none")
+	assert.Contains(t, out.String(), "Foreign keys:\n  none")
+}
+
+func TestInferFieldsMergesTypesAcrossDocuments(t *testing.T) {
+	fields := app.InferFields([]bson.M{
+		{"_id": int32(1), "name": "a"},
+		{"_id": int32(2), "name": nil, "tags": bson.A{"x"}},
+	})
+
+	assert.Equal(t, []app.FieldDescription{
+		{Name: "_id", Types: []string{"int"}},
+		{Name: "name", Types: []string{"null", "string"}},
+		{Name: "tags", Types: []string{"array"}},
+	}, fields)
+}
@@ -0,0 +1,34 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDoctorReport(t *testing.T) {
+	results := []app.DoctorResult{
+		{Tool: "pg_dump", Check: &backup.VersionCheck{
+			Tool: "pg_dump", Engine: "postgres",
+			Client: backup.Version{Major: 16, Raw: "16.2"}, Server: backup.Version{Major: 16, Raw: "16.1"},
+		}},
+		{Tool: "pg_restore", Check: &backup.VersionCheck{
+			Tool: "pg_restore", Engine: "postgres",
+			Client: backup.Version{Major: 14, Raw: "14.9"}, Server: backup.Version{Major: 16, Raw: "16.1"},
+		}},
+		{Tool: "psql", Err: errors.New("executable file not found")},
+	}
+
+	var out bytes.Buffer
+	err := app.WriteDoctorReport(&out, results)
+
+	assert.EqualError(t, err, "2 of 3 checks reported problems")
+	assert.Contains(t, out.String(), "[ OK ] pg_dump 16.2 is compatible")
+	assert.Contains(t, out.String(), "[WARN] pg_restore 14.9 is older than the postgres server 16.1")
+	assert.Contains(t, out.String(), "[FAIL] psql: executable file not found")
+}
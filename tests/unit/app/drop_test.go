@@ -0,0 +1,36 @@
+package app_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmDropRequiresTypedName(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, app.ConfirmDrop("shop_test", "localhost:5432", false, strings.NewReader("shop_test\n"), &out))
+	assert.Contains(t, out.String(), "Type the database name to confirm")
+
+	for _, input := range []string{"y\n", "shop\n", ""} {
+		err := app.ConfirmDrop("shop_test", "localhost:5432", false, strings.NewReader(input), &bytes.Buffer{})
+		assert.EqualError(t, err, "confirmation did not match; database shop_test was not dropped", "input %q", input)
+	}
+
+	out.Reset()
+	assert.NoError(t, app.ConfirmDrop("shop_test", "localhost:5432", true, strings.NewReader(""), &out))
+	assert.Empty(t, out.String(), "--yes skips the prompt")
+}
+
+func TestDropDatabaseRefusesAdminDatabaseBeforeConnecting(t *testing.T) {
+	cfg := &appconfig.Config{Database: appconfig.DatabaseConfig{Type: "postgres", Host: "localhost", Port: 5432}}
+
+	var out bytes.Buffer
+	err := app.DropDatabase(cfg, "postgres", true, strings.NewReader(""), &out)
+	assert.EqualError(t, err, "refusing to drop the postgres system database postgres")
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,103 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	"github.com/kadirbelkuyu/DBRTS/pkg/format"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFlattenDocumentNestedFields(t *testing.T) {
+	doc := bson.M{
+		"name": "Ada",
+		"address": bson.M{
+			"city": "London",
+			"geo":  bson.D{{Key: "lat", Value: 51.5}, {Key: "lng", Value: -0.12}},
+		},
+		"tags": bson.A{"admin", bson.M{"scope": "billing"}},
+	}
+
+	flat := app.FlattenDocument(doc)
+
+	assert.Equal(t, map[string]interface{}{
+		"name":            "Ada",
+		"address.city":    "London",
+		"address.geo.lat": 51.5,
+		"address.geo.lng": -0.12,
+		"tags":            `["admin",{"scope":"billing"}]`,
+	}, flat)
+}
+
+func TestUnionKeysAcrossHeterogeneousDocuments(t *testing.T) {
+	rows := []map[string]interface{}{
+		app.FlattenDocument(bson.M{"_id": 1, "name": "a", "meta": bson.M{"source": "web"}}),
+		app.FlattenDocument(bson.M{"_id": 2, "email": "b@example.com"}),
+		app.FlattenDocument(bson.M{"_id": 3, "meta": bson.M{"campaign": "spring"}}),
+	}
+
+	assert.Equal(t, []string{"_id", "email", "meta.campaign", "meta.source", "name"}, app.UnionKeys(rows))
+}
+
+func TestWriteFlatCSVFillsMissingFields(t *testing.T) {
+	rows := []map[string]interface{}{
+		app.FlattenDocument(bson.M{"_id": 1, "name": "a"}),
+		app.FlattenDocument(bson.M{"_id": 2, "active": true}),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, app.WriteFlatCSV(&out, rows, format.DefaultCellOptions()))
+
+	assert.Equal(t, "_id,active,name\n1,NULL,a\n2,true,NULL\n", out.String())
+}
+
+// failingWriter accepts limit bytes and then fails every write.
+type failingWriter struct {
+	limit   int
+	written int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.limit {
+		return 0, errors.New("broken pipe")
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestWriteCSVRowsStopsAtWriteError(t *testing.T) {
+	calls := 0
+	next := func() (map[string]interface{}, error) {
+		calls++
+		if calls > 10000 {
+			return nil, nil
+		}
+		return map[string]interface{}{"_id": calls, "name": "row"}, nil
+	}
+
+	writer := app.NewRowWriter(&failingWriter{limit: 20}, 4096, 10)
+	count, err := app.WriteCSVRows(writer, []string{"_id", "name"}, next, format.DefaultCellOptions())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken pipe")
+	assert.Equal(t, 10, count)
+	assert.Equal(t, 10, calls)
+}
+
+func TestRowWriterFlushesEveryNRows(t *testing.T) {
+	var out bytes.Buffer
+	writer := app.NewRowWriter(&out, 4096, 2)
+
+	_, _ = writer.WriteString("a\n")
+	require.NoError(t, writer.EndRow())
+	assert.Empty(t, out.String())
+
+	_, _ = writer.WriteString("b\n")
+	require.NoError(t, writer.EndRow())
+	assert.Equal(t, "a\nb\n", out.String())
+}
@@ -0,0 +1,64 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const hexID = "507f1f77bcf86cd799439011"
+
+func mustObjectID(t *testing.T) primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(hexID)
+	require.NoError(t, err)
+	return id
+}
+
+func TestParseMongoFilterConvertsBareHexID(t *testing.T) {
+	filter, err := app.ParseMongoFilter(`{"_id":"`+hexID+`"}`, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, bson.D{{Key: "_id", Value: mustObjectID(t)}}, filter)
+}
+
+func TestParseMongoFilterLeavesNonHexIDAsString(t *testing.T) {
+	filter, err := app.ParseMongoFilter(`{"_id":"order-42"}`, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, bson.D{{Key: "_id", Value: "order-42"}}, filter)
+}
+
+func TestParseMongoFilterConvertsOperatorsAndNestedClauses(t *testing.T) {
+	filter, err := app.ParseMongoFilter(`{"$or":[{"_id":{"$in":["`+hexID+`","x"]}},{"name":"`+hexID+`"}]}`, true)
+	require.NoError(t, err)
+
+	clauses := filter[0].Value.(bson.A)
+	in := clauses[0].(bson.D)[0].Value.(bson.D)[0].Value.(bson.A)
+	assert.Equal(t, mustObjectID(t), in[0])
+	assert.Equal(t, "x", in[1])
+	assert.Equal(t, hexID, clauses[1].(bson.D)[0].Value, "only _id positions are converted")
+}
+
+func TestParseMongoFilterKeepsExplicitTypesAndOptOut(t *testing.T) {
+	filter, err := app.ParseMongoFilter(`{"_id":{"$oid":"`+hexID+`"}}`, true)
+	require.NoError(t, err)
+	assert.Equal(t, mustObjectID(t), filter[0].Value)
+
+	filter, err = app.ParseMongoFilter(`{"_id":"`+hexID+`"}`, false)
+	require.NoError(t, err)
+	assert.Equal(t, hexID, filter[0].Value)
+}
+
+func TestParseMongoFilterEmptyAndInvalid(t *testing.T) {
+	filter, err := app.ParseMongoFilter("  ", true)
+	require.NoError(t, err)
+	assert.Empty(t, filter)
+
+	_, err = app.ParseMongoFilter(`{"_id":`, true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package app_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseIndexKeys(t *testing.T) {
+	keys, err := app.ParseIndexKeys("email:1, created_at:-1,body:text,owner")
+	require.NoError(t, err)
+
+	assert.Equal(t, bson.D{
+		{Key: "email", Value: int32(1)},
+		{Key: "created_at", Value: int32(-1)},
+		{Key: "body", Value: "text"},
+		{Key: "owner", Value: int32(1)},
+	}, keys)
+}
+
+func TestParseIndexKeysRejectsInvalidInput(t *testing.T) {
+	_, err := app.ParseIndexKeys("email:up")
+	assert.Error(t, err)
+
+	_, err = app.ParseIndexKeys(" , ")
+	assert.Error(t, err)
+}
+
+func TestBuildIndexModelWithOptions(t *testing.T) {
+	model, err := app.BuildIndexModel(app.IndexSpec{
+		Name:       "sessions_expiry",
+		Keys:       "expires_at:1",
+		Unique:     true,
+		TTLSeconds: 3600,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, bson.D{{Key: "expires_at", Value: int32(1)}}, model.Keys)
+	require.NotNil(t, model.Options)
+	assert.Equal(t, "sessions_expiry", *model.Options.Name)
+	assert.True(t, *model.Options.Unique)
+	assert.Equal(t, int32(3600), *model.Options.ExpireAfterSeconds)
+	assert.Nil(t, model.Options.Sparse)
+}
+
+func TestBuildIndexModelRejectsCompoundTTL(t *testing.T) {
+	_, err := app.BuildIndexModel(app.IndexSpec{Keys: "a:1,b:1", TTLSeconds: 60})
+	assert.Error(t, err)
+}
+
+func TestFormatIndexes(t *testing.T) {
+	ttl := int32(60)
+	var out bytes.Buffer
+	app.FormatIndexes(&out, []app.CollectionIndex{
+		{Name: "_id_", Keys: "_id:1"},
+		{Name: "email_1", Keys: "email:1", Unique: true, Sparse: true},
+		{Name: "expires_at_1", Keys: "expires_at:1", TTLSeconds: &ttl},
+	})
+
+	assert.Equal(t, "_id_ {_id:1}\nemail_1 {email:1} [unique, sparse]\nexpires_at_1 {expires_at:1} [ttl 60s]\n", out.String())
+}
@@ -0,0 +1,78 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type junitReport struct {
+	Suites []struct {
+		Name     string `xml:"name,attr"`
+		Tests    int    `xml:"tests,attr"`
+		Failures int    `xml:"failures,attr"`
+		Skipped  int    `xml:"skipped,attr"`
+		Time     string `xml:"time,attr"`
+		Cases    []struct {
+			ClassName string `xml:"classname,attr"`
+			Name      string `xml:"name,attr"`
+			Time      string `xml:"time,attr"`
+			Failure   *struct {
+				Message string `xml:"message,attr"`
+			} `xml:"failure"`
+			Skipped *struct{} `xml:"skipped"`
+		} `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+func TestWriteJUnitWithMixedOutcomes(t *testing.T) {
+	report := transfer.NewTransferReport()
+	report.Record(transfer.TableResult{Schema: "public", Table: "users", RowsAttempted: 10, RowsSucceeded: 10, Duration: 1500 * time.Millisecond}, nil)
+	report.Record(transfer.TableResult{Schema: "public", Table: "orders", RowsAttempted: 5, RowsSucceeded: 2, Duration: 500 * time.Millisecond}, errors.New(`insert into "orders" failed: <constraint> & more`))
+	report.Record(transfer.TableResult{Schema: "public", Table: "audit", Status: transfer.StatusSkipped}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, app.WriteJUnit(&buf, "dbrts transfer", app.TransferJUnitCases(report, errors.New("transfer failed"))))
+	assert.Contains(t, buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`)
+
+	var parsed junitReport
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	require.Len(t, parsed.Suites, 1)
+
+	suite := parsed.Suites[0]
+	assert.Equal(t, "dbrts transfer", suite.Name)
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	assert.Equal(t, "2.000", suite.Time)
+
+	require.Len(t, suite.Cases, 3)
+	assert.Equal(t, "public.audit", suite.Cases[0].Name)
+	assert.NotNil(t, suite.Cases[0].Skipped)
+
+	assert.Equal(t, "public.orders", suite.Cases[1].Name)
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Equal(t, `insert into "orders" failed: <constraint> & more`, suite.Cases[1].Failure.Message)
+	assert.Equal(t, "0.500", suite.Cases[1].Time)
+
+	assert.Equal(t, "dbrts.transfer", suite.Cases[2].ClassName)
+	assert.Equal(t, "public.users", suite.Cases[2].Name)
+	assert.Nil(t, suite.Cases[2].Failure)
+	assert.Equal(t, "1.500", suite.Cases[2].Time)
+}
+
+func TestTransferJUnitCasesReportsFailureWithoutTables(t *testing.T) {
+	cases := app.TransferJUnitCases(nil, errors.New("schema creation failed"))
+
+	require.Len(t, cases, 1)
+	assert.Equal(t, "transfer", cases[0].Name)
+	assert.Equal(t, "schema creation failed", cases[0].Failure)
+}
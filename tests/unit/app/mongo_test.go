@@ -0,0 +1,40 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMongoDatabaseOverrideSelectsDatabase(t *testing.T) {
+	cfg := &appconfig.Config{Database: appconfig.DatabaseConfig{
+		Type:     "mongo",
+		Host:     "mongo.internal",
+		Port:     27017,
+		Database: "shop",
+		Username: "reader",
+		Password: "secret",
+	}}
+
+	// Connect does not reach the server until the first operation.
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://mongo.internal:27017"))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+
+	assert.Equal(t, "shop", app.SelectMongoDatabase(client, cfg).Name())
+
+	override := cfg.WithMongoDatabase("analytics")
+	assert.Equal(t, "analytics", app.SelectMongoDatabase(client, override).Name())
+
+	// The saved profile and the URI users authenticate with are unchanged.
+	assert.Equal(t, "shop", cfg.Database.Database)
+	assert.Equal(t, "shop", cfg.MongoDatabase())
+	assert.Equal(t, cfg.GetMongoURI(), override.GetMongoURI())
+}
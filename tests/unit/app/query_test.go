@@ -0,0 +1,63 @@
+package app_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	"github.com/kadirbelkuyu/DBRTS/pkg/format"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFormatNullsAndBoolsUsesCellOptions(t *testing.T) {
+	values := []interface{}{nil, true, false, int64(0), "t"}
+	app.FormatNullsAndBools(values, format.CellOptions{NullString: `\N`, BoolFormat: format.BoolTF})
+	assert.Equal(t, []interface{}{`\N`, "t", "f", int64(0), "t"}, values, "only NULLs and booleans change")
+
+	var out bytes.Buffer
+	require.NoError(t, app.WriteNDJSONRow(&out, []string{"deleted_at", "active"}, []string{"TIMESTAMPTZ", "BOOL"}, values[:2]))
+	assert.Equal(t, `{"deleted_at":"\\N","active":"t"}`+"\n", out.String())
+}
+
+func TestWriteNDJSONRowKeepsColumnOrderAndNulls(t *testing.T) {
+	var out bytes.Buffer
+	columns := []string{"id", "email", "deleted_at", "balance", "avatar", "meta"}
+	types := []string{"INT8", "TEXT", "TIMESTAMPTZ", "NUMERIC", "BYTEA", "JSONB"}
+	values := []interface{}{int64(7), "ann@example.com", nil, []byte("12.50"), []byte{0xde, 0xad}, []byte(`{"a":1}`)}
+
+	require.NoError(t, app.WriteNDJSONRow(&out, columns, types, values))
+
+	assert.Equal(t,
+		`{"id":7,"email":"ann@example.com","deleted_at":null,"balance":12.50,"avatar":"\\xdead","meta":{"a":1}}`+"\n",
+		out.String())
+}
+
+func TestWriteNDJSONRowOneLinePerRow(t *testing.T) {
+	var out bytes.Buffer
+	columns := []string{"name", "at"}
+	types := []string{"VARCHAR", "TIMESTAMP"}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, app.WriteNDJSONRow(&out, columns, types, []interface{}{"line\nbreak", at}))
+	require.NoError(t, app.WriteNDJSONRow(&out, columns, types, []interface{}{nil, nil}))
+
+	assert.Equal(t,
+		`{"name":"line\nbreak","at":"2024-01-02T03:04:05Z"}`+"\n"+`{"name":null,"at":null}`+"\n",
+		out.String())
+}
+
+func TestWriteExtJSONLine(t *testing.T) {
+	id, err := primitive.ObjectIDFromHex("507f1f77bcf86cd799439011")
+	require.NoError(t, err)
+	raw, err := bson.Marshal(bson.D{{Key: "_id", Value: id}, {Key: "n", Value: int32(1)}})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, app.WriteExtJSONLine(&out, raw))
+	assert.Equal(t, `{"_id":{"$oid":"507f1f77bcf86cd799439011"},"n":1}`+"\n", out.String())
+}
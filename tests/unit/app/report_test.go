@@ -0,0 +1,53 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTransferReportJSON(t *testing.T) {
+	report := transfer.NewTransferReport()
+	report.Record(transfer.TableResult{Schema: "public", Table: "users", RowsAttempted: 3, RowsSucceeded: 1}, errors.New("boom"))
+
+	var out bytes.Buffer
+	require.NoError(t, app.WriteTransferReport(&out, report, "json"))
+
+	var decoded struct {
+		Tables []transfer.TableResult `json:"tables"`
+		Totals transfer.TableResult   `json:"totals"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded.Tables, 1)
+	assert.Equal(t, "boom", decoded.Tables[0].FirstError)
+	assert.Equal(t, int64(2), decoded.Totals.RowsFailed)
+}
+
+func TestWriteTransferReportText(t *testing.T) {
+	report := transfer.NewTransferReport()
+	report.Record(transfer.TableResult{Schema: "public", Table: "users", RowsAttempted: 3, RowsSucceeded: 3}, nil)
+
+	var out bytes.Buffer
+	require.NoError(t, app.WriteTransferReport(&out, report, "text"))
+	assert.Contains(t, out.String(), "public.users  succeeded")
+	assert.Contains(t, out.String(), "TOTAL")
+}
+
+func TestWriteTransferReportWithNoTables(t *testing.T) {
+	report := transfer.NewTransferReport()
+
+	var out bytes.Buffer
+	require.NoError(t, app.WriteTransferReport(&out, report, "text"))
+	assert.Equal(t, "No table data was transferred.\n", out.String())
+
+	out.Reset()
+	require.NoError(t, app.WriteTransferReport(&out, report, "json"))
+	assert.JSONEq(t, `{"tables":[],"totals":{"table":"total","status":"succeeded","rows_attempted":0,"rows_succeeded":0,"rows_failed":0}}`, out.String())
+}
@@ -0,0 +1,83 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/app"
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func summaryConfig(dbType, host, name string) *appconfig.Config {
+	return &appconfig.Config{Database: appconfig.DatabaseConfig{Type: dbType, Host: host, Port: 5432, Database: name}}
+}
+
+func TestBuildTransferSummaryPostgres(t *testing.T) {
+	summary := app.BuildTransferSummary(
+		summaryConfig("postgres", "prod-db", "shop"),
+		summaryConfig("postgres", "staging-db", "shop_copy"),
+		transfer.Options{DataOnly: true, ParallelWorkers: 8, BatchSize: 500},
+	)
+
+	assert.Contains(t, summary, "Source:  prod-db:5432/shop")
+	assert.Contains(t, summary, "Target:  staging-db:5432/shop_copy")
+	assert.Contains(t, summary, "Mode:    data only")
+	assert.Contains(t, summary, "Workers: 8")
+	assert.Contains(t, summary, "Batch:   500")
+	assert.Contains(t, summary, "conflicting rows are skipped")
+}
+
+func TestBuildTransferSummaryMongoWarnsAboutDrops(t *testing.T) {
+	summary := app.BuildTransferSummary(
+		summaryConfig("mongo", "a", "src"),
+		summaryConfig("mongo", "b", "dst"),
+		transfer.Options{ParallelWorkers: 4, BatchSize: 1000},
+	)
+
+	assert.Contains(t, summary, "Mode:    full (schema + data)")
+	assert.Contains(t, summary, "WARNING: existing target collections with the same names will be dropped")
+}
+
+func TestBuildTransferSummarySchemaOnlyHasNoWarning(t *testing.T) {
+	summary := app.BuildTransferSummary(
+		summaryConfig("postgres", "a", "src"),
+		summaryConfig("postgres", "b", "dst"),
+		transfer.Options{SchemaOnly: true},
+	)
+
+	assert.Contains(t, summary, "Mode:    schema only")
+	assert.NotContains(t, summary, "WARNING")
+}
+
+func TestBuildTransferSummaryListsChosenOptions(t *testing.T) {
+	summary := app.BuildTransferSummary(
+		summaryConfig("postgres", "prod-db", "shop"),
+		summaryConfig("postgres", "staging-db", "shop"),
+		transfer.Options{
+			ParallelWorkers:    4,
+			BatchSize:          1000,
+			ConsistentSnapshot: true,
+			VerifyChecksums:    true,
+			ExcludeColumns:     []string{"public.users.ssn"},
+			Transforms:         map[string]string{"public.users.email": "mask", "public.users.salary": "const=0"},
+		},
+	)
+
+	assert.Contains(t, summary, "Excluded columns: public.users.ssn\n")
+	assert.Contains(t, summary, "Transforms: public.users.email:mask, public.users.salary:const=0\n")
+	assert.Contains(t, summary, "Options: consistent snapshot, verify checksums\n")
+}
+
+func TestBuildTransferSummaryMongoAppendDoesNotWarnAboutDrops(t *testing.T) {
+	summary := app.BuildTransferSummary(
+		summaryConfig("mongo", "a", "src"),
+		summaryConfig("mongo", "b", "dst"),
+		transfer.Options{Append: true},
+	)
+
+	assert.Contains(t, summary, "Options: append\n")
+	assert.NotContains(t, summary, "dropped")
+	assert.Contains(t, summary, "duplicate _ids are skipped")
+}
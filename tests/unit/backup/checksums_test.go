@@ -0,0 +1,65 @@
+package backup_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumsPathSitsNextToBackup(t *testing.T) {
+	assert.Equal(t, "backup/orders_20240101.checksums.json", backup.ChecksumsPath("backup/orders_20240101.dump"))
+	assert.Equal(t, "backup/orders_20240101.checksums.json", backup.ChecksumsPath("backup/orders_20240101"))
+}
+
+func TestTableChecksumsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.checksums.json")
+	written := &backup.TableChecksums{
+		Database:  "orders",
+		CreatedAt: time.Date(2024, 3, 7, 14, 5, 9, 0, time.UTC),
+		Tables: map[string]backup.TableChecksum{
+			"public.orders":   {Rows: 120, Checksum: "9f86d081884c7d65"},
+			"sales.customers": {Rows: 0, Checksum: "e3b0c44298fc1c14"},
+		},
+		Skipped: []string{"public.audit_log"},
+	}
+	require.NoError(t, backup.WriteTableChecksums(path, written))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"public.orders": {`)
+	assert.Contains(t, string(data), `"rows": 120`)
+	assert.Contains(t, string(data), `"checksum": "9f86d081884c7d65"`)
+
+	read, err := backup.ReadTableChecksums(path)
+	require.NoError(t, err)
+	assert.Equal(t, written, read)
+}
+
+func TestDiffTableChecksums(t *testing.T) {
+	older := &backup.TableChecksums{Tables: map[string]backup.TableChecksum{
+		"public.orders":    {Rows: 10, Checksum: "a"},
+		"public.customers": {Rows: 5, Checksum: "b"},
+		"public.legacy":    {Rows: 1, Checksum: "c"},
+		"public.events":    {Rows: 3, Checksum: "d"},
+	}}
+	newer := &backup.TableChecksums{
+		Tables: map[string]backup.TableChecksum{
+			"public.orders":    {Rows: 11, Checksum: "e"},
+			"public.customers": {Rows: 5, Checksum: "b"},
+			"public.invoices":  {Rows: 2, Checksum: "f"},
+		},
+		Skipped: []string{"public.events"},
+	}
+
+	assert.Equal(t, []backup.TableChange{
+		{Table: "public.invoices", Change: backup.TableAdded, OldRows: -1, NewRows: 2},
+		{Table: "public.legacy", Change: backup.TableRemoved, OldRows: 1, NewRows: -1},
+		{Table: "public.orders", Change: backup.TableChanged, OldRows: 10, NewRows: 11},
+	}, backup.DiffTableChecksums(older, newer))
+}
@@ -0,0 +1,22 @@
+package backup_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDropDatabaseRefusesSystemDatabases(t *testing.T) {
+	for _, name := range []string{"postgres", "template0", "template1"} {
+		assert.EqualError(t, backup.CheckDropDatabase("postgres", name), "refusing to drop the postgres system database "+name)
+	}
+	for _, name := range []string{"admin", "local", "config"} {
+		assert.EqualError(t, backup.CheckDropDatabase("mongo", name), "refusing to drop the mongo system database "+name)
+	}
+	assert.Error(t, backup.CheckDropDatabase("postgres", ""))
+
+	assert.NoError(t, backup.CheckDropDatabase("postgres", "shop_test"))
+	assert.NoError(t, backup.CheckDropDatabase("mongo", "postgres"), "only the engine's own system databases are protected")
+}
@@ -0,0 +1,52 @@
+package backup_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtraArgsAppendedAfterGeneratedArgs(t *testing.T) {
+	extra := []string{"--exclude-table=audit.*", "--no-owner"}
+	args := backup.PostgresDumpArgs(postgresConfig(), "orders", "out.dump", backup.BackupOptions{ExtraArgs: extra})
+	assert.Equal(t, extra, args[len(args)-2:])
+
+	restore := backup.PostgresRestoreArgs(postgresConfig(), backup.RestoreOptions{
+		BackupPath:     "out.dump",
+		TargetDatabase: "orders",
+		ExtraArgs:      []string{"--no-acl"},
+	})
+	assert.Equal(t, "--no-acl", restore[len(restore)-1])
+
+	mongo := backup.MongoRestoreArgs(mongoConfig(), backup.RestoreOptions{
+		BackupPath: "app.archive",
+		ExtraArgs:  []string{"--numParallelCollections=2"},
+	})
+	assert.Equal(t, "--numParallelCollections=2", mongo[len(mongo)-1])
+}
+
+func TestValidateExtraArgsRejectsManagedFlags(t *testing.T) {
+	cases := []struct {
+		tool string
+		arg  string
+	}{
+		{"pg_dump", "--host=other"},
+		{"pg_dump", "-hother"},
+		{"pg_dump", "--file"},
+		{"pg_restore", "--dbname=other"},
+		{"mongodump", "--uri=mongodb://other"},
+		{"mongorestore", "--archive=other.archive"},
+	}
+
+	for _, tc := range cases {
+		err := backup.ValidateExtraArgs(tc.tool, []string{tc.arg})
+		assert.Error(t, err, "%s %s", tc.tool, tc.arg)
+	}
+}
+
+func TestValidateExtraArgsAllowsOtherFlags(t *testing.T) {
+	assert.NoError(t, backup.ValidateExtraArgs("pg_dump", []string{"--no-owner", "--exclude-table=logs", "--hostile-name"}))
+	assert.NoError(t, backup.ValidateExtraArgs("mongodump", []string{"--excludeCollection=logs", "--dbpath=x"}))
+}
@@ -0,0 +1,42 @@
+package backup_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var backupTime = time.Date(2024, 3, 7, 14, 5, 9, 0, time.FixedZone("CET", 3600))
+
+func TestRenderFilenameDefaultsToHistoricalName(t *testing.T) {
+	name, err := backup.RenderFilename("", "shop", backupTime, ".dump")
+	require.NoError(t, err)
+	assert.Equal(t, "shop_20240307_140509.dump", name)
+}
+
+func TestRenderFilenameWithCustomTemplate(t *testing.T) {
+	name, err := backup.RenderFilename("nightly-{db}-2006-01-02T150405-0700", "shop", backupTime, ".archive.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "nightly-shop-2024-03-07T140509+0100.archive.gz", name)
+}
+
+func TestRenderFilenameKeepsDatabaseNameLiteral(t *testing.T) {
+	name, err := backup.RenderFilename("{db}_2006", "Jan2006", backupTime, ".sql")
+	require.NoError(t, err)
+	assert.Equal(t, "Jan2006_2024.sql", name, "layout elements inside the database name must not be formatted")
+}
+
+func TestRenderFilenameRejectsUnsafeNames(t *testing.T) {
+	for _, template := range []string{
+		"{db}_2006-01-02T15:04:05",
+		"backups/{db}",
+		"-{db}",
+	} {
+		_, err := backup.RenderFilename(template, "shop", backupTime, ".dump")
+		assert.Error(t, err, template)
+	}
+}
@@ -0,0 +1,80 @@
+package backup_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/pkg/interactive"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeFormatAcceptsEngineFormats(t *testing.T) {
+	cases := []struct {
+		engine, format, want string
+	}{
+		{"postgres", "", "custom"},
+		{"postgres", "custom", "custom"},
+		{"postgres", " SQL ", "sql"},
+		{"postgres", "plain", "sql"},
+		{"postgres", "tar", "tar"},
+		{"postgres", "directory", "directory"},
+		{"mongo", "", "archive"},
+		{"mongo", "archive", "archive"},
+	}
+	for _, tc := range cases {
+		got, err := backup.NormalizeFormat(tc.engine, tc.format)
+		require.NoErrorf(t, err, "%s %q", tc.engine, tc.format)
+		assert.Equalf(t, tc.want, got, "%s %q", tc.engine, tc.format)
+	}
+}
+
+func TestNormalizeFormatRejectsInvalidFormats(t *testing.T) {
+	cases := []struct {
+		engine, format string
+	}{
+		{"postgres", "cusotm"},
+		{"postgres", "archive"},
+		{"mongo", "custom"},
+		{"mysql", "sql"},
+	}
+	for _, tc := range cases {
+		_, err := backup.NormalizeFormat(tc.engine, tc.format)
+		assert.Errorf(t, err, "%s %q", tc.engine, tc.format)
+	}
+
+	_, err := backup.NormalizeFormat("postgres", "cusotm")
+	assert.EqualError(t, err, `unsupported postgres backup format "cusotm" (expected custom, sql, tar, directory)`)
+}
+
+func TestSelectorSkipsFormatPromptWhenFormatIsGiven(t *testing.T) {
+	// Compression, schema only, data only, output path.
+	answers := strings.NewReader("3\nn\nn\n\n")
+	selector := interactive.NewDatabaseSelectorWithReader("postgres", answers).WithFormat("tar")
+
+	options := selector.GetBackupOptions("postgres")
+	assert.Equal(t, "tar", options.Format)
+	assert.Equal(t, 3, options.Compression)
+}
+
+func TestSelectorSuggestsConfiguredDefaults(t *testing.T) {
+	level := 2
+	// Every prompt left empty.
+	answers := strings.NewReader(strings.Repeat("\n", 8))
+	selector := interactive.NewDatabaseSelectorWithReader("postgres", answers).
+		WithDefaults(interactive.BackupDefaults{Format: "tar", Compression: &level})
+
+	options := selector.GetBackupOptions("postgres")
+	assert.Equal(t, "tar", options.Format)
+	assert.Equal(t, 2, options.Compression)
+
+	uncompressed := 0
+	selector = interactive.NewDatabaseSelectorWithReader("mongo", strings.NewReader(strings.Repeat("\n", 4))).
+		WithDefaults(interactive.BackupDefaults{Compression: &uncompressed})
+
+	options = selector.GetBackupOptions("mongo")
+	assert.Equal(t, "archive", options.Format)
+	assert.Equal(t, 0, options.Compression)
+}
@@ -0,0 +1,101 @@
+package backup_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const pgRestoreList = `;
+; Archive created at 2026-10-16 09:12:44 UTC
+;     dbname: shop
+;     TOC Entries: 9
+;     Compression: gzip
+;     Dump Version: 1.15-0
+;     Format: CUSTOM
+;     Integer: 4 bytes
+;     Offset: 8 bytes
+;     Dumped from database version: 16.4
+;     Dumped by pg_dump version: 16.4
+;
+;
+; Selected TOC Entries:
+;
+3; 0 0 ENCODING - ENCODING 
+215; 1259 16385 TABLE public orders shop_owner
+216; 1259 16384 SEQUENCE public orders_id_seq shop_owner
+217; 1255 16400 FUNCTION public order_total(integer, numeric) shop_owner
+3318; 0 16385 TABLE DATA public orders shop_owner
+3325; 0 0 SEQUENCE SET public orders_id_seq shop_owner
+3170; 2606 16390 CONSTRAINT public orders orders_pkey shop_owner
+3171; 2606 16395 FK CONSTRAINT public orders orders_customer_fkey shop_owner
+3330; 0 0 DEFAULT ACL - DEFAULT PRIVILEGES FOR TABLES shop_owner
+`
+
+func TestParsePostgresTOC(t *testing.T) {
+	toc, err := backup.ParsePostgresTOC(strings.NewReader(pgRestoreList))
+	require.NoError(t, err)
+
+	assert.Equal(t, "shop", toc.Database)
+	assert.Equal(t, "CUSTOM", toc.Format)
+	assert.Equal(t, "16.4", toc.ServerVersion)
+	require.Len(t, toc.Entries, 9)
+
+	assert.Equal(t, backup.TOCEntry{ID: 3, Type: "ENCODING", Name: "ENCODING"}, toc.Entries[0])
+	assert.Equal(t, backup.TOCEntry{ID: 215, Type: "TABLE", Schema: "public", Name: "orders", Owner: "shop_owner"}, toc.Entries[1])
+	assert.Equal(t, "order_total(integer, numeric)", toc.Entries[3].Name)
+	assert.Equal(t, backup.TOCEntry{ID: 3318, Type: "TABLE DATA", Schema: "public", Name: "orders", Owner: "shop_owner"}, toc.Entries[4])
+	assert.Equal(t, "SEQUENCE SET", toc.Entries[5].Type)
+	assert.Equal(t, "orders orders_pkey", toc.Entries[6].Name)
+	assert.Equal(t, "FK CONSTRAINT", toc.Entries[7].Type)
+	assert.Equal(t, backup.TOCEntry{ID: 3330, Type: "DEFAULT ACL", Name: "DEFAULT PRIVILEGES FOR TABLES", Owner: "shop_owner"}, toc.Entries[8])
+}
+
+func TestParsePostgresTOCRejectsGarbage(t *testing.T) {
+	_, err := backup.ParsePostgresTOC(strings.NewReader("CREATE TABLE orders (id int);\n"))
+	assert.Error(t, err)
+}
+
+func mongoArchivePrelude(t *testing.T, docs ...bson.D) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint32(0x8199e26d)))
+	for _, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		require.NoError(t, err)
+		buf.Write(raw)
+	}
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, int32(-1)))
+	// Collection data follows the prelude and is not read.
+	buf.WriteString("namespace headers and documents")
+	return buf.Bytes()
+}
+
+func TestReadMongoArchive(t *testing.T) {
+	data := mongoArchivePrelude(t,
+		bson.D{{Key: "concurrent_collections", Value: int32(4)}, {Key: "version", Value: "0.1"}, {Key: "server_version", Value: "7.0.12"}, {Key: "tool_version", Value: "100.9.4"}},
+		bson.D{{Key: "db", Value: "shop"}, {Key: "collection", Value: "orders"}, {Key: "metadata", Value: "{}"}, {Key: "size", Value: int32(0)}, {Key: "type", Value: "collection"}},
+		bson.D{{Key: "db", Value: "shop"}, {Key: "collection", Value: "open_orders"}, {Key: "metadata", Value: "{}"}, {Key: "type", Value: "view"}},
+	)
+
+	archive, err := backup.ReadMongoArchive(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "7.0.12", archive.ServerVersion)
+	assert.Equal(t, "100.9.4", archive.ToolVersion)
+	assert.Equal(t, []backup.MongoArchiveCollection{
+		{Database: "shop", Collection: "orders", Type: "collection"},
+		{Database: "shop", Collection: "open_orders", Type: "view"},
+	}, archive.Collections)
+}
+
+func TestReadMongoArchiveRejectsOtherFiles(t *testing.T) {
+	_, err := backup.ReadMongoArchive(strings.NewReader("PGDMP\x01\x0f\x00"))
+	assert.Error(t, err)
+}
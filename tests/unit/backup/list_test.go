@@ -0,0 +1,102 @@
+package backup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func databaseInfos(names ...string) []backup.DatabaseInfo {
+	infos := make([]backup.DatabaseInfo, len(names))
+	for i, name := range names {
+		infos[i] = backup.DatabaseInfo{Name: name, Type: "mongo"}
+	}
+	return infos
+}
+
+func TestCollectDatabaseInfoSkipsSlowDatabase(t *testing.T) {
+	count := func(ctx context.Context, name string) (int, error) {
+		if name == "slow" {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return len(name), nil
+	}
+
+	gathered, err := backup.CollectDatabaseInfo(context.Background(), databaseInfos("admin", "slow", "orders"), 10*time.Millisecond, count)
+	require.NoError(t, err)
+
+	require.Len(t, gathered, 3, "a single slow database must not drop the rest")
+	assert.Equal(t, 5, gathered[0].Collections)
+	assert.Equal(t, 0, gathered[1].Collections)
+	assert.Equal(t, 6, gathered[2].Collections)
+}
+
+func TestCollectDatabaseInfoReturnsPartialResultsAtDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	count := func(ctx context.Context, name string) (int, error) {
+		if name == "slow" {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 1, nil
+	}
+
+	gathered, err := backup.CollectDatabaseInfo(ctx, databaseInfos("admin", "slow", "orders"), time.Second, count)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Len(t, gathered, 1)
+	assert.Equal(t, "admin", gathered[0].Name)
+}
+
+func TestCollectDatabaseInfoIgnoresLookupErrors(t *testing.T) {
+	count := func(ctx context.Context, name string) (int, error) {
+		return 0, errors.New("not authorized")
+	}
+
+	gathered, err := backup.CollectDatabaseInfo(context.Background(), databaseInfos("tenant_a"), time.Second, count)
+	require.NoError(t, err)
+	assert.Equal(t, databaseInfos("tenant_a"), gathered)
+}
+
+func databaseNames(databases []backup.DatabaseInfo) []string {
+	names := make([]string, len(databases))
+	for i, db := range databases {
+		names[i] = db.Name
+	}
+	return names
+}
+
+func TestFilterDatabasesIncludeAndExclude(t *testing.T) {
+	databases := databaseInfos("acme_app", "acme_app_tmp", "globex", "acme_reports", "postgres")
+
+	filtered := backup.FilterDatabases(databases, config.DatabaseFilter{
+		Include: []string{"acme_*"},
+		Exclude: []string{"*_tmp"},
+	})
+	assert.Equal(t, []string{"acme_app", "acme_reports"}, databaseNames(filtered))
+
+	filtered = backup.FilterDatabases(databases, config.DatabaseFilter{Exclude: []string{"postgres", "globex"}})
+	assert.Equal(t, []string{"acme_app", "acme_app_tmp", "acme_reports"}, databaseNames(filtered))
+
+	assert.Len(t, backup.FilterDatabases(databases, config.DatabaseFilter{}), len(databases))
+}
+
+func TestDatabaseFilterRejectsBadPattern(t *testing.T) {
+	assert.Error(t, config.DatabaseFilter{Include: []string{"app_["}}.Validate())
+	assert.NoError(t, config.DatabaseFilter{Include: []string{"app_[0-9]*"}}.Validate())
+}
+
+func TestPostgresListDatabasesQueryConnectableOnly(t *testing.T) {
+	assert.NotContains(t, backup.PostgresListDatabasesQuery(false), "has_database_privilege")
+	assert.Contains(t, backup.PostgresListDatabasesQuery(true), "has_database_privilege(datname, 'CONNECT')")
+}
@@ -0,0 +1,59 @@
+package backup_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataPathKeepsExtension(t *testing.T) {
+	assert.Equal(t, "backup/orders.dump.meta.json", backup.MetadataPath("backup/orders.dump"))
+	assert.Equal(t, "backup/orders.meta.json", backup.MetadataPath("backup/orders/"))
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.dump.meta.json")
+	written := &backup.BackupMetadata{
+		Database:    "orders",
+		Engine:      "postgres",
+		Format:      "custom",
+		Compression: 6,
+		ToolVersion: "pg_dump 16.2",
+		BackupSize:  4096,
+		Checksum:    "9f86d081884c7d659a2feaa0c55ad015",
+		Location:    "backup/orders.dump",
+		StartedAt:   time.Date(2024, 3, 7, 14, 5, 9, 0, time.UTC),
+		CompletedAt: time.Date(2024, 3, 7, 14, 6, 0, 0, time.UTC),
+	}
+	require.NoError(t, backup.WriteMetadata(path, written))
+
+	read, err := backup.ReadMetadata(path)
+	require.NoError(t, err)
+	assert.Equal(t, written, read)
+}
+
+func TestRestoreFormatFromMetadataOverridesExtension(t *testing.T) {
+	options := backup.RestoreOptions{BackupPath: "orders.backup", TargetDatabase: "orders"}
+	steps := backup.PostgresRestoreSteps(postgresConfig(), options)
+	assert.Equal(t, "pg_restore", steps[len(steps)-1].Tool)
+
+	options.Format = "sql"
+	steps = backup.PostgresRestoreSteps(postgresConfig(), options)
+	assert.Equal(t, "psql", steps[len(steps)-1].Tool)
+}
+
+func TestMongoRestoreArgsGzipForCompressedArchives(t *testing.T) {
+	args := backup.MongoRestoreArgs(mongoConfig(), backup.RestoreOptions{BackupPath: "backup/analytics.archive.gz"})
+	assert.Contains(t, args, "--gzip")
+
+	args = backup.MongoRestoreArgs(mongoConfig(), backup.RestoreOptions{BackupPath: "backup/analytics.bin", Format: "archive", Compression: 1})
+	assert.Contains(t, args, "--gzip")
+
+	args = backup.MongoRestoreArgs(mongoConfig(), backup.RestoreOptions{BackupPath: "backup/analytics.archive"})
+	assert.NotContains(t, args, "--gzip")
+}
@@ -0,0 +1,49 @@
+package backup_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mongoConfig() *appconfig.Config {
+	return &appconfig.Config{
+		Database: appconfig.DatabaseConfig{
+			Type:     "mongo",
+			Host:     "replica.internal",
+			Port:     27017,
+			Database: "analytics",
+		},
+	}
+}
+
+func TestMongoDumpArgsWithSecondaryReadPreference(t *testing.T) {
+	args := backup.MongoDumpArgs(mongoConfig(), "analytics", "backup/analytics.archive", backup.BackupOptions{
+		ReadPreference: "secondary",
+	})
+
+	assert.Contains(t, args, "--uri=mongodb://replica.internal:27017/analytics?appName=dbrts&readPreference=secondary")
+	assert.Contains(t, args, "--readPreference=secondary")
+	assert.Contains(t, args, "--db=analytics")
+}
+
+func TestMongoDumpArgsAppendsReadPreferenceToExistingQuery(t *testing.T) {
+	cfg := mongoConfig()
+	cfg.Database.URI = "mongodb://replica.internal:27017/?replicaSet=rs0"
+
+	args := backup.MongoDumpArgs(cfg, "analytics", "out.archive", backup.BackupOptions{ReadPreference: "secondaryPreferred"})
+
+	assert.Contains(t, args, "--uri=mongodb://replica.internal:27017/?replicaSet=rs0&appName=dbrts&readPreference=secondaryPreferred")
+}
+
+func TestMongoDumpArgsWithoutReadPreference(t *testing.T) {
+	args := backup.MongoDumpArgs(mongoConfig(), "analytics", "out.archive", backup.BackupOptions{})
+
+	assert.Contains(t, args, "--uri=mongodb://replica.internal:27017/analytics?appName=dbrts")
+	for _, arg := range args {
+		assert.NotContains(t, arg, "readPreference")
+	}
+}
@@ -0,0 +1,151 @@
+package backup_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postgresConfig() *appconfig.Config {
+	return &appconfig.Config{Database: appconfig.DatabaseConfig{
+		Type:     "postgres",
+		Host:     "db.internal",
+		Port:     5432,
+		Username: "backup",
+		Password: "secret",
+		SSLMode:  "disable",
+	}}
+}
+
+func TestPostgresDumpArgsDefaults(t *testing.T) {
+	args := backup.PostgresDumpArgs(postgresConfig(), "orders", "backup/orders.dump", backup.BackupOptions{})
+
+	assert.Equal(t, []string{
+		"--host=db.internal",
+		"--port=5432",
+		"--username=backup",
+		"--dbname=orders",
+		"--format=custom",
+		"--file=backup/orders.dump",
+	}, args)
+}
+
+func TestClientEncodingAppliedToDumpArgsAndToolEnv(t *testing.T) {
+	cfg := postgresConfig()
+	cfg.Database.ClientEncoding = "LATIN1"
+
+	args := backup.PostgresDumpArgs(cfg, "orders", "backup/orders.sql", backup.BackupOptions{Format: "plain"})
+	assert.Contains(t, args, "--encoding=LATIN1")
+
+	assert.Contains(t, cfg.PostgresToolEnv(), "PGCLIENTENCODING=LATIN1")
+	assert.NotContains(t, cfg.GetConnectionString(), "client_encoding", "lib/pq only accepts UTF8")
+}
+
+func TestClientEncodingOmittedByDefault(t *testing.T) {
+	cfg := postgresConfig()
+
+	assert.NotContains(t, backup.PostgresDumpArgs(cfg, "orders", "out.dump", backup.BackupOptions{}), "--encoding=")
+	for _, kv := range cfg.PostgresToolEnv() {
+		assert.NotContains(t, kv, "PGCLIENTENCODING")
+	}
+}
+
+func TestPgRestoreIfExistsAccompaniesClean(t *testing.T) {
+	options := backup.RestoreOptions{BackupPath: "orders.dump", TargetDatabase: "orders", CleanFirst: true, IfExists: true}
+
+	args := backup.PostgresRestoreArgs(postgresConfig(), options)
+	assert.Contains(t, args, "--clean")
+	assert.Contains(t, args, "--if-exists")
+
+	options.IfExists = false
+	assert.NotContains(t, backup.PostgresRestoreArgs(postgresConfig(), options), "--if-exists")
+}
+
+func TestPgRestoreIfExistsOmittedWithoutClean(t *testing.T) {
+	args := backup.PostgresRestoreArgs(postgresConfig(), backup.RestoreOptions{BackupPath: "orders.dump", TargetDatabase: "orders", IfExists: true})
+
+	assert.NotContains(t, args, "--clean")
+	assert.NotContains(t, args, "--if-exists")
+}
+
+func TestPgDumpallGlobalsArgs(t *testing.T) {
+	globals := backup.GlobalsPath("backup/orders_20240101.dump")
+	assert.Equal(t, "backup/orders_20240101.globals.sql", globals)
+
+	assert.Equal(t, []string{
+		"--host=db.internal",
+		"--port=5432",
+		"--username=backup",
+		"--globals-only",
+		"--file=backup/orders_20240101.globals.sql",
+	}, backup.PgDumpallGlobalsArgs(postgresConfig(), globals))
+}
+
+func TestRestoreStepsApplyGlobalsBeforeData(t *testing.T) {
+	options := backup.RestoreOptions{BackupPath: "backup/orders.dump", TargetDatabase: "orders", ApplyGlobals: true}
+
+	steps := backup.PostgresRestoreSteps(postgresConfig(), options)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "psql", steps[0].Tool)
+	assert.Contains(t, steps[0].Args, "--file=backup/orders.globals.sql")
+	assert.Contains(t, steps[0].Args, "--dbname=postgres")
+	assert.Equal(t, "pg_restore", steps[1].Tool)
+
+	options.BackupPath = "backup/orders.sql"
+	steps = backup.PostgresRestoreSteps(postgresConfig(), options)
+	require.Len(t, steps, 2)
+	assert.Contains(t, steps[0].Args, "--file=backup/orders.globals.sql")
+	assert.Contains(t, steps[1].Args, "--file=backup/orders.sql")
+
+	options.ApplyGlobals = false
+	steps = backup.PostgresRestoreSteps(postgresConfig(), options)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "psql", steps[0].Tool)
+}
+
+func TestPgDumpJobsOnlyForDirectoryFormat(t *testing.T) {
+	args := backup.PostgresDumpArgs(postgresConfig(), "orders", "backup/orders", backup.BackupOptions{Format: "directory", Jobs: 4})
+	assert.Contains(t, args, "--jobs=4")
+
+	for _, format := range []string{"custom", "tar", "sql"} {
+		args := backup.PostgresDumpArgs(postgresConfig(), "orders", "backup/orders", backup.BackupOptions{Format: format, Jobs: 4})
+		assert.NotContains(t, args, "--jobs=4", format)
+	}
+
+	args = backup.PostgresDumpArgs(postgresConfig(), "orders", "backup/orders", backup.BackupOptions{Format: "directory", Jobs: 1})
+	assert.NotContains(t, args, "--jobs=1")
+}
+
+func TestPgRestoreJobsSkipTarAndStdin(t *testing.T) {
+	options := backup.RestoreOptions{BackupPath: "orders.dump", TargetDatabase: "orders", Jobs: 4}
+	assert.Contains(t, backup.PostgresRestoreArgs(postgresConfig(), options), "--jobs=4")
+
+	options.BackupPath = "orders.tar"
+	assert.NotContains(t, backup.PostgresRestoreArgs(postgresConfig(), options), "--jobs=4")
+
+	options.BackupPath = ""
+	assert.NotContains(t, backup.PostgresRestoreArgs(postgresConfig(), options), "--jobs=4")
+}
+
+func TestPgDumpExclusionsAreSeparateArgs(t *testing.T) {
+	args := backup.PostgresDumpArgs(postgresConfig(), "orders", "backup/orders.dump", backup.BackupOptions{
+		ExcludeTables:  []string{"audit.*", `public."Event Log"`},
+		ExcludeSchemas: []string{"staging"},
+	})
+
+	assert.Equal(t, []string{
+		"--host=db.internal",
+		"--port=5432",
+		"--username=backup",
+		"--dbname=orders",
+		"--format=custom",
+		"--file=backup/orders.dump",
+		"--exclude-schema=staging",
+		"--exclude-table=audit.*",
+		`--exclude-table=public."Event Log"`,
+	}, args)
+}
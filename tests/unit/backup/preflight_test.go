@@ -0,0 +1,58 @@
+package backup_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresPreflightArgsReuseDumpConnection(t *testing.T) {
+	cfg := postgresConfig()
+	dump := backup.PostgresDumpArgs(cfg, "inventory", "backup/inventory.dump", backup.BackupOptions{
+		ExtraArgs: []string{"--data-only"},
+	})
+	preflight := backup.PostgresPreflightArgs(cfg, "inventory")
+
+	for _, arg := range dump[:4] {
+		assert.Contains(t, preflight, arg, "connection arguments must match the real dump")
+	}
+	assert.Contains(t, preflight, "--schema-only")
+	assert.Contains(t, preflight, "--format=plain")
+	assert.Contains(t, preflight, "--exclude-schema=*")
+	assert.NotContains(t, preflight, "--data-only", "extra arguments are left out of the check")
+	for _, arg := range preflight {
+		assert.NotContains(t, arg, "--file", "the check writes nothing to disk")
+	}
+}
+
+func TestPostgresPreflightArgsHaveNoIncludePatterns(t *testing.T) {
+	// pg_dump exits with "no matching tables were found" when an include
+	// pattern matches nothing, which would fail every backup's preflight.
+	preflight := backup.PostgresPreflightArgs(postgresConfig(), "inventory")
+
+	for _, arg := range preflight {
+		for _, include := range []string{"--table", "-t", "--schema=", "-n", "--extension", "-e"} {
+			assert.False(t, strings.HasPrefix(arg, include), "%s is an include pattern that can match nothing", arg)
+		}
+	}
+}
+
+func TestMongoPreflightArgsReuseURIAndReadPreference(t *testing.T) {
+	cfg := mongoConfig()
+	preflight := backup.MongoPreflightArgs(cfg, "analytics", backup.BackupOptions{
+		ReadPreference: "secondary",
+		Compression:    6,
+		ExtraArgs:      []string{"--numParallelCollections=8"},
+	})
+
+	assert.Equal(t, []string{
+		"--uri=mongodb://replica.internal:27017/analytics?appName=dbrts&readPreference=secondary",
+		"--archive",
+		"--db=analytics",
+		"--readPreference=secondary",
+		"--collection=dbrts_preflight_check",
+	}, preflight)
+}
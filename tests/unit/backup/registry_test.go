@@ -0,0 +1,82 @@
+package backup_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/pkg/interactive"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func touch(t *testing.T, path string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte("dump"), 0o644))
+	return path
+}
+
+func seedRegistry(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	registry := filepath.Join(dir, "registry.json")
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	entries := []backup.RegistryEntry{
+		{Engine: "postgres", Database: "orders", Path: touch(t, filepath.Join(dir, "orders_1.dump")), CreatedAt: base},
+		{Engine: "mongo", Database: "events", Path: touch(t, filepath.Join(dir, "events_1.archive")), CreatedAt: base.Add(time.Hour)},
+		{Engine: "postgres", Database: "billing", Path: touch(t, filepath.Join(dir, "billing_1.sql")), CreatedAt: base.Add(2 * time.Hour)},
+		{Engine: "postgres", Database: "gone", Path: filepath.Join(dir, "deleted.dump"), CreatedAt: base.Add(3 * time.Hour)},
+	}
+	for _, entry := range entries {
+		require.NoError(t, backup.RecordBackup(registry, entry))
+	}
+
+	return registry, dir
+}
+
+func TestListBackupsFiltersByEngineNewestFirst(t *testing.T) {
+	registry, _ := seedRegistry(t)
+
+	entries, err := backup.ListBackups(registry, "postgres")
+	require.NoError(t, err)
+
+	require.Len(t, entries, 2, "mongo entries and deleted files are excluded")
+	assert.Equal(t, "billing", entries[0].Database)
+	assert.Equal(t, "plain", entries[0].Format)
+	assert.Equal(t, "orders", entries[1].Database)
+	assert.Equal(t, "custom", entries[1].Format)
+}
+
+func TestListBackupsWithoutRegistry(t *testing.T) {
+	entries, err := backup.ListBackups(filepath.Join(t.TempDir(), "registry.json"), "postgres")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRegistrySelectionPrefillsRestoreOptions(t *testing.T) {
+	registry, dir := seedRegistry(t)
+
+	entries, err := backup.ListBackups(registry, "postgres")
+	require.NoError(t, err)
+
+	// Pick the second backup, keep the suggested database name, then answer
+	// the create/clean/exit-on-error prompts.
+	answers := strings.NewReader("2\n\ny\nn\n\n")
+	selector := interactive.NewDatabaseSelectorWithReader("postgres", answers)
+
+	chosen, err := selector.SelectBackup(entries)
+	require.NoError(t, err)
+
+	options := selector.GetRestoreOptionsFrom("postgres", chosen)
+	assert.Equal(t, filepath.Join(dir, "orders_1.dump"), options.BackupPath)
+	assert.Equal(t, "orders", options.TargetDatabase)
+	assert.True(t, options.CreateDatabase)
+	assert.False(t, options.CleanFirst)
+	assert.True(t, options.ExitOnError)
+}
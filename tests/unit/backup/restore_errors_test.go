@@ -0,0 +1,77 @@
+package backup_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pgRestoreOutput = `pg_restore: while PROCESSING TOC:
+pg_restore: from TOC entry 215; 1259 16385 TABLE users app
+pg_restore: error: could not execute query: ERROR:  relation "users" already exists
+Command was: CREATE TABLE public.users (
+    id integer NOT NULL
+);
+pg_restore: from TOC entry 3321; 2606 16390 CONSTRAINT users users_pkey app
+pg_restore: error: could not execute query: ERROR:  multiple primary keys for table "users" are not allowed
+Command was: ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+pg_restore: [archiver (db)] could not execute query: ERROR:  function "touch_updated_at" already exists with same argument types
+pg_restore: warning: errors ignored on restore: 3
+`
+
+func TestClassifyRestoreLine(t *testing.T) {
+	kind, message := backup.ClassifyRestoreLine("pg_restore", `pg_restore: error: could not execute query: ERROR:  relation "users" already exists`)
+	assert.Equal(t, backup.RestoreLineExisting, kind)
+	assert.Equal(t, `relation "users" already exists`, message)
+
+	kind, message = backup.ClassifyRestoreLine("pg_restore", `pg_restore: error: COPY failed for table "orders": ERROR:  duplicate key value violates unique constraint "orders_pkey"`)
+	assert.Equal(t, backup.RestoreLineFailed, kind)
+	assert.Equal(t, `duplicate key value violates unique constraint "orders_pkey"`, message)
+
+	kind, _ = backup.ClassifyRestoreLine("psql", `psql:dump.sql:42: ERROR:  type "mood" already exists`)
+	assert.Equal(t, backup.RestoreLineExisting, kind)
+
+	kind, _ = backup.ClassifyRestoreLine("pg_restore", "pg_restore: warning: errors ignored on restore: 3")
+	assert.Equal(t, backup.RestoreLineOther, kind)
+
+	kind, _ = backup.ClassifyRestoreLine("mongorestore", "2026-10-16T10:00:00.000+0000\tcontinuing through error: E11000 duplicate key error collection: app.users index: _id_ dup key: { _id: 1 }")
+	assert.Equal(t, backup.RestoreLineExisting, kind)
+
+	kind, _ = backup.ClassifyRestoreLine("mongorestore", "2026-10-16T10:00:00.000+0000\tFailed: app.users: error creating indexes for app.users: Index with name: email_1 already exists with different options")
+	assert.Equal(t, backup.RestoreLineFailed, kind)
+}
+
+func TestRestoreErrorsForgivesExistingObjects(t *testing.T) {
+	tally := &backup.RestoreErrors{Tool: "pg_restore"}
+	_, err := io.WriteString(tally, pgRestoreOutput)
+	require.NoError(t, err)
+
+	assert.NoError(t, tally.Result(errors.New("exit status 1")))
+	assert.Equal(t, 3, tally.Existing)
+	assert.Empty(t, tally.Failed)
+}
+
+func TestRestoreErrorsFailsOnOtherErrors(t *testing.T) {
+	tally := &backup.RestoreErrors{Tool: "pg_restore"}
+	io.WriteString(tally, pgRestoreOutput)
+	// A last line without a newline is still classified.
+	io.WriteString(tally, `pg_restore: error: could not execute query: ERROR:  permission denied for schema audit`)
+
+	err := tally.Result(errors.New("exit status 1"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 already present on the target, 1 failed")
+	assert.Contains(t, err.Error(), "permission denied for schema audit")
+}
+
+func TestRestoreErrorsKeepsToolFailureWithoutErrorLines(t *testing.T) {
+	tally := &backup.RestoreErrors{Tool: "pg_restore"}
+	io.WriteString(tally, "pg_restore: error: connection to server on socket failed: No such file or directory\n")
+
+	assert.EqualError(t, tally.Result(errors.New("exit status 1")), "exit status 1")
+}
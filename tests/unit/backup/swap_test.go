@@ -0,0 +1,57 @@
+package backup_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var swapTime = time.Date(2026, 10, 16, 14, 30, 5, 0, time.UTC)
+
+func TestNewAtomicSwapNames(t *testing.T) {
+	swap := backup.NewAtomicSwap("shop", swapTime)
+
+	assert.Equal(t, "shop", swap.Target)
+	assert.Equal(t, "shop_swap_20261016143005", swap.Temp)
+	assert.Equal(t, "shop_old", swap.Old)
+}
+
+func TestNewAtomicSwapKeepsNamesWithinIdentifierLimit(t *testing.T) {
+	target := strings.Repeat("é", 40) // 80 bytes
+	swap := backup.NewAtomicSwap(target, swapTime)
+
+	assert.LessOrEqual(t, len(swap.Temp), 63)
+	assert.LessOrEqual(t, len(swap.Old), 63)
+	assert.True(t, strings.HasSuffix(swap.Temp, "_swap_20261016143005"))
+	assert.True(t, strings.HasSuffix(swap.Old, "_old"))
+	assert.True(t, strings.HasPrefix(swap.Old, "éé"))
+}
+
+func TestAtomicSwapStatementSequence(t *testing.T) {
+	swap := backup.NewAtomicSwap("shop's", swapTime)
+
+	assert.Equal(t, []string{
+		`ALTER DATABASE "shop's" WITH ALLOW_CONNECTIONS false`,
+		`SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = 'shop''s' AND pid <> pg_backend_pid()`,
+	}, swap.LockStatements())
+
+	assert.Equal(t, []string{
+		`ALTER DATABASE "shop's" RENAME TO "shop's_old"`,
+		`ALTER DATABASE "shop's_swap_20261016143005" RENAME TO "shop's"`,
+	}, swap.RenameStatements(true))
+
+	assert.Equal(t, `ALTER DATABASE "shop's_old" WITH ALLOW_CONNECTIONS true`, swap.UnlockStatement(true))
+	assert.Equal(t, `ALTER DATABASE "shop's" WITH ALLOW_CONNECTIONS true`, swap.UnlockStatement(false))
+}
+
+func TestAtomicSwapIntoMissingTargetOnlyRenamesTemp(t *testing.T) {
+	swap := backup.NewAtomicSwap("shop", swapTime)
+
+	assert.Equal(t, []string{
+		`ALTER DATABASE "shop_swap_20261016143005" RENAME TO "shop"`,
+	}, swap.RenameStatements(false))
+}
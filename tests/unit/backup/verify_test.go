@@ -0,0 +1,78 @@
+package backup_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerifyBackupMatchesAndDetectsCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.dump")
+	require.NoError(t, os.WriteFile(path, []byte("PGDMP archive"), 0o644))
+	require.NoError(t, backup.WriteMetadata(backup.MetadataPath(path), &backup.BackupMetadata{
+		Engine:   "postgres",
+		Checksum: sha256Hex("PGDMP archive"),
+	}))
+
+	result, err := backup.VerifyBackup(path)
+	require.NoError(t, err)
+	assert.True(t, result.OK())
+	assert.Equal(t, int64(len("PGDMP archive")), result.Size)
+
+	require.NoError(t, os.WriteFile(path, []byte("PGDMP archivf"), 0o644))
+	result, err = backup.VerifyBackup(path)
+	assert.ErrorIs(t, err, backup.ErrChecksumMismatch)
+	require.NotNil(t, result)
+	assert.False(t, result.OK())
+}
+
+func TestVerifyBackupMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := backup.VerifyBackup(filepath.Join(dir, "gone.dump"))
+	assert.ErrorIs(t, err, backup.ErrBackupMissing)
+
+	path := filepath.Join(dir, "orders.dump")
+	require.NoError(t, os.WriteFile(path, []byte("PGDMP"), 0o644))
+	_, err = backup.VerifyBackup(path)
+	assert.ErrorIs(t, err, backup.ErrBackupMissing, "no sidecar")
+	assert.NotErrorIs(t, err, backup.ErrChecksumMismatch)
+}
+
+func TestVerifyDirectoryBackupNamesChangedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders")
+	require.NoError(t, os.MkdirAll(path, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "toc.dat"), []byte("toc"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "3001.dat.gz"), []byte("rows, damaged"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "extra.dat"), []byte("stray"), 0o644))
+
+	require.NoError(t, backup.WriteMetadata(backup.MetadataPath(path), &backup.BackupMetadata{
+		Engine:   "postgres",
+		Format:   "directory",
+		Checksum: "recorded",
+		Files: map[string]string{
+			"toc.dat":     sha256Hex("toc"),
+			"3001.dat.gz": sha256Hex("rows"),
+			"3002.dat.gz": sha256Hex("more rows"),
+		},
+	}))
+
+	result, err := backup.VerifyBackup(path)
+	assert.ErrorIs(t, err, backup.ErrChecksumMismatch)
+	require.NotNil(t, result)
+	assert.Equal(t, []string{"3001.dat.gz"}, result.Changed)
+	assert.Equal(t, []string{"3002.dat.gz"}, result.Missing)
+	assert.Equal(t, []string{"extra.dat"}, result.Added)
+}
@@ -0,0 +1,63 @@
+package backup_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	cases := []struct {
+		output string
+		major  int
+		minor  int
+	}{
+		{"pg_dump (PostgreSQL) 16.2 (Debian 16.2-1.pgdg120+2)\n", 16, 2},
+		{"pg_restore (PostgreSQL) 9.6.24", 9, 6},
+		{"16.1 (Ubuntu 16.1-1.pgdg22.04+1)", 16, 1},
+		{"17beta1", 17, 0},
+		{"mongodump version: 100.9.4\ngit version: abc123\nGo version: go1.21", 100, 9},
+		{"7.0.5", 7, 0},
+	}
+
+	for _, tc := range cases {
+		version, err := backup.ParseVersion(tc.output)
+		require.NoError(t, err, tc.output)
+		assert.Equal(t, tc.major, version.Major, tc.output)
+		assert.Equal(t, tc.minor, version.Minor, tc.output)
+	}
+
+	_, err := backup.ParseVersion("command not found")
+	assert.Error(t, err)
+}
+
+func TestVersionCheckSkewed(t *testing.T) {
+	v := func(s string) backup.Version {
+		version, err := backup.ParseVersion(s)
+		require.NoError(t, err)
+		return version
+	}
+
+	cases := []struct {
+		engine string
+		client string
+		server string
+		skewed bool
+	}{
+		{"postgres", "15.6", "16.2", true},
+		{"postgres", "16.0", "16.2", false},
+		{"postgres", "17.1", "16.2", false},
+		{"postgres", "9.6.24", "10.1", true},
+		{"mongo", "100.9.4", "7.0.5", false},
+		{"mongo", "4.2.8", "5.0.1", true},
+		{"mongo", "5.0.1", "4.4.0", false},
+	}
+
+	for _, tc := range cases {
+		check := backup.VersionCheck{Tool: "tool", Engine: tc.engine, Client: v(tc.client), Server: v(tc.server)}
+		assert.Equal(t, tc.skewed, check.Skewed(), "%s client %s server %s", tc.engine, tc.client, tc.server)
+	}
+}
@@ -0,0 +1,60 @@
+package concurrency_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/concurrency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterBoundsConcurrentHolders(t *testing.T) {
+	limiter := concurrency.NewLimiter(3)
+
+	var active, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := limiter.Do(context.Background(), func() error {
+				current := atomic.AddInt32(&active, 1)
+				for {
+					observed := atomic.LoadInt32(&peak)
+					if current <= observed || atomic.CompareAndSwapInt32(&peak, observed, current) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&active, -1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak, int32(3))
+	assert.Greater(t, peak, int32(0))
+}
+
+func TestLimiterAcquireHonoursContext(t *testing.T) {
+	limiter := concurrency.NewLimiter(1)
+	require.NoError(t, limiter.Acquire(context.Background()))
+	defer limiter.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, limiter.Acquire(ctx), context.DeadlineExceeded)
+}
+
+func TestNewLimiterDefaultsToNumCPU(t *testing.T) {
+	assert.Equal(t, runtime.NumCPU(), concurrency.NewLimiter(0).Cap())
+}
@@ -0,0 +1,54 @@
+package concurrency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/concurrency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewThrottleDisabledWithoutLimits(t *testing.T) {
+	throttle := concurrency.NewThrottle(0, 0)
+	assert.Nil(t, throttle)
+	assert.NoError(t, throttle.Wait(context.Background(), 1_000_000, 1<<30))
+}
+
+func TestThrottlePacesRowsToRate(t *testing.T) {
+	// 1000 rows/s with a one-second burst: the first 1000 rows are free and
+	// the next 500 take half a second.
+	throttle := concurrency.NewThrottle(1000, 0)
+
+	started := time.Now()
+	for i := 0; i < 30; i++ {
+		require.NoError(t, throttle.Wait(context.Background(), 50, 0))
+	}
+	elapsed := time.Since(started)
+
+	assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+	assert.Less(t, elapsed, 900*time.Millisecond)
+}
+
+func TestThrottlePacesBytesBeyondBurst(t *testing.T) {
+	// A single request larger than the bucket is split rather than rejected.
+	throttle := concurrency.NewThrottle(0, 1)
+
+	started := time.Now()
+	require.NoError(t, throttle.Wait(context.Background(), 0, 1536*1024))
+	elapsed := time.Since(started)
+
+	assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+	assert.Less(t, elapsed, 900*time.Millisecond)
+}
+
+func TestThrottleWaitStopsOnCancel(t *testing.T) {
+	throttle := concurrency.NewThrottle(1, 0)
+	require.NoError(t, throttle.Wait(context.Background(), 1, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, throttle.Wait(ctx, 1, 0))
+}
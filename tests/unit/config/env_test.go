@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envLookup(values map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	lookup := envLookup(map[string]string{"PGHOST": "db.internal", "EMPTY": ""})
+
+	for _, tc := range []struct {
+		value, want string
+	}{
+		{"${PGHOST}", "db.internal"},
+		{"${PGHOST:-localhost}", "db.internal"},
+		{"${PGPORT:-5432}", "5432"},
+		{"${EMPTY:-fallback}", "fallback"},
+		{"${EMPTY}", ""},
+		{"${MISSING:-}", ""},
+		{"postgres://${PGHOST}:${PGPORT:-5432}/app", "postgres://db.internal:5432/app"},
+		{"pa$$word", "pa$$word"},
+		{"$PGHOST", "$PGHOST"},
+		{"${not a reference}", "${not a reference}"},
+	} {
+		got, err := appconfig.ExpandEnv(tc.value, lookup)
+		require.NoError(t, err, tc.value)
+		assert.Equal(t, tc.want, got, tc.value)
+	}
+
+	_, err := appconfig.ExpandEnv("${PGHOST}/${PGPASSWORD}", lookup)
+	assert.EqualError(t, err, "environment variable PGPASSWORD is not set")
+}
+
+func TestLoadConfigExpandsEnvironment(t *testing.T) {
+	t.Setenv("DBRTS_TEST_HOST", "db.internal")
+	t.Setenv("DBRTS_TEST_PASSWORD", "s3cret")
+
+	path := filepath.Join(t.TempDir(), "env.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`database:
+  type: ${DBRTS_TEST_TYPE:-postgres}
+  host: ${DBRTS_TEST_HOST}
+  port: 5432
+  username: app
+  password: ${DBRTS_TEST_PASSWORD}
+  database: ${DBRTS_TEST_DATABASE:-app}
+`), 0o644))
+
+	cfg, err := appconfig.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", cfg.Database.Type)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, "s3cret", cfg.Database.Password)
+	assert.Equal(t, "app", cfg.Database.Database)
+}
+
+func TestLoadConfigNamesFieldOfUnsetVariable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("database:\n  host: localhost\n  password: ${DBRTS_TEST_UNSET_PASSWORD}\n"), 0o644))
+
+	_, err := appconfig.LoadConfig(path)
+	assert.EqualError(t, err, "password: environment variable DBRTS_TEST_UNSET_PASSWORD is not set")
+}
+
+func TestLoadConfigExpandsToolPaths(t *testing.T) {
+	t.Setenv("DBRTS_TEST_PG_BIN", "/usr/lib/postgresql/16/bin")
+
+	path := filepath.Join(t.TempDir(), "env.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`database:
+  host: localhost
+tools:
+  pg_dump_path: ${DBRTS_TEST_PG_BIN}/pg_dump
+  psql_path: ${DBRTS_TEST_UNSET_BIN:-/usr/bin}/psql
+`), 0o644))
+
+	cfg, err := appconfig.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/lib/postgresql/16/bin/pg_dump", cfg.Tools.PgDump)
+	assert.Equal(t, "/usr/bin/psql", cfg.Tools.Psql)
+
+	require.NoError(t, os.WriteFile(path, []byte("database:\n  host: localhost\ntools:\n  pg_restore_path: ${DBRTS_TEST_UNSET_BIN}/pg_restore\n"), 0o644))
+	_, err = appconfig.LoadConfig(path)
+	assert.EqualError(t, err, "tools.pg_restore_path: environment variable DBRTS_TEST_UNSET_BIN is not set")
+}
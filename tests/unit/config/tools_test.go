@@ -0,0 +1,73 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolPathFallsBackToPath(t *testing.T) {
+	cfg := &appconfig.Config{}
+
+	path, err := cfg.ToolPath("pg_dump")
+	require.NoError(t, err)
+	assert.Equal(t, "pg_dump", path)
+}
+
+func TestToolPathUsesConfiguredBinary(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "pg_dump")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\n"), 0o755))
+	cfg := &appconfig.Config{Tools: appconfig.ToolPaths{PgDump: binary}}
+
+	path, err := cfg.ToolPath("pg_dump")
+	require.NoError(t, err)
+	assert.Equal(t, binary, path)
+
+	// Other tools are unaffected.
+	path, err = cfg.ToolPath("pg_restore")
+	require.NoError(t, err)
+	assert.Equal(t, "pg_restore", path)
+}
+
+func TestToolPathRejectsUnusableBinary(t *testing.T) {
+	dir := t.TempDir()
+
+	missing := &appconfig.Config{Tools: appconfig.ToolPaths{PgRestore: filepath.Join(dir, "pg_restore")}}
+	_, err := missing.ToolPath("pg_restore")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tools.pg_restore_path")
+	assert.Contains(t, err.Error(), "does not exist")
+
+	directory := &appconfig.Config{Tools: appconfig.ToolPaths{Mongodump: dir}}
+	_, err = directory.ToolPath("mongodump")
+	assert.ErrorContains(t, err, "is a directory")
+
+	if runtime.GOOS != "windows" {
+		plain := filepath.Join(dir, "psql")
+		require.NoError(t, os.WriteFile(plain, []byte("not a binary"), 0o644))
+		cfg := &appconfig.Config{Tools: appconfig.ToolPaths{Psql: plain}}
+		_, err = cfg.ToolPath("psql")
+		assert.ErrorContains(t, err, "is not executable")
+	}
+}
+
+func TestLoadConfigReadsToolPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`database:
+  host: localhost
+tools:
+  pg_dump_path: /usr/lib/postgresql/16/bin/pg_dump
+  pg_restore_path: /usr/lib/postgresql/16/bin/pg_restore
+`), 0o644))
+
+	cfg, err := appconfig.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/lib/postgresql/16/bin/pg_dump", cfg.Tools.PgDump)
+	assert.Equal(t, "/usr/lib/postgresql/16/bin/pg_restore", cfg.Tools.PgRestore)
+}
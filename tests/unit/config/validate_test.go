@@ -0,0 +1,57 @@
+package config_test
+
+import (
+	"testing"
+
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func failedChecks(checks []appconfig.ValidationCheck) map[string]bool {
+	failed := make(map[string]bool)
+	for _, check := range checks {
+		if !check.Passed() {
+			failed[check.Field] = check.Required
+		}
+	}
+	return failed
+}
+
+func TestChecksPassCompletePostgresConfig(t *testing.T) {
+	cfg := &appconfig.Config{Database: appconfig.DatabaseConfig{
+		Type: "postgres", Host: "db.internal", Port: 5432, Username: "app", Password: "secret", Database: "shop", SSLMode: "verify-full",
+	}}
+
+	assert.Empty(t, failedChecks(cfg.Checks()))
+}
+
+func TestChecksReportEachPostgresProblem(t *testing.T) {
+	cfg := &appconfig.Config{Database: appconfig.DatabaseConfig{
+		Type: "postgres", Host: "db.internal", Port: 70000, SSLMode: "strict",
+	}}
+
+	assert.Equal(t, map[string]bool{
+		"port":     true,
+		"username": true,
+		"database": true,
+		"sslmode":  true,
+		"password": false,
+	}, failedChecks(cfg.Checks()))
+}
+
+func TestChecksMongoNeedsURIOrHostAndDatabase(t *testing.T) {
+	withURI := &appconfig.Config{Database: appconfig.DatabaseConfig{Type: "mongo", URI: "mongodb+srv://cluster.example.net"}}
+	assert.Empty(t, failedChecks(withURI.Checks()))
+
+	hostOnly := &appconfig.Config{Database: appconfig.DatabaseConfig{Type: "mongo", Host: "localhost", Port: 27017}}
+	assert.Equal(t, map[string]bool{"database": true}, failedChecks(hostOnly.Checks()))
+
+	badURI := &appconfig.Config{Database: appconfig.DatabaseConfig{Type: "mongo", URI: "localhost:27017"}}
+	assert.Equal(t, map[string]bool{"uri": true}, failedChecks(badURI.Checks()))
+}
+
+func TestChecksRejectUnknownType(t *testing.T) {
+	cfg := &appconfig.Config{Database: appconfig.DatabaseConfig{Type: "mysql", Host: "localhost"}}
+	assert.Equal(t, map[string]bool{"type": true}, failedChecks(cfg.Checks()))
+}
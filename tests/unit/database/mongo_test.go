@@ -0,0 +1,90 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func mongoConfig(uri, host string) *config.Config {
+	return &config.Config{Database: config.DatabaseConfig{
+		Type:     "mongo",
+		URI:      uri,
+		Host:     host,
+		Port:     27017,
+		Database: "app",
+	}}
+}
+
+// fakeConnector fails for URIs containing a bad marker and otherwise returns
+// an unconnected client, recording every URI it was asked for.
+type fakeConnector struct {
+	bad   string
+	tried []string
+}
+
+func (f *fakeConnector) connect(ctx context.Context, uri string) (*mongo.Client, error) {
+	f.tried = append(f.tried, uri)
+	if strings.Contains(uri, f.bad) {
+		return nil, errors.New("auth failed")
+	}
+	return mongo.Connect(ctx, options.Client().ApplyURI(uri))
+}
+
+func TestMongoEndpointsFallBackToHostFields(t *testing.T) {
+	endpoints := database.MongoEndpoints(mongoConfig("mongodb://stale.internal:27017/app", "db.internal"))
+
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, "uri", endpoints[0].Source)
+	assert.Contains(t, endpoints[0].URI, "stale.internal")
+	assert.Equal(t, "host fields", endpoints[1].Source)
+	assert.Contains(t, endpoints[1].URI, "mongodb://db.internal:27017/app")
+	assert.Contains(t, endpoints[1].URI, "appName=")
+}
+
+func TestMongoEndpointsWithoutHostFields(t *testing.T) {
+	assert.Len(t, database.MongoEndpoints(mongoConfig("mongodb://stale.internal:27017/app", "")), 1)
+	assert.Len(t, database.MongoEndpoints(mongoConfig("", "db.internal")), 1)
+}
+
+func TestConnectMongoFallsBackWhenURIFails(t *testing.T) {
+	connector := &fakeConnector{bad: "stale.internal"}
+	cfg := mongoConfig("mongodb://stale.internal:27017/app", "db.internal")
+
+	client, err := database.ConnectMongo(context.Background(), cfg, connector.connect, logger.NewLogger(false))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+
+	require.Len(t, connector.tried, 2)
+	assert.Contains(t, connector.tried[1], "db.internal")
+}
+
+func TestConnectMongoReturnsURIErrorWhenEverythingFails(t *testing.T) {
+	connector := &fakeConnector{bad: "mongodb://"}
+	cfg := mongoConfig("mongodb://stale.internal:27017/app", "db.internal")
+
+	_, err := database.ConnectMongo(context.Background(), cfg, connector.connect, logger.NewLogger(false))
+	assert.EqualError(t, err, "auth failed")
+	assert.Len(t, connector.tried, 2)
+}
+
+func TestConnectMongoStopsAtWorkingURI(t *testing.T) {
+	connector := &fakeConnector{bad: "unused"}
+	cfg := mongoConfig("mongodb://primary.internal:27017/app", "db.internal")
+
+	client, err := database.ConnectMongo(context.Background(), cfg, connector.connect, logger.NewLogger(false))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+
+	assert.Len(t, connector.tried, 1)
+}
@@ -0,0 +1,36 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/engine"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilitiesOfPostgresAndMongo(t *testing.T) {
+	postgres, err := engine.Capabilities("postgres")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"custom", "sql", "tar", "directory"}, postgres.Formats)
+	assert.Equal(t, "custom", postgres.DefaultFormat)
+	assert.Equal(t, 6, postgres.DefaultCompression)
+	assert.True(t, postgres.SupportsSchemaOnly)
+	assert.True(t, postgres.SupportsSSL)
+	assert.True(t, postgres.SupportsFormat("directory"))
+
+	mongo, err := engine.Capabilities("mongo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive"}, mongo.Formats)
+	assert.Equal(t, "archive", mongo.DefaultFormat)
+	assert.Equal(t, 1, mongo.DefaultCompression)
+	assert.False(t, mongo.SupportsSchemaOnly)
+	assert.False(t, mongo.SupportsSSL)
+	assert.False(t, mongo.SupportsFormat("custom"))
+	assert.Equal(t, "indexes", mongo.SchemaNoun)
+}
+
+func TestCapabilitiesRejectsUnknownEngine(t *testing.T) {
+	_, err := engine.Capabilities("mysql")
+	assert.Error(t, err)
+}
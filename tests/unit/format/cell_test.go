@@ -0,0 +1,48 @@
+package format_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/format"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultCellOptions(t *testing.T) {
+	opts := format.DefaultCellOptions()
+
+	assert.Equal(t, "NULL", opts.Cell(nil))
+	assert.Equal(t, "true", opts.Cell(true))
+	assert.Equal(t, "false", opts.Cell(false))
+	assert.Equal(t, "42", opts.Cell(int64(42)))
+	assert.Equal(t, "raw", opts.Cell([]byte("raw")))
+	assert.Equal(t, "2024-03-01T12:00:00Z", opts.Cell(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestCellNullString(t *testing.T) {
+	assert.Equal(t, `\N`, format.CellOptions{NullString: `\N`}.Cell(nil))
+	assert.Equal(t, "", format.CellOptions{NullString: ""}.Cell(nil))
+}
+
+func TestCellBoolFormats(t *testing.T) {
+	cases := map[string][2]string{
+		format.BoolTrueFalse: {"true", "false"},
+		format.BoolTF:        {"t", "f"},
+		format.BoolOneZero:   {"1", "0"},
+		format.BoolYesNo:     {"yes", "no"},
+		"":                   {"true", "false"},
+	}
+
+	for boolFormat, expected := range cases {
+		opts := format.CellOptions{BoolFormat: boolFormat}
+		assert.Equal(t, expected[0], opts.Cell(true), boolFormat)
+		assert.Equal(t, expected[1], opts.Cell(false), boolFormat)
+	}
+}
+
+func TestValidateBoolFormat(t *testing.T) {
+	assert.NoError(t, format.ValidateBoolFormat("t/f"))
+	assert.NoError(t, format.ValidateBoolFormat(""))
+	assert.Error(t, format.ValidateBoolFormat("on/off"))
+}
@@ -0,0 +1,110 @@
+package hook_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/hook"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell syntax")
+	}
+}
+
+func readEnvDump(t *testing.T, path string) map[string]string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	env := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		env[key] = value
+	}
+	return env
+}
+
+func TestHooksReceiveOperationEnvironment(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	pre := filepath.Join(dir, "pre.env")
+	post := filepath.Join(dir, "post.env")
+
+	hooks := hook.Hooks{
+		Pre:    "env | grep ^DBRTS_ > " + pre,
+		Post:   "env | grep ^DBRTS_ > " + post,
+		Logger: logger.NewLogger(false),
+	}
+
+	err := hooks.Around("backup", "orders", func() (string, error) {
+		return "/backups/orders.dump", nil
+	})
+	require.NoError(t, err)
+
+	preEnv := readEnvDump(t, pre)
+	assert.Equal(t, "backup", preEnv["DBRTS_OPERATION"])
+	assert.Equal(t, "orders", preEnv["DBRTS_DATABASE"])
+	assert.Equal(t, hook.StatusStarting, preEnv["DBRTS_STATUS"])
+
+	postEnv := readEnvDump(t, post)
+	assert.Equal(t, hook.StatusSuccess, postEnv["DBRTS_STATUS"])
+	assert.Equal(t, "/backups/orders.dump", postEnv["DBRTS_PATH"])
+}
+
+func TestPostHookSeesFailure(t *testing.T) {
+	skipWithoutShell(t)
+	post := filepath.Join(t.TempDir(), "post.env")
+
+	hooks := hook.Hooks{Post: "env | grep ^DBRTS_ > " + post, Logger: logger.NewLogger(false)}
+	opErr := errors.New("pg_dump failed")
+
+	err := hooks.Around("backup", "orders", func() (string, error) { return "", opErr })
+	assert.ErrorIs(t, err, opErr)
+
+	postEnv := readEnvDump(t, post)
+	assert.Equal(t, hook.StatusFailure, postEnv["DBRTS_STATUS"])
+	assert.Equal(t, "pg_dump failed", postEnv["DBRTS_ERROR"])
+}
+
+func TestFailingPreHookAbortsOperation(t *testing.T) {
+	skipWithoutShell(t)
+
+	ran := false
+	hooks := hook.Hooks{Pre: "exit 3", Logger: logger.NewLogger(false)}
+
+	err := hooks.Around("restore", "orders", func() (string, error) {
+		ran = true
+		return "", nil
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "restore aborted")
+	assert.False(t, ran)
+}
+
+func TestFailingPreHookCanBeIgnored(t *testing.T) {
+	skipWithoutShell(t)
+
+	ran := false
+	hooks := hook.Hooks{Pre: "exit 3", ContinueOnPreFailure: true, Logger: logger.NewLogger(false)}
+
+	err := hooks.Around("transfer", "orders", func() (string, error) {
+		ran = true
+		return "", nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, ran)
+}
@@ -0,0 +1,51 @@
+package humanize_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/humanize"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytesAcrossUnitBoundaries(t *testing.T) {
+	cases := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 kB"},
+		{1536, "1.5 kB"},
+		{1024*1024 - 1, "1.0 MB"},
+		{1024 * 1024, "1.0 MB"},
+		{1288490189, "1.2 GB"},
+		{5 * 1024 * 1024 * 1024 * 1024, "5.0 TB"},
+		{-2048, "-2.0 kB"},
+	}
+	for _, tc := range cases {
+		assert.Equalf(t, tc.want, humanize.Bytes(tc.n), "%d bytes", tc.n)
+	}
+}
+
+func TestDurationAcrossUnitBoundaries(t *testing.T) {
+	cases := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0ms"},
+		{850 * time.Millisecond, "850ms"},
+		{999_600 * time.Microsecond, "1s"},
+		{42 * time.Second, "42s"},
+		{59_600 * time.Millisecond, "1m 0s"},
+		{3*time.Minute + 12*time.Second, "3m 12s"},
+		{time.Hour, "1h 0m"},
+		{2*time.Hour + 5*time.Minute + 30*time.Second, "2h 5m"},
+		{28 * time.Hour, "1d 4h"},
+		{-90 * time.Second, "-1m 30s"},
+	}
+	for _, tc := range cases {
+		assert.Equalf(t, tc.want, humanize.Duration(tc.d), "%s", tc.d)
+	}
+}
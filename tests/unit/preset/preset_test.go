@@ -0,0 +1,61 @@
+package preset_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/preset"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	original := preset.TransferPreset{DataOnly: true, Workers: 8, BatchSize: 250, SplitThreshold: 1_000_000}
+
+	require.NoError(t, preset.Save(dir, "nightly-sync", original))
+
+	data, err := os.ReadFile(filepath.Join(dir, "nightly-sync.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "workers: 8")
+	assert.NotContains(t, string(data), "schema_only", "zero values should be omitted")
+
+	loaded, err := preset.Load(dir, "nightly-sync")
+	require.NoError(t, err)
+	assert.Equal(t, original, *loaded)
+
+	names, err := preset.List(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nightly-sync"}, names)
+}
+
+func TestPresetLoadMissing(t *testing.T) {
+	_, err := preset.Load(t.TempDir(), "absent")
+	assert.ErrorContains(t, err, `preset "absent" not found`)
+}
+
+func TestPresetRejectsPathNames(t *testing.T) {
+	assert.Error(t, preset.Save(t.TempDir(), "../escape", preset.TransferPreset{}))
+}
+
+func TestPresetMergeExplicitFlagsWin(t *testing.T) {
+	p := preset.TransferPreset{DataOnly: true, Workers: 8, BatchSize: 250}
+	flags := transfer.Options{ParallelWorkers: 2, BatchSize: 1000}
+	changed := map[string]bool{"workers": true}
+
+	merged := p.Merge(flags, func(name string) bool { return changed[name] })
+
+	assert.Equal(t, 2, merged.ParallelWorkers, "explicit --workers overrides the preset")
+	assert.Equal(t, 250, merged.BatchSize, "unset --batch-size falls back to the preset")
+	assert.True(t, merged.DataOnly)
+}
+
+func TestPresetMergeKeepsFlagDefaultsForUnsetPresetFields(t *testing.T) {
+	merged := preset.TransferPreset{}.Merge(transfer.Options{ParallelWorkers: 4, BatchSize: 1000}, func(string) bool { return false })
+
+	assert.Equal(t, 4, merged.ParallelWorkers)
+	assert.Equal(t, 1000, merged.BatchSize)
+}
@@ -0,0 +1,96 @@
+package profile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/profile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestCheckReportsMalformedYAML(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "good.yaml", "version: 1\ndatabase:\n  type: postgres\n  host: localhost\n  port: 5432\n")
+	broken := writeFile(t, dir, "broken.yaml", "database:\n  type: postgres\n  host: [localhost\n")
+
+	statuses, err := profile.Check(dir)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, broken, statuses[0].Path)
+	assert.False(t, statuses[0].Valid())
+	require.Len(t, statuses[0].Errors, 1)
+	assert.Contains(t, statuses[0].Errors[0].Error(), "failed to parse config")
+
+	assert.True(t, statuses[1].Valid())
+	assert.False(t, statuses[1].Outdated)
+}
+
+func TestCheckCollectsValidationErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "odd.yaml", "database:\n  type: mysql\n  port: 70000\n")
+
+	statuses, err := profile.Check(dir)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+
+	var messages []string
+	for _, problem := range statuses[0].Errors {
+		messages = append(messages, problem.Error())
+	}
+	assert.Contains(t, messages, `unsupported database type "mysql" (use postgres or mongo)`)
+	assert.Contains(t, messages, "a host or URI is required")
+	assert.Contains(t, messages, "port 70000 is out of range")
+}
+
+func TestCheckFlagsOutdatedProfiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "legacy.yml", "database:\n  type: mongodb\n  host: localhost\n")
+
+	statuses, err := profile.Check(dir)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.True(t, statuses[0].Valid())
+	assert.True(t, statuses[0].Outdated)
+}
+
+func TestQuarantineHidesProfileFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "broken.yaml", ":\n")
+
+	moved, err := profile.Quarantine(dir, path)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, profile.QuarantineDir, "broken.yaml"), moved)
+	assert.FileExists(t, moved)
+
+	files, err := profile.Files(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestQuarantineKeepsEarlierQuarantinedProfile(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := profile.Quarantine(dir, writeFile(t, dir, "broken.yaml", "first\n"))
+	require.NoError(t, err)
+	second, err := profile.Quarantine(dir, writeFile(t, dir, "broken.yaml", "second\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, profile.QuarantineDir, "broken.1.yaml"), second)
+	content, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(content))
+	content, err = os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(content))
+}
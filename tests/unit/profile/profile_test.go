@@ -0,0 +1,48 @@
+package profile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/profile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProfile(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("database:\n  type: postgres\n"), 0o644))
+	return path
+}
+
+func TestResolveConfigPathPrefersFlag(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile(t, dir, "local.yaml")
+	require.NoError(t, profile.SetDefault(dir, "local"))
+
+	path, err := profile.ResolveConfigPath("explicit.yaml", dir)
+	require.NoError(t, err)
+	assert.Equal(t, "explicit.yaml", path)
+}
+
+func TestResolveConfigPathFallsBackToDefault(t *testing.T) {
+	dir := t.TempDir()
+	expected := writeProfile(t, dir, "local.yml")
+	require.NoError(t, profile.SetDefault(dir, "local"))
+
+	path, err := profile.ResolveConfigPath("", dir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, path)
+}
+
+func TestResolveConfigPathErrorsWithoutDefault(t *testing.T) {
+	_, err := profile.ResolveConfigPath("", t.TempDir())
+	assert.ErrorIs(t, err, profile.ErrNoConfig)
+}
+
+func TestSetDefaultRejectsUnknownProfile(t *testing.T) {
+	assert.Error(t, profile.SetDefault(t.TempDir(), "missing"))
+}
@@ -0,0 +1,74 @@
+package progress_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/pkg/progress"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiTracksIncrementsPerTable(t *testing.T) {
+	multi := progress.NewMultiTo(&bytes.Buffer{}, 300, "Data transfer", true)
+	users := multi.Table("public.users", 200)
+	orders := multi.Table("public.orders", 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			users.IncrementBy(50)
+		}()
+	}
+	wg.Wait()
+	orders.IncrementBy(30)
+
+	assert.Equal(t, int64(230), multi.Current())
+	assert.Equal(t, []progress.TableStatus{
+		{Name: "public.users", Current: 200, Total: 200, Started: true},
+		{Name: "public.orders", Current: 30, Total: 100, Started: true},
+	}, multi.Tables())
+
+	users.Done()
+	users.Done()
+	statuses := multi.Tables()
+	assert.True(t, statuses[0].Done)
+	assert.False(t, statuses[1].Done)
+	assert.Equal(t, int64(230), multi.Current(), "a repeated Done must not change the counts")
+}
+
+func TestMultiDrawsOnlyStartedTables(t *testing.T) {
+	var out bytes.Buffer
+	multi := progress.NewMultiTo(&out, 10, "Data transfer", true)
+	multi.Table("public.waiting", 5)
+	running := multi.Table("public.running", 5)
+
+	running.Start()
+	running.IncrementBy(5)
+	running.Done()
+	multi.Finish()
+
+	require.NotEmpty(t, out.String())
+	assert.Contains(t, out.String(), "public.running")
+	assert.NotContains(t, out.String(), "public.waiting")
+	assert.Contains(t, out.String(), "Data transfer")
+}
+
+func TestMultiFallsBackToAggregateBar(t *testing.T) {
+	var out bytes.Buffer
+	multi := progress.NewMultiTo(&out, 10, "Data transfer", false)
+	table := multi.Table("public.users", 10)
+
+	table.Start()
+	table.IncrementBy(10)
+	table.Done()
+	multi.Finish()
+
+	assert.Equal(t, int64(10), multi.Current())
+	assert.NotContains(t, out.String(), "public.users", "the aggregate bar has no per-table lines")
+	assert.NotContains(t, out.String(), "\x1b[", "no cursor movement outside a terminal")
+}
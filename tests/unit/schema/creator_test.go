@@ -0,0 +1,67 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mixedCaseTable() schema.Table {
+	return schema.Table{
+		Name:   "UserAccounts",
+		Schema: "public",
+		Columns: []schema.Column{
+			{Name: "AccountID", DataType: "integer"},
+			{Name: "DisplayName", DataType: "text", IsNullable: true},
+		},
+		PrimaryKeys: []string{"AccountID"},
+		Indexes: []schema.Index{
+			{Name: "IX_DisplayName", Columns: []string{"DisplayName"}, IndexType: "BTREE"},
+		},
+		ForeignKeys: []schema.ForeignKey{
+			{Name: "FK_Owner", ColumnName: "AccountID", ReferencedSchema: "public", ReferencedTable: "Owners", ReferencedColumn: "OwnerID"},
+		},
+	}
+}
+
+func newCreator(identifierCase string) *schema.Creator {
+	return schema.NewCreator(nil, logger.NewLogger(false), schema.CreateOptions{IdentifierCase: identifierCase})
+}
+
+func TestCreateTableSQLPreservesCaseByDefault(t *testing.T) {
+	sql := newCreator("").BuildCreateTableSQL(mixedCaseTable())
+
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."UserAccounts" ("AccountID" integer NOT NULL, "DisplayName" text, PRIMARY KEY ("AccountID"))`,
+		sql)
+}
+
+func TestCreateTableSQLFoldsToLower(t *testing.T) {
+	creator := newCreator(schema.IdentifierCaseLower)
+	table := mixedCaseTable()
+
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."useraccounts" ("accountid" integer NOT NULL, "displayname" text, PRIMARY KEY ("accountid"))`,
+		creator.BuildCreateTableSQL(table))
+	assert.Equal(t,
+		`CREATE INDEX IF NOT EXISTS "ix_displayname" ON "public"."useraccounts" USING BTREE ("displayname")`,
+		creator.BuildCreateIndexSQL(table, table.Indexes[0]))
+	assert.Equal(t,
+		`ALTER TABLE "public"."useraccounts" ADD CONSTRAINT "fk_owner" FOREIGN KEY ("accountid") REFERENCES "public"."owners" ("ownerid")`,
+		creator.BuildForeignKeySQL(table, table.ForeignKeys[0]))
+}
+
+func TestCreateTableSQLFoldsToUpper(t *testing.T) {
+	sql := newCreator(schema.IdentifierCaseUpper).BuildCreateTableSQL(mixedCaseTable())
+
+	assert.Contains(t, sql, `"public"."USERACCOUNTS"`)
+	assert.Contains(t, sql, `PRIMARY KEY ("ACCOUNTID")`)
+}
+
+func TestValidateIdentifierCase(t *testing.T) {
+	assert.NoError(t, schema.ValidateIdentifierCase("Lower"))
+	assert.Error(t, schema.ValidateIdentifierCase("camel"))
+}
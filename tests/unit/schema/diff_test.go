@@ -0,0 +1,148 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func usersTable(columns ...schema.Column) schema.Table {
+	return schema.Table{Name: "users", Schema: "public", Columns: columns}
+}
+
+func TestDiffSchemaFindsAddedColumn(t *testing.T) {
+	id := schema.Column{Name: "id", DataType: "integer"}
+	length := 64
+	nickname := schema.Column{Name: "nickname", DataType: "character varying", IsNullable: true, MaxLength: &length}
+
+	diff := schema.DiffSchema(
+		schema.Objects{Tables: []schema.Table{usersTable(id, nickname)}},
+		schema.Objects{Tables: []schema.Table{usersTable(id)}},
+		"",
+	)
+
+	require.Len(t, diff.AddedColumns, 1)
+	assert.Empty(t, diff.NewTables)
+
+	added := diff.AddedColumns[0]
+	assert.Equal(t,
+		`ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "nickname" character varying(64)`,
+		newCreator("").BuildAddColumnSQL(added.Table, added.Column))
+}
+
+func TestAddColumnSQLKeepsConstraintsAndDefault(t *testing.T) {
+	def := "'active'::text"
+	col := schema.Column{Name: "Status", DataType: "text", DefaultValue: &def}
+
+	assert.Equal(t,
+		`ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'active'::text`,
+		newCreator(schema.IdentifierCaseLower).BuildAddColumnSQL(usersTable(), col))
+}
+
+func TestDiffSchemaMatchesFoldedTargetNames(t *testing.T) {
+	source := mixedCaseTable()
+	target := schema.Table{
+		Name:   "useraccounts",
+		Schema: "public",
+		Columns: []schema.Column{
+			{Name: "accountid", DataType: "integer"},
+			{Name: "displayname", DataType: "text", IsNullable: true},
+		},
+		Indexes:     []schema.Index{{Name: "ix_displayname"}},
+		ForeignKeys: []schema.ForeignKey{{Name: "fk_owner"}},
+	}
+
+	diff := schema.DiffSchema(
+		schema.Objects{Tables: []schema.Table{source}},
+		schema.Objects{Tables: []schema.Table{target}},
+		schema.IdentifierCaseLower,
+	)
+
+	assert.True(t, diff.Empty())
+	assert.Empty(t, diff.ChangedColumns)
+}
+
+func TestDiffSchemaReportsNewObjectsAndChangedColumns(t *testing.T) {
+	source := mixedCaseTable()
+	orders := schema.Table{Name: "orders", Schema: "public", Columns: []schema.Column{{Name: "id", DataType: "bigint"}}}
+	target := schema.Table{
+		Name:   "UserAccounts",
+		Schema: "public",
+		Columns: []schema.Column{
+			{Name: "AccountID", DataType: "bigint"},
+			{Name: "DisplayName", DataType: "text", IsNullable: true},
+		},
+	}
+
+	diff := schema.DiffSchema(
+		schema.Objects{Tables: []schema.Table{source, orders}},
+		schema.Objects{Tables: []schema.Table{target}},
+		"",
+	)
+
+	require.Len(t, diff.NewTables, 1)
+	assert.Equal(t, "orders", diff.NewTables[0].Name)
+	require.Len(t, diff.NewIndexes, 1)
+	require.Len(t, diff.NewForeignKeys, 1)
+	require.Len(t, diff.ChangedColumns, 1)
+	assert.Equal(t, "AccountID", diff.ChangedColumns[0].Source.Name)
+
+	plan := newCreator("").PlanDiff(diff)
+	var sql []string
+	for _, stmt := range plan {
+		sql = append(sql, stmt.SQL)
+	}
+	assert.Equal(t, []string{
+		`CREATE TABLE IF NOT EXISTS "public"."orders" ("id" bigint NOT NULL)`,
+		`CREATE INDEX IF NOT EXISTS "IX_DisplayName" ON "public"."UserAccounts" USING BTREE ("DisplayName")`,
+		`ALTER TABLE "public"."UserAccounts" ADD CONSTRAINT "FK_Owner" FOREIGN KEY ("AccountID") REFERENCES "public"."Owners" ("OwnerID")`,
+	}, sql)
+}
+
+func TestWriteMigrationScript(t *testing.T) {
+	id := schema.Column{Name: "id", DataType: "integer"}
+	email := schema.Column{Name: "email", DataType: "text", IsNullable: true}
+	orders := schema.Table{Name: "orders", Schema: "public", Columns: []schema.Column{{Name: "id", DataType: "bigint"}}, PrimaryKeys: []string{"id"}}
+	sourceUsers := usersTable(id, email, schema.Column{Name: "age", DataType: "integer", IsNullable: true})
+	sourceUsers.Indexes = []schema.Index{{Name: "users_email_idx", Columns: []string{"email"}, IndexType: "BTREE"}}
+	targetUsers := usersTable(id, schema.Column{Name: "email", DataType: "character varying", IsNullable: true})
+
+	diff := schema.DiffSchema(
+		schema.Objects{Tables: []schema.Table{sourceUsers, orders}},
+		schema.Objects{Tables: []schema.Table{targetUsers}},
+		"",
+	)
+
+	var script strings.Builder
+	require.NoError(t, newCreator("").WriteMigrationScript(&script, diff))
+
+	assert.Equal(t, `-- Not altered: column public.users.email differs on the target (text vs character varying)
+-- 1 new tables, 1 added columns, 1 new indexes, 0 new foreign keys
+
+BEGIN;
+
+-- table public.orders
+CREATE TABLE IF NOT EXISTS "public"."orders" ("id" bigint NOT NULL, PRIMARY KEY ("id"));
+
+-- column public.users.age
+ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "age" integer;
+
+-- index users_email_idx (optional: remove it if it fails)
+CREATE INDEX IF NOT EXISTS "users_email_idx" ON "public"."users" USING BTREE ("email");
+
+COMMIT;
+`, script.String())
+}
+
+func TestWriteMigrationScriptUpToDate(t *testing.T) {
+	table := usersTable(schema.Column{Name: "id", DataType: "integer"})
+	diff := schema.DiffSchema(schema.Objects{Tables: []schema.Table{table}}, schema.Objects{Tables: []schema.Table{table}}, "")
+
+	var script strings.Builder
+	require.NoError(t, newCreator("").WriteMigrationScript(&script, diff))
+	assert.Equal(t, "-- The target schema is up to date; there is nothing to apply.\n", script.String())
+}
@@ -0,0 +1,86 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func emailDomain() schema.Domain {
+	return schema.Domain{
+		Name:     "email_address",
+		Schema:   "public",
+		BaseType: "character varying(320)",
+		NotNull:  true,
+		Constraints: []schema.DomainConstraint{
+			{Name: "email_address_check", Definition: `CHECK (((VALUE)::text ~~ '%@%'::text))`},
+		},
+	}
+}
+
+func TestBuildCreateDomainSQL(t *testing.T) {
+	assert.Equal(t,
+		`CREATE DOMAIN "public"."email_address" AS character varying(320) NOT NULL CONSTRAINT "email_address_check" CHECK (((VALUE)::text ~~ '%@%'::text))`,
+		newCreator("").BuildCreateDomainSQL(emailDomain()))
+
+	defaultValue := "0"
+	assert.Equal(t,
+		`CREATE DOMAIN "billing"."amount" AS numeric(12,2) DEFAULT 0`,
+		newCreator("").BuildCreateDomainSQL(schema.Domain{Name: "amount", Schema: "billing", BaseType: "numeric(12,2)", Default: &defaultValue}))
+}
+
+func TestDomainColumnsReferenceTheDomain(t *testing.T) {
+	table := schema.Table{
+		Name:   "Contacts",
+		Schema: "public",
+		Columns: []schema.Column{
+			{Name: "Email", DataType: "character varying", DomainSchema: "public", Domain: "Email_Address"},
+		},
+	}
+
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."contacts" ("email" "public"."email_address" NOT NULL)`,
+		newCreator(schema.IdentifierCaseLower).BuildCreateTableSQL(table))
+}
+
+func TestPlanSchemaCreatesDomainsBeforeTables(t *testing.T) {
+	plan := newCreator("").PlanSchema(schema.Objects{
+		Extensions: []schema.Extension{{Name: "citext", Schema: "public"}},
+		Domains:    []schema.Domain{emailDomain()},
+		Tables:     []schema.Table{{Name: "contacts", Schema: "public", Columns: []schema.Column{{Name: "id", DataType: "integer"}}}},
+	})
+
+	require.Len(t, plan, 3)
+	assert.Contains(t, plan[0].SQL, "CREATE EXTENSION")
+	assert.Contains(t, plan[1].SQL, "CREATE DOMAIN")
+	assert.True(t, plan[1].BestEffort, "an existing domain on the target must not abort the transfer")
+	assert.Contains(t, plan[2].SQL, "CREATE TABLE")
+}
+
+func TestExtractDomains(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM pg_type").WillReturnRows(
+		sqlmock.NewRows([]string{"oid", "nspname", "typname", "format_type", "typnotnull", "typdefault"}).
+			AddRow(16390, "public", "email_address", "character varying(320)", true, nil),
+	)
+	mock.ExpectQuery("FROM pg_constraint").WithArgs(int64(16390)).WillReturnRows(
+		sqlmock.NewRows([]string{"conname", "pg_get_constraintdef"}).
+			AddRow("email_address_check", `CHECK (((VALUE)::text ~~ '%@%'::text))`),
+	)
+
+	extractor := schema.NewExtractor(&database.Connection{DB: db}, logger.NewLogger(false))
+	domains, err := extractor.ExtractDomains()
+	require.NoError(t, err)
+
+	assert.Equal(t, []schema.Domain{emailDomain()}, domains)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractExtensions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM pg_extension").WillReturnRows(
+		sqlmock.NewRows([]string{"extname", "extversion", "nspname"}).
+			AddRow("pgcrypto", "1.3", "public").
+			AddRow("uuid-ossp", "1.1", "extensions"),
+	)
+
+	extractor := schema.NewExtractor(&database.Connection{DB: db}, logger.NewLogger(false))
+	extensions, err := extractor.ExtractExtensions()
+	require.NoError(t, err)
+
+	assert.Equal(t, []schema.Extension{
+		{Name: "pgcrypto", Version: "1.3", Schema: "public"},
+		{Name: "uuid-ossp", Version: "1.1", Schema: "extensions"},
+	}, extensions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlanSchemaCreatesExtensionsFirst(t *testing.T) {
+	table := mixedCaseTable()
+	plan := newCreator("").PlanSchema(schema.Objects{
+		Extensions: []schema.Extension{{Name: "uuid-ossp", Schema: "public"}},
+		Tables:     []schema.Table{table},
+	})
+
+	require.Len(t, plan, 4)
+	assert.Equal(t, `CREATE EXTENSION IF NOT EXISTS "uuid-ossp" WITH SCHEMA "public"`, plan[0].SQL)
+	assert.True(t, plan[0].BestEffort)
+	assert.Contains(t, plan[1].SQL, "CREATE TABLE")
+	assert.False(t, plan[1].BestEffort, "table creation failures must abort the transfer")
+	assert.Contains(t, plan[2].SQL, "CREATE INDEX")
+	assert.Contains(t, plan[3].SQL, "FOREIGN KEY")
+}
@@ -0,0 +1,59 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(value string) *string {
+	return &value
+}
+
+func TestBuildCreatePolicySQL(t *testing.T) {
+	table := schema.Table{Name: "documents", Schema: "app", RowSecurity: true}
+	creator := newCreator("")
+
+	tenant := schema.Policy{
+		Name:       "tenant_isolation",
+		Permissive: true,
+		Command:    "ALL",
+		Roles:      []string{"app_user", "reporting"},
+		Using:      strPtr("(tenant_id = current_setting('app.tenant')::integer)"),
+		WithCheck:  strPtr("(tenant_id = current_setting('app.tenant')::integer)"),
+	}
+	assert.Equal(t,
+		`CREATE POLICY "tenant_isolation" ON "app"."documents" AS PERMISSIVE FOR ALL TO "app_user", "reporting" `+
+			`USING ((tenant_id = current_setting('app.tenant')::integer)) WITH CHECK ((tenant_id = current_setting('app.tenant')::integer))`,
+		creator.BuildCreatePolicySQL(table, tenant))
+
+	readOnly := schema.Policy{
+		Name:    "public_read",
+		Command: "SELECT",
+		Roles:   []string{"public"},
+		Using:   strPtr("published"),
+	}
+	assert.Equal(t,
+		`CREATE POLICY "public_read" ON "app"."documents" AS RESTRICTIVE FOR SELECT TO PUBLIC USING (published)`,
+		creator.BuildCreatePolicySQL(table, readOnly))
+}
+
+func TestPlanSchemaEnablesRowSecurityAfterTables(t *testing.T) {
+	table := schema.Table{
+		Name:        "documents",
+		Schema:      "app",
+		Columns:     []schema.Column{{Name: "id", DataType: "integer"}},
+		RowSecurity: true,
+		Policies:    []schema.Policy{{Name: "p", Permissive: true, Command: "SELECT", Using: strPtr("true")}},
+	}
+
+	plan := newCreator("").PlanSchema(schema.Objects{Tables: []schema.Table{table}})
+
+	require.Len(t, plan, 3)
+	assert.Contains(t, plan[0].SQL, "CREATE TABLE")
+	assert.Equal(t, `ALTER TABLE "app"."documents" ENABLE ROW LEVEL SECURITY`, plan[1].SQL)
+	assert.Equal(t, `CREATE POLICY "p" ON "app"."documents" AS PERMISSIVE FOR SELECT USING (true)`, plan[2].SQL)
+}
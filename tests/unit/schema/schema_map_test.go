@@ -0,0 +1,63 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mappedCreator() *schema.Creator {
+	return schema.NewCreator(nil, logger.NewLogger(false), schema.CreateOptions{
+		SchemaMap: map[string]string{"app": "public", "billing": "Finance"},
+	})
+}
+
+func TestPlanSchemaCreatesMappedSchemasFirst(t *testing.T) {
+	defaultValue := `nextval('app.orders_id_seq'::regclass)`
+	orders := schema.Table{
+		Schema: "app",
+		Name:   "orders",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "integer", DefaultValue: &defaultValue},
+			{Name: "invoice_id", DataType: "integer", IsNullable: true},
+		},
+		PrimaryKeys: []string{"id"},
+		ForeignKeys: []schema.ForeignKey{
+			{Name: "orders_invoice_fk", ColumnName: "invoice_id", ReferencedSchema: "billing", ReferencedTable: "invoices", ReferencedColumn: "id"},
+		},
+	}
+	invoices := schema.Table{
+		Schema:      "billing",
+		Name:        "invoices",
+		Columns:     []schema.Column{{Name: "id", DataType: "integer"}},
+		PrimaryKeys: []string{"id"},
+	}
+	audit := schema.Table{Schema: "audit", Name: "events", Columns: []schema.Column{{Name: "id", DataType: "integer"}}}
+
+	plan := mappedCreator().PlanSchema(schema.Objects{
+		Sequences: []schema.Sequence{{Schema: "app", Name: "orders_id_seq", IncrementBy: 1, StartValue: 1, CacheValue: 1}},
+		Tables:    []schema.Table{orders, invoices, audit},
+	})
+
+	require.Len(t, plan, 7)
+	assert.Equal(t, `CREATE SCHEMA IF NOT EXISTS "Finance"`, plan[0].SQL)
+	assert.Equal(t, `CREATE SCHEMA IF NOT EXISTS "public"`, plan[1].SQL)
+	assert.Contains(t, plan[2].SQL, `CREATE SEQUENCE IF NOT EXISTS "public"."orders_id_seq"`)
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."orders" ("id" integer NOT NULL DEFAULT nextval('"public".orders_id_seq'::regclass), "invoice_id" integer, PRIMARY KEY ("id"))`,
+		plan[3].SQL)
+	assert.Contains(t, plan[4].SQL, `CREATE TABLE IF NOT EXISTS "Finance"."invoices"`)
+	assert.Contains(t, plan[5].SQL, `CREATE TABLE IF NOT EXISTS "audit"."events"`, "unmapped schemas are left alone")
+	assert.Equal(t,
+		`ALTER TABLE "public"."orders" ADD CONSTRAINT "orders_invoice_fk" FOREIGN KEY ("invoice_id") REFERENCES "Finance"."invoices" ("id")`,
+		plan[6].SQL)
+}
+
+func TestSetSequenceSQLUsesMappedSchema(t *testing.T) {
+	seq := schema.Sequence{Schema: "app", Name: "orders_id_seq", LastValue: int64Ptr(7)}
+	assert.Equal(t, `SELECT setval('"public"."orders_id_seq"', 7, true)`, mappedCreator().BuildSetSequenceSQL(seq))
+}
@@ -0,0 +1,100 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestExtractSequences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM pg_sequences").WillReturnRows(
+		sqlmock.NewRows([]string{"schemaname", "sequencename", "last_value", "start_value", "increment_by",
+			"min_value", "max_value", "cache_size", "cycle"}).
+			AddRow("public", "orders_id_seq", int64(1042), int64(1), int64(1), int64(1), int64(2147483647), int64(1), false).
+			AddRow("billing", "Invoice_No", nil, int64(100), int64(-5), int64(-1000), int64(100), int64(20), true),
+	)
+
+	extractor := schema.NewExtractor(&database.Connection{DB: db}, logger.NewLogger(false))
+	sequences, err := extractor.ExtractSequences()
+	require.NoError(t, err)
+
+	assert.Equal(t, []schema.Sequence{
+		{
+			Name: "orders_id_seq", Schema: "public", LastValue: int64Ptr(1042), StartValue: 1, IncrementBy: 1,
+			MinValue: int64Ptr(1), MaxValue: int64Ptr(2147483647), CacheValue: 1,
+		},
+		{
+			Name: "Invoice_No", Schema: "billing", StartValue: 100, IncrementBy: -5,
+			MinValue: int64Ptr(-1000), MaxValue: int64Ptr(100), CacheValue: 20, IsCycle: true,
+		},
+	}, sequences)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExtractSequencesSkipsIdentitySequences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Identity columns own their sequence with an internal ('i') dependency;
+	// the target columns are plain, so those sequences would be orphans.
+	mock.ExpectQuery(`FROM pg_sequences s\s+WHERE .*NOT EXISTS \(\s*SELECT 1 FROM pg_depend d.*d\.deptype = 'i'`).WillReturnRows(
+		sqlmock.NewRows([]string{"schemaname", "sequencename", "last_value", "start_value", "increment_by",
+			"min_value", "max_value", "cache_size", "cycle"}).
+			AddRow("public", "orders_id_seq", int64(1042), int64(1), int64(1), int64(1), int64(2147483647), int64(1), false),
+	)
+
+	extractor := schema.NewExtractor(&database.Connection{DB: db}, logger.NewLogger(false))
+	sequences, err := extractor.ExtractSequences()
+	require.NoError(t, err)
+	require.Len(t, sequences, 1)
+	assert.Equal(t, "orders_id_seq", sequences[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateSequenceSQL(t *testing.T) {
+	seq := schema.Sequence{
+		Name: "Invoice_No", Schema: "billing", StartValue: 100, IncrementBy: -5,
+		MinValue: int64Ptr(-1000), MaxValue: int64Ptr(100), CacheValue: 20, IsCycle: true,
+	}
+
+	assert.Equal(t,
+		`CREATE SEQUENCE IF NOT EXISTS "billing"."invoice_no" INCREMENT BY -5 MINVALUE -1000 MAXVALUE 100 START WITH 100 CACHE 20 CYCLE`,
+		newCreator(schema.IdentifierCaseLower).BuildCreateSequenceSQL(seq))
+}
+
+func TestSetSequenceSQL(t *testing.T) {
+	creator := newCreator("")
+
+	used := schema.Sequence{Name: "orders_id_seq", Schema: "public", LastValue: int64Ptr(1042), StartValue: 1}
+	assert.Equal(t, `SELECT setval('"public"."orders_id_seq"', 1042, true)`, creator.BuildSetSequenceSQL(used))
+
+	unused := schema.Sequence{Name: "it's", Schema: "public", StartValue: 100}
+	assert.Equal(t, `SELECT setval('"public"."it''s"', 100, false)`, creator.BuildSetSequenceSQL(unused),
+		"a sequence never used on the source must hand out its start value next")
+}
+
+func TestPlanSchemaCreatesSequencesBeforeTables(t *testing.T) {
+	plan := newCreator("").PlanSchema(schema.Objects{
+		Sequences: []schema.Sequence{{Name: "UserAccounts_AccountID_seq", Schema: "public", IncrementBy: 1, StartValue: 1, CacheValue: 1}},
+		Tables:    []schema.Table{mixedCaseTable()},
+	})
+
+	require.GreaterOrEqual(t, len(plan), 2)
+	assert.Contains(t, plan[0].SQL, "CREATE SEQUENCE")
+	assert.Contains(t, plan[1].SQL, "CREATE TABLE")
+}
@@ -0,0 +1,60 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func storageTable() schema.Table {
+	return schema.Table{
+		Name:        "events",
+		Schema:      "public",
+		Columns:     []schema.Column{{Name: "id", DataType: "bigint"}},
+		StorageOpts: []string{"fillfactor=70", "autovacuum_vacuum_scale_factor=0.01"},
+		Tablespace:  "fast_ssd",
+	}
+}
+
+func TestBuildStorageClause(t *testing.T) {
+	assert.Equal(t,
+		` WITH (fillfactor=70, autovacuum_vacuum_scale_factor=0.01) TABLESPACE "fast_ssd"`,
+		schema.BuildStorageClause(storageTable()))
+
+	assert.Equal(t, "", schema.BuildStorageClause(schema.Table{Name: "plain"}))
+	assert.Equal(t, ` TABLESPACE "archive"`, schema.BuildStorageClause(schema.Table{Tablespace: "archive"}))
+}
+
+func TestCreateTableSQLIgnoresStorageUnlessPreserved(t *testing.T) {
+	sql := newCreator("").BuildCreateTableSQL(storageTable())
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS "public"."events" ("id" bigint NOT NULL)`, sql)
+
+	preserving := schema.NewCreator(nil, logger.NewLogger(false), schema.CreateOptions{PreserveStorage: true})
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."events" ("id" bigint NOT NULL) WITH (fillfactor=70, autovacuum_vacuum_scale_factor=0.01) TABLESPACE "fast_ssd"`,
+		preserving.BuildCreateTableSQL(storageTable()))
+}
+
+func TestCreateSchemaDropsMissingTablespace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	creator := schema.NewCreator(&database.Connection{DB: db}, logger.NewLogger(false), schema.CreateOptions{PreserveStorage: true})
+
+	mock.ExpectQuery("FROM pg_tablespace").WithArgs("fast_ssd").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec(`WITH \(fillfactor=70, autovacuum_vacuum_scale_factor=0.01\)$`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	require.NoError(t, creator.CreateTables([]schema.Table{storageTable()}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,102 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func viewNames(views []schema.View) []string {
+	names := make([]string, len(views))
+	for i, view := range views {
+		names[i] = view.Schema + "." + view.Name
+	}
+	return names
+}
+
+func TestOrderViewsPutsDependenciesFirst(t *testing.T) {
+	views := []schema.View{
+		{Schema: "public", Name: "top_customers", DependsOn: []string{"public.customer_totals", "public.active_customers"}},
+		{Schema: "public", Name: "customer_totals", DependsOn: []string{"public.active_customers"}},
+		{Schema: "public", Name: "active_customers"},
+		{Schema: "reports", Name: "daily", DependsOn: []string{"public.orders"}},
+	}
+
+	ordered, err := schema.OrderViews(views)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"public.active_customers",
+		"public.customer_totals",
+		"public.top_customers",
+		"reports.daily",
+	}, viewNames(ordered), "dependencies that are not views, such as tables, are ignored")
+}
+
+func TestOrderViewsReportsCycles(t *testing.T) {
+	views := []schema.View{
+		{Schema: "public", Name: "standalone"},
+		{Schema: "public", Name: "a", DependsOn: []string{"public.b"}},
+		{Schema: "public", Name: "b", DependsOn: []string{"public.c"}},
+		{Schema: "public", Name: "c", DependsOn: []string{"public.a"}},
+	}
+
+	_, err := schema.OrderViews(views)
+	assert.EqualError(t, err, "views form a dependency cycle: public.a -> public.b -> public.c -> public.a")
+}
+
+func TestCreateViewSQL(t *testing.T) {
+	creator := newCreator(schema.IdentifierCaseLower)
+
+	view := schema.View{Schema: "public", Name: "ActiveUsers", Definition: " SELECT id FROM users WHERE active;"}
+	assert.Equal(t, `CREATE OR REPLACE VIEW "public"."activeusers" AS SELECT id FROM users WHERE active`, creator.BuildCreateViewSQL(view))
+
+	view.Materialized = true
+	assert.Equal(t, `CREATE MATERIALIZED VIEW IF NOT EXISTS "public"."activeusers" AS SELECT id FROM users WHERE active WITH NO DATA`,
+		creator.BuildCreateViewSQL(view))
+}
+
+func TestExtractViews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("FROM information_schema.views").WillReturnRows(
+		sqlmock.NewRows([]string{"table_schema", "table_name", "view_definition", "bool"}).
+			AddRow("public", "active_users", " SELECT id FROM users;", false).
+			AddRow("public", "hidden", nil, false).
+			AddRow("reports", "user_counts", " SELECT count(*) FROM active_users;", true),
+	)
+	mock.ExpectQuery("FROM pg_rewrite").WillReturnRows(
+		sqlmock.NewRows([]string{"nspname", "relname", "nspname", "relname"}).
+			AddRow("reports", "user_counts", "public", "active_users"),
+	)
+
+	extractor := schema.NewExtractor(&database.Connection{DB: db}, logger.NewLogger(false))
+	views, err := extractor.ExtractViews()
+	require.NoError(t, err)
+
+	assert.Equal(t, []schema.View{
+		{Schema: "public", Name: "active_users", Definition: " SELECT id FROM users;"},
+		{Schema: "reports", Name: "user_counts", Definition: " SELECT count(*) FROM active_users;", Materialized: true, DependsOn: []string{"public.active_users"}},
+	}, views, "views whose definition is hidden are skipped")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMissingViewsSkipsViewsTheTargetHas(t *testing.T) {
+	source := []schema.View{
+		{Schema: "public", Name: "active_users"},
+		{Schema: "public", Name: "user_counts", DependsOn: []string{"public.active_users"}},
+		{Schema: "reports", Name: "active_users"},
+	}
+	target := []schema.View{
+		{Schema: "public", Name: "active_users", Definition: " SELECT id FROM users WHERE active;"},
+	}
+
+	assert.Equal(t, []string{"public.user_counts", "reports.active_users"}, viewNames(schema.MissingViews(source, target)))
+}
@@ -0,0 +1,92 @@
+package settings_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/backup"
+	"github.com/kadirbelkuyu/DBRTS/internal/preset"
+	"github.com/kadirbelkuyu/DBRTS/internal/settings"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/interactive"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func changedFlags(names ...string) func(string) bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return func(flag string) bool { return set[flag] }
+}
+
+func TestSettingsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dbrts", "settings.yaml")
+	original := &settings.Settings{
+		Postgres: settings.EngineSettings{Transfer: preset.TransferPreset{Workers: 8, BatchSize: 5000}},
+		Mongo:    settings.EngineSettings{Backup: settings.BackupDefaults{ReadPreference: "secondary", Compression: intPtr(0)}},
+	}
+	require.NoError(t, settings.Save(path, original))
+
+	loaded, err := settings.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, loaded)
+}
+
+func TestLoadMissingSettingsIsEmpty(t *testing.T) {
+	loaded, err := settings.Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, &settings.Settings{}, loaded)
+}
+
+func TestSettingsTransferDefaultsApplyUnlessFlagSet(t *testing.T) {
+	s := &settings.Settings{Postgres: settings.EngineSettings{Transfer: preset.TransferPreset{Workers: 8, BatchSize: 5000, VerifyChecksums: true}}}
+	flags := transfer.Options{ParallelWorkers: 4, BatchSize: 1000}
+
+	unset := s.For("postgres").TransferOptions(flags, changedFlags())
+	assert.Equal(t, 8, unset.ParallelWorkers)
+	assert.Equal(t, 5000, unset.BatchSize)
+	assert.True(t, unset.VerifyChecksums)
+
+	set := s.For("postgres").TransferOptions(transfer.Options{ParallelWorkers: 2, BatchSize: 1000}, changedFlags("workers"))
+	assert.Equal(t, 2, set.ParallelWorkers)
+	assert.Equal(t, 5000, set.BatchSize)
+
+	other := s.For("mongo").TransferOptions(flags, changedFlags())
+	assert.Equal(t, 4, other.ParallelWorkers, "postgres defaults must not leak into mongo")
+}
+
+func TestSettingsBackupDefaultsApplyUnlessFlagSet(t *testing.T) {
+	s := &settings.Settings{Mongo: settings.EngineSettings{Backup: settings.BackupDefaults{
+		ReadPreference: "secondary",
+		ExtraArgs:      []string{"--gzip"},
+	}}}
+
+	unset := s.For("mongo").BackupOptions(backup.BackupOptions{}, changedFlags())
+	assert.Equal(t, "secondary", unset.ReadPreference)
+	assert.Equal(t, []string{"--gzip"}, unset.ExtraArgs)
+
+	set := s.For("mongo").BackupOptions(backup.BackupOptions{ReadPreference: "primary"}, changedFlags("read-preference"))
+	assert.Equal(t, "primary", set.ReadPreference)
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestSettingsPromptDefaults(t *testing.T) {
+	postgres := settings.EngineSettings{Backup: settings.BackupDefaults{Format: "Plain", Compression: intPtr(9)}}
+	defaults, err := postgres.PromptDefaults("postgres")
+	require.NoError(t, err)
+	assert.Equal(t, interactive.BackupDefaults{Format: "sql", Compression: intPtr(9)}, defaults)
+
+	defaults, err = settings.EngineSettings{}.PromptDefaults("mongo")
+	require.NoError(t, err)
+	assert.Equal(t, interactive.BackupDefaults{}, defaults)
+
+	_, err = settings.EngineSettings{Backup: settings.BackupDefaults{Format: "zip"}}.PromptDefaults("postgres")
+	assert.ErrorContains(t, err, `unsupported postgres backup format "zip"`)
+
+	_, err = settings.EngineSettings{Backup: settings.BackupDefaults{Compression: intPtr(12)}}.PromptDefaults("postgres")
+	assert.ErrorContains(t, err, "out of range")
+}
@@ -0,0 +1,82 @@
+package transfer_test
+
+import (
+	"testing"
+
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postgresConfig(host, database string) *appconfig.Config {
+	return &appconfig.Config{Database: appconfig.DatabaseConfig{
+		Type:     "postgres",
+		Host:     host,
+		Port:     5432,
+		Username: "app",
+		Database: database,
+	}}
+}
+
+func TestViaDumpCommands(t *testing.T) {
+	dump, restore := transfer.ViaDumpCommands(
+		postgresConfig("source.internal", "shop"),
+		postgresConfig("target.internal", "shop_copy"),
+		transfer.Options{DumpCompression: 6},
+	)
+
+	assert.Equal(t, "pg_dump", dump.Tool)
+	assert.Equal(t, []string{
+		"--host=source.internal",
+		"--port=5432",
+		"--username=app",
+		"--dbname=shop",
+		"--format=custom",
+		"--compress=6",
+	}, dump.Args)
+
+	assert.Equal(t, "pg_restore", restore.Tool)
+	assert.Equal(t, []string{
+		"--host=target.internal",
+		"--port=5432",
+		"--username=app",
+		"--dbname=shop_copy",
+		"--exit-on-error",
+		"--no-owner",
+		"--no-privileges",
+	}, restore.Args)
+}
+
+func TestViaDumpCommandsDataOnlyWithTriggersDisabled(t *testing.T) {
+	dump, restore := transfer.ViaDumpCommands(
+		postgresConfig("source.internal", "shop"),
+		postgresConfig("target.internal", "shop"),
+		transfer.Options{DataOnly: true, DisableTriggers: true},
+	)
+
+	assert.Contains(t, dump.Args, "--data-only")
+	assert.Contains(t, restore.Args, "--disable-triggers")
+}
+
+func TestViaDumpRejectsRowLevelOptions(t *testing.T) {
+	source := postgresConfig("source.internal", "shop")
+	target := postgresConfig("target.internal", "shop")
+
+	_, err := transfer.NewService(source, target, transfer.Options{
+		ViaDump:    true,
+		Transforms: map[string]string{"public.users.email": "mask"},
+		Logger:     logger.NewLogger(false),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--transform")
+
+	_, err = transfer.NewService(source, target, transfer.Options{ViaDump: true, RateLimitRows: 100, Logger: logger.NewLogger(false)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--rate-limit-rows")
+
+	_, err = transfer.NewService(source, target, transfer.Options{ViaDump: true, Logger: logger.NewLogger(false)})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,84 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func customersTable() schema.Table {
+	return schema.Table{
+		Name:   "customers",
+		Schema: "public",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "integer"},
+			{Name: "email", DataType: "text", IsNullable: true},
+			{Name: "ssn", DataType: "text", IsNullable: true},
+		},
+		PrimaryKeys: []string{"id"},
+		Indexes: []schema.Index{
+			{Name: "customers_email_idx", TableName: "customers", Columns: []string{"email"}},
+			{Name: "customers_ssn_idx", TableName: "customers", Columns: []string{"ssn"}, IsUnique: true},
+		},
+	}
+}
+
+func TestExcludeColumnsOmitsColumnFromDDLAndCopy(t *testing.T) {
+	tables, err := transfer.ExcludeColumns([]schema.Table{customersTable()}, []string{"public.customers.ssn"})
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	table := tables[0]
+
+	creator := schema.NewCreator(nil, logger.NewLogger(false), schema.CreateOptions{})
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."customers" ("id" integer NOT NULL, "email" text, PRIMARY KEY ("id"))`,
+		creator.BuildCreateTableSQL(table))
+	require.Len(t, table.Indexes, 1, "indexes on the excluded column are dropped with it")
+	assert.Equal(t, "customers_email_idx", table.Indexes[0].Name)
+
+	job := &transfer.DataTransferJob{Table: table}
+	assert.Equal(t, `SELECT "id", "email" FROM "public"."customers" ORDER BY "id" OFFSET 0 LIMIT 10`, job.BuildSelectQuery(0, 10))
+	assert.Equal(t, `INSERT INTO "public"."customers" ("id", "email") VALUES ($1, $2) ON CONFLICT DO NOTHING`, job.BuildInsertQuery())
+}
+
+func TestExcludeColumnsDropsForeignKeysToExcludedColumns(t *testing.T) {
+	orders := schema.Table{
+		Name:    "orders",
+		Schema:  "public",
+		Columns: []schema.Column{{Name: "id", DataType: "integer"}, {Name: "customer_ssn", DataType: "text"}},
+		ForeignKeys: []schema.ForeignKey{
+			{Name: "orders_customer_ssn_fkey", ColumnName: "customer_ssn", ReferencedSchema: "public", ReferencedTable: "customers", ReferencedColumn: "ssn"},
+		},
+	}
+
+	tables, err := transfer.ExcludeColumns([]schema.Table{customersTable(), orders}, []string{"public.customers.ssn"})
+	require.NoError(t, err)
+	assert.Empty(t, tables[1].ForeignKeys)
+	assert.Len(t, tables[1].Columns, 2, "the referencing column itself is kept")
+}
+
+func TestExcludeColumnsRejectsPrimaryKey(t *testing.T) {
+	_, err := transfer.ExcludeColumns([]schema.Table{customersTable()}, []string{"public.customers.id"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "primary key")
+}
+
+func TestExcludeColumnsRejectsUnknownColumn(t *testing.T) {
+	_, err := transfer.ExcludeColumns([]schema.Table{customersTable()}, []string{"public.customers.sssn"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "public.customers.sssn")
+}
+
+func TestParseExcludeColumnFlags(t *testing.T) {
+	columns, err := transfer.ParseExcludeColumnFlags([]string{"public.customers.ssn"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public.customers.ssn"}, columns)
+
+	_, err = transfer.ParseExcludeColumnFlags([]string{"customers.ssn"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package transfer_test
+
+import (
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+	"github.com/kadirbelkuyu/DBRTS/pkg/progress"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysetKeyNeedsSingleIntegerOrUUIDPrimaryKey(t *testing.T) {
+	key, ok := transfer.KeysetKey(accountsTable())
+	assert.True(t, ok)
+	assert.Equal(t, "AccountID", key)
+
+	uuidKeyed := schema.Table{Columns: []schema.Column{{Name: "id", DataType: "uuid"}}, PrimaryKeys: []string{"id"}}
+	_, ok = transfer.KeysetKey(uuidKeyed)
+	assert.True(t, ok)
+
+	textKeyed := schema.Table{Columns: []schema.Column{{Name: "code", DataType: "text"}}, PrimaryKeys: []string{"code"}}
+	_, ok = transfer.KeysetKey(textKeyed)
+	assert.False(t, ok)
+
+	composite := schema.Table{
+		Columns:     []schema.Column{{Name: "a", DataType: "integer"}, {Name: "b", DataType: "integer"}},
+		PrimaryKeys: []string{"a", "b"},
+	}
+	_, ok = transfer.KeysetKey(composite)
+	assert.False(t, ok)
+}
+
+func TestKeysetQueryReplacesDeepOffset(t *testing.T) {
+	job := &transfer.DataTransferJob{Table: accountsTable()}
+
+	// The OFFSET query makes the server walk and discard every earlier row.
+	assert.Equal(t,
+		`SELECT "AccountID", "DisplayName" FROM "public"."UserAccounts" ORDER BY "AccountID" OFFSET 50000000 LIMIT 1000`,
+		job.BuildSelectQuery(50_000_000, 1000))
+	assert.Equal(t,
+		`SELECT "AccountID", "DisplayName" FROM "public"."UserAccounts" ORDER BY "AccountID" LIMIT 1000`,
+		job.BuildKeysetQuery("AccountID", false, 1000))
+	assert.Equal(t,
+		`SELECT "AccountID", "DisplayName" FROM "public"."UserAccounts" WHERE "AccountID" > $1 ORDER BY "AccountID" LIMIT 1000`,
+		job.BuildKeysetQuery("AccountID", true, 1000))
+
+	job.Range = &transfer.RowRange{Start: 100, End: 200}
+	job.RangeKey = "AccountID"
+	assert.Equal(t,
+		`SELECT "AccountID", "DisplayName" FROM "public"."UserAccounts" WHERE "AccountID" >= 100 AND "AccountID" < 200 AND "AccountID" > $1 ORDER BY "AccountID" LIMIT 1000`,
+		job.BuildKeysetQuery("AccountID", true, 1000))
+}
+
+func TestKeysetTransferCopiesEveryRowOnce(t *testing.T) {
+	sourceDB, source, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer sourceDB.Close()
+	targetDB, target, err := sqlmock.New()
+	require.NoError(t, err)
+	defer targetDB.Close()
+
+	table := accountsTable()
+	table.RowCount = 5
+	columns := []string{"AccountID", "DisplayName"}
+	job := &transfer.DataTransferJob{
+		Table:       table,
+		SourceConn:  &database.Connection{DB: sourceDB},
+		TargetConn:  &database.Connection{DB: targetDB},
+		BatchSize:   2,
+		ProgressBar: progress.NewMultiTo(io.Discard, 5, "Data transfer", false).Table("public.UserAccounts", 5),
+		Logger:      logger.NewLogger(false),
+	}
+
+	pages := [][]int64{{1, 2}, {3, 4}, {5}}
+	for i, page := range pages {
+		rows := sqlmock.NewRows(columns)
+		for _, id := range page {
+			rows.AddRow(id, "user")
+		}
+		query := source.ExpectQuery(job.BuildKeysetQuery("AccountID", i > 0, 2))
+		if i > 0 {
+			previous := pages[i-1]
+			query.WithArgs(previous[len(previous)-1])
+		}
+		query.WillReturnRows(rows)
+
+		target.ExpectBegin()
+		target.ExpectPrepare(regexp.QuoteMeta("INSERT INTO"))
+		for _, id := range page {
+			target.ExpectExec("INSERT INTO").WithArgs(id, "user").WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		target.ExpectCommit()
+	}
+
+	require.NoError(t, job.Execute())
+	assert.NoError(t, source.ExpectationsWereMet())
+	assert.NoError(t, target.ExpectationsWereMet())
+
+	result := job.Result()
+	assert.Equal(t, int64(5), result.RowsAttempted)
+	assert.Equal(t, int64(5), result.RowsSucceeded)
+}
@@ -0,0 +1,33 @@
+package transfer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleNotificationLogsPayload(t *testing.T) {
+	var out bytes.Buffer
+	log := logger.NewLogger(false)
+	log.SetOutput(&out)
+
+	transfer.HandleNotification(log, "target", &pq.Notification{BePid: 4242, Channel: "etl_progress", Extra: "orders: 50000/120000"})
+
+	assert.Contains(t, out.String(), "[target etl_progress] orders: 50000/120000")
+}
+
+func TestHandleNotificationWarnsAfterReconnect(t *testing.T) {
+	var out bytes.Buffer
+	log := logger.NewLogger(false)
+	log.SetOutput(&out)
+
+	transfer.HandleNotification(log, "source", nil)
+
+	assert.Contains(t, out.String(), "re-established")
+	assert.Contains(t, out.String(), "WARN")
+}
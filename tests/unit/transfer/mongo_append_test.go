@@ -0,0 +1,102 @@
+package transfer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type fakeMongoTarget struct {
+	dropped   bool
+	ordered   *bool
+	inserted  []interface{}
+	insertErr error
+}
+
+func (f *fakeMongoTarget) Drop(context.Context) error {
+	f.dropped = true
+	return nil
+}
+
+func (f *fakeMongoTarget) InsertMany(_ context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	f.inserted = append(f.inserted, documents...)
+	for _, opt := range opts {
+		f.ordered = opt.Ordered
+	}
+	return &mongo.InsertManyResult{}, f.insertErr
+}
+
+func duplicateKeyErrors(codes ...int) error {
+	var writeErrors []mongo.BulkWriteError
+	for _, code := range codes {
+		writeErrors = append(writeErrors, mongo.BulkWriteError{WriteError: mongo.WriteError{Code: code}})
+	}
+	return mongo.BulkWriteException{WriteErrors: writeErrors}
+}
+
+func TestPrepareTargetCollectionDropsByDefault(t *testing.T) {
+	target := &fakeMongoTarget{}
+	require.NoError(t, transfer.PrepareTargetCollection(context.Background(), target, false))
+	assert.True(t, target.dropped)
+}
+
+func TestAppendModeSkipsDropAndToleratesDuplicates(t *testing.T) {
+	target := &fakeMongoTarget{insertErr: duplicateKeyErrors(11000, 11000)}
+	ctx := context.Background()
+
+	require.NoError(t, transfer.PrepareTargetCollection(ctx, target, true))
+	assert.False(t, target.dropped)
+
+	skipped, err := transfer.InsertDocuments(ctx, target, []interface{}{"a", "b", "c"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, skipped)
+	require.NotNil(t, target.ordered)
+	assert.False(t, *target.ordered)
+}
+
+func TestAppendModeSurfacesOtherWriteErrors(t *testing.T) {
+	target := &fakeMongoTarget{insertErr: duplicateKeyErrors(11000, 121)}
+
+	_, err := transfer.InsertDocuments(context.Background(), target, []interface{}{"a", "b"}, true)
+	assert.Error(t, err)
+
+	target.insertErr = errors.New("connection reset")
+	_, err = transfer.InsertDocuments(context.Background(), target, []interface{}{"a"}, true)
+	assert.Error(t, err)
+}
+
+func TestDuplicatesFailWithoutAppendMode(t *testing.T) {
+	target := &fakeMongoTarget{insertErr: duplicateKeyErrors(11000)}
+
+	_, err := transfer.InsertDocuments(context.Background(), target, []interface{}{"a"}, false)
+	assert.Error(t, err)
+}
+
+func TestStripIDsRemovesIDBeforeInsert(t *testing.T) {
+	target := &fakeMongoTarget{}
+	batch := []interface{}{
+		bson.M{"_id": primitive.NewObjectID(), "name": "ada"},
+		bson.M{"_id": "custom-id", "name": "grace", "nested": bson.M{"_id": 7}},
+		bson.M{"name": "no id"},
+	}
+
+	transfer.StripIDs(batch)
+	_, err := transfer.InsertDocuments(context.Background(), target, batch, false)
+	require.NoError(t, err)
+
+	require.Len(t, target.inserted, 3)
+	for _, document := range target.inserted {
+		assert.NotContains(t, document.(bson.M), "_id")
+	}
+	assert.Equal(t, "grace", target.inserted[1].(bson.M)["name"])
+	assert.Equal(t, bson.M{"_id": 7}, target.inserted[1].(bson.M)["nested"], "only the top-level _id is the document identity")
+}
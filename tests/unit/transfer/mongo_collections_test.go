@@ -0,0 +1,85 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func collectionInfo(t *testing.T, doc bson.D) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestParseCollectionInfoView(t *testing.T) {
+	pipeline := bson.A{bson.D{{Key: "$match", Value: bson.D{{Key: "active", Value: true}}}}}
+	definition, err := transfer.ParseCollectionInfo(collectionInfo(t, bson.D{
+		{Key: "name", Value: "active_users"},
+		{Key: "type", Value: "view"},
+		{Key: "options", Value: bson.D{{Key: "viewOn", Value: "users"}, {Key: "pipeline", Value: pipeline}}},
+		{Key: "info", Value: bson.D{{Key: "readOnly", Value: true}}},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, transfer.CollectionKindView, definition.Kind)
+	assert.Equal(t, "users", definition.ViewOn)
+	require.Len(t, definition.Pipeline, 1)
+	assert.False(t, definition.CopiesData())
+	assert.Nil(t, definition.CreateCollectionOptions())
+}
+
+func TestParseCollectionInfoTimeSeries(t *testing.T) {
+	definition, err := transfer.ParseCollectionInfo(collectionInfo(t, bson.D{
+		{Key: "name", Value: "readings"},
+		{Key: "type", Value: "timeseries"},
+		{Key: "options", Value: bson.D{
+			{Key: "timeseries", Value: bson.D{
+				{Key: "timeField", Value: "ts"},
+				{Key: "metaField", Value: "sensor"},
+				{Key: "granularity", Value: "minutes"},
+				{Key: "bucketMaxSpanSeconds", Value: int32(86400)},
+			}},
+			{Key: "expireAfterSeconds", Value: int64(3600)},
+		}},
+	}))
+	require.NoError(t, err)
+
+	opts := definition.CreateCollectionOptions()
+	require.NotNil(t, opts)
+	require.NotNil(t, opts.TimeSeriesOptions)
+	assert.Equal(t, "ts", opts.TimeSeriesOptions.TimeField)
+	assert.Equal(t, "sensor", *opts.TimeSeriesOptions.MetaField)
+	assert.Equal(t, "minutes", *opts.TimeSeriesOptions.Granularity)
+	assert.Equal(t, int64(3600), *opts.ExpireAfterSeconds)
+	assert.True(t, definition.CopiesData())
+}
+
+func TestParseCollectionInfoRejectsViewWithoutSource(t *testing.T) {
+	_, err := transfer.ParseCollectionInfo(collectionInfo(t, bson.D{
+		{Key: "name", Value: "broken"},
+		{Key: "type", Value: "view"},
+		{Key: "options", Value: bson.D{}},
+	}))
+	assert.Error(t, err)
+}
+
+func TestOrderCollectionDefinitionsPutsViewsAfterTheirSource(t *testing.T) {
+	ordered := transfer.OrderCollectionDefinitions([]transfer.CollectionDefinition{
+		{Name: "recent_active", Kind: transfer.CollectionKindView, ViewOn: "active_users"},
+		{Name: "active_users", Kind: transfer.CollectionKindView, ViewOn: "users"},
+		{Name: "system.views", Kind: transfer.CollectionKindCollection},
+		{Name: "users", Kind: transfer.CollectionKindCollection},
+	})
+
+	var names []string
+	for _, definition := range ordered {
+		names = append(names, definition.Name)
+	}
+	assert.Equal(t, []string{"users", "active_users", "recent_active"}, names)
+}
@@ -0,0 +1,78 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newDocumentTransformer(t *testing.T, flags ...string) *transfer.DocumentTransformer {
+	t.Helper()
+	specs, err := transfer.ParseMongoTransformFlags(flags)
+	require.NoError(t, err)
+	transformer, err := transfer.NewDocumentTransformer(specs)
+	require.NoError(t, err)
+	return transformer
+}
+
+func TestDocumentTransformerHandlesNestedPaths(t *testing.T) {
+	transformer := newDocumentTransformer(t, "ssn:remove", "profile.email:mask", "profile.address.zip:const=00000")
+	document := bson.M{
+		"name": "ada",
+		"ssn":  "123-45-6789",
+		"profile": bson.M{
+			"email":   "ada@example.com",
+			"address": bson.D{{Key: "city", Value: "London"}, {Key: "zip", Value: "N1 9GU"}},
+		},
+	}
+
+	transformer.Apply(document)
+
+	assert.NotContains(t, document, "ssn")
+	assert.Equal(t, "ada", document["name"])
+	profile := document["profile"].(bson.M)
+	assert.Equal(t, "a**@example.com", profile["email"])
+	assert.Equal(t, bson.D{{Key: "city", Value: "London"}, {Key: "zip", Value: "00000"}}, profile["address"])
+}
+
+func TestDocumentTransformerIgnoresMissingFields(t *testing.T) {
+	transformer := newDocumentTransformer(t, "ssn:remove", "profile.email:hash", "name.first:mask")
+	document := bson.M{"name": "ada", "profile": bson.M{"phone": "555"}}
+
+	transformer.Apply(document)
+
+	assert.Equal(t, bson.M{"name": "ada", "profile": bson.M{"phone": "555"}}, document)
+}
+
+func TestDocumentTransformerTraversesArrays(t *testing.T) {
+	transformer := newDocumentTransformer(t, "contacts.email:hash", "contacts.notes:remove", "tags:mask")
+	document := bson.M{
+		"contacts": bson.A{
+			bson.M{"email": "a@example.com", "notes": "vip"},
+			bson.D{{Key: "email", Value: "b@example.com"}, {Key: "notes", Value: "late payer"}},
+			"not a document",
+		},
+		"tags": bson.A{"internal", "beta"},
+	}
+
+	transformer.Apply(document)
+
+	contacts := document["contacts"].(bson.A)
+	first := contacts[0].(bson.M)
+	assert.Equal(t, transfer.HashValue("a@example.com"), first["email"])
+	assert.NotContains(t, first, "notes")
+	assert.Equal(t, bson.D{{Key: "email", Value: transfer.HashValue("b@example.com")}}, contacts[1])
+	assert.Equal(t, "not a document", contacts[2])
+	assert.Equal(t, bson.A{"i*******", "b***"}, document["tags"])
+}
+
+func TestParseMongoTransformFlagsRejectsInvalidSpecs(t *testing.T) {
+	for _, flag := range []string{"email", "email:", ":hash", "profile..email:hash", "$where:remove", "email:shuffle"} {
+		_, err := transfer.ParseMongoTransformFlags([]string{flag})
+		assert.Error(t, err, flag)
+	}
+}
@@ -0,0 +1,75 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func eventsTable() schema.Table {
+	return schema.Table{
+		Name:   "events",
+		Schema: "public",
+		Columns: []schema.Column{
+			{Name: "tenant", DataType: "integer"},
+			{Name: "seq", DataType: "bigint"},
+			{Name: "External Ref", DataType: "text"},
+			{Name: "note", DataType: "text", IsNullable: true},
+		},
+	}
+}
+
+func TestOrderKeyPrefersPrimaryKey(t *testing.T) {
+	table := eventsTable()
+	table.PrimaryKeys = []string{"tenant", "seq"}
+	table.Indexes = []schema.Index{{Name: "events_ref_key", Columns: []string{`"External Ref"`}, IsUnique: true}}
+
+	key, source := transfer.OrderKey(table)
+	assert.Equal(t, []string{"tenant", "seq"}, key)
+	assert.Equal(t, "primary key", source)
+}
+
+func TestOrderKeyPicksNarrowestUsableUniqueIndex(t *testing.T) {
+	table := eventsTable()
+	table.Indexes = []schema.Index{
+		{Name: "events_tenant_seq_key", Columns: []string{"tenant", "seq"}, IsUnique: true},
+		{Name: "events_note_key", Columns: []string{"note"}, IsUnique: true},
+		{Name: "events_live_seq_key", Columns: []string{"seq"}, IsUnique: true, Partial: true},
+		{Name: "events_tenant_idx", Columns: []string{"tenant"}},
+		{Name: "events_ref_key", Columns: []string{`"External Ref"`}, IsUnique: true},
+	}
+
+	key, source := transfer.OrderKey(table)
+	assert.Equal(t, []string{"External Ref"}, key)
+	assert.Equal(t, "unique index events_ref_key", source)
+
+	job := &transfer.DataTransferJob{Table: table}
+	assert.Equal(t,
+		`SELECT "tenant", "seq", "External Ref", "note" FROM "public"."events" ORDER BY "External Ref" OFFSET 0 LIMIT 50`,
+		job.BuildSelectQuery(0, 50))
+}
+
+func TestOrderKeyNoneWithoutUsableIndex(t *testing.T) {
+	table := eventsTable()
+	table.Indexes = []schema.Index{
+		{Name: "events_note_key", Columns: []string{"note"}, IsUnique: true},
+		{Name: "events_lower_ref_key", Columns: []string{`lower("External Ref")`}, IsUnique: true},
+	}
+
+	key, _ := transfer.OrderKey(table)
+	assert.Nil(t, key)
+
+	job := &transfer.DataTransferJob{Table: table, FullRead: true}
+	assert.Equal(t,
+		`SELECT "tenant", "seq", "External Ref", "note" FROM "public"."events"`,
+		job.BuildFullReadQuery())
+}
+
+func TestValidateUnkeyedStrategy(t *testing.T) {
+	assert.NoError(t, transfer.ValidateUnkeyedStrategy(""))
+	assert.NoError(t, transfer.ValidateUnkeyedStrategy(transfer.UnkeyedFullRead))
+	assert.Error(t, transfer.ValidateUnkeyedStrategy("random"))
+}
@@ -0,0 +1,82 @@
+package transfer_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPoolBoundsConcurrency(t *testing.T) {
+	const workers = 3
+	pool := transfer.NewWorkerPool(workers, 100)
+	pool.Start(context.Background())
+
+	var running, maxRunning, completed int32
+	for i := 0; i < 20; i++ {
+		err := pool.SubmitJob(context.Background(), transfer.JobFunc(func() error {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if now <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, now) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&completed, 1)
+			return nil
+		}))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, pool.Wait())
+	assert.Equal(t, int32(20), completed)
+	assert.LessOrEqual(t, maxRunning, int32(workers))
+	assert.Greater(t, maxRunning, int32(1), "jobs should run in parallel")
+}
+
+func TestWorkerPoolReturnsFirstErrorAndRunsTheRest(t *testing.T) {
+	pool := transfer.NewWorkerPool(1, 100)
+	pool.Start(context.Background())
+
+	failure := errors.New("table failed")
+	var completed int32
+	require.NoError(t, pool.SubmitJob(context.Background(), transfer.JobFunc(func() error { return failure })))
+	require.NoError(t, pool.SubmitJob(context.Background(), transfer.JobFunc(func() error {
+		atomic.AddInt32(&completed, 1)
+		return errors.New("later failure")
+	})))
+
+	assert.ErrorIs(t, pool.Wait(), failure)
+	assert.Equal(t, int32(1), completed)
+}
+
+func TestRunTableJobsFailsAfterEveryTableRuns(t *testing.T) {
+	tables := []schema.Table{{Name: "users"}, {Name: "orders"}, {Name: "items"}}
+	failure := errors.New("copy failed")
+
+	var mu sync.Mutex
+	var ran []string
+	err := transfer.RunTableJobs(context.Background(), 2, 100, tables, func(table schema.Table) error {
+		mu.Lock()
+		ran = append(ran, table.Name)
+		mu.Unlock()
+		if table.Name == "orders" {
+			return failure
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, failure)
+	assert.ElementsMatch(t, []string{"users", "orders", "items"}, ran)
+	assert.NoError(t, transfer.RunTableJobs(context.Background(), 2, 100, tables, func(schema.Table) error { return nil }))
+}
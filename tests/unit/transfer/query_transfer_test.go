@@ -0,0 +1,94 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryColumnsFromResultMetadata(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("id").OfType("INT8", int64(0)),
+		sqlmock.NewColumn("email").OfType("VARCHAR", "").WithLength(255),
+		sqlmock.NewColumn("note").OfType("VARCHAR", "").WithLength(-5),
+		sqlmock.NewColumn("code").OfType("BPCHAR", "").WithLength(3),
+		sqlmock.NewColumn("total").OfType("NUMERIC", "").WithPrecisionAndScale(12, 2),
+		sqlmock.NewColumn("ratio").OfType("NUMERIC", "").WithPrecisionAndScale(65535, 65531),
+		sqlmock.NewColumn("tags").OfType("_TEXT", []byte{}),
+		sqlmock.NewColumn("placed_at").OfType("TIMESTAMPTZ", ""),
+		sqlmock.NewColumn("status").OfType("", ""),
+	))
+
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+	defer rows.Close()
+	columnTypes, err := rows.ColumnTypes()
+	require.NoError(t, err)
+
+	columns, err := transfer.QueryColumns(columnTypes)
+	require.NoError(t, err)
+
+	length := 255
+	assert.Equal(t, []schema.Column{
+		{Name: "id", DataType: "int8", IsNullable: true, Position: 1},
+		{Name: "email", DataType: "varchar", MaxLength: &length, IsNullable: true, Position: 2},
+		{Name: "note", DataType: "varchar", IsNullable: true, Position: 3},
+		{Name: "code", DataType: "char(3)", IsNullable: true, Position: 4},
+		{Name: "total", DataType: "numeric(12,2)", IsNullable: true, Position: 5},
+		{Name: "ratio", DataType: "numeric", IsNullable: true, Position: 6},
+		{Name: "tags", DataType: "text[]", IsNullable: true, Position: 7},
+		{Name: "placed_at", DataType: "timestamptz", IsNullable: true, Position: 8},
+		{Name: "status", DataType: "text", IsNullable: true, Position: 9},
+	}, columns)
+}
+
+func TestQueryColumnsRejectsDuplicateNames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "id"}))
+	rows, err := db.Query("SELECT")
+	require.NoError(t, err)
+	defer rows.Close()
+	columnTypes, err := rows.ColumnTypes()
+	require.NoError(t, err)
+
+	_, err = transfer.QueryColumns(columnTypes)
+	assert.ErrorContains(t, err, `more than one column named "id"`)
+}
+
+func TestSplitTargetTableDefaultsToPublic(t *testing.T) {
+	schemaName, table := transfer.SplitTargetTable("reporting.daily_orders")
+	assert.Equal(t, "reporting", schemaName)
+	assert.Equal(t, "daily_orders", table)
+
+	schemaName, table = transfer.SplitTargetTable("daily_orders")
+	assert.Equal(t, "public", schemaName)
+	assert.Equal(t, "daily_orders", table)
+}
+
+func TestQueryTransferValidatesOptions(t *testing.T) {
+	source := postgresConfig("source.internal", "shop")
+	target := postgresConfig("target.internal", "shop")
+	query := "SELECT o.id, c.email FROM orders o JOIN customers c ON c.id = o.customer_id"
+
+	_, err := transfer.NewService(source, target, transfer.Options{Query: query, Logger: logger.NewLogger(false)})
+	assert.ErrorContains(t, err, "--target-table")
+
+	_, err = transfer.NewService(source, target, transfer.Options{Query: query, TargetTable: "reporting.orders", SchemaDiff: true, Logger: logger.NewLogger(false)})
+	assert.ErrorContains(t, err, "--schema-diff")
+
+	_, err = transfer.NewService(source, target, transfer.Options{Query: query, TargetTable: "reporting.orders", UseCopy: true, Logger: logger.NewLogger(false)})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,105 @@
+package transfer_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferReportAggregatesMixedJobs(t *testing.T) {
+	report := transfer.NewTransferReport()
+
+	// Two ranges of the same table finish concurrently and must merge.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Record(transfer.TableResult{Schema: "public", Table: "orders", RowsAttempted: 500, RowsSucceeded: 500}, nil)
+		}()
+	}
+	wg.Wait()
+
+	// A failed batch: rows were read but rolled back.
+	report.Record(transfer.TableResult{Schema: "public", Table: "users", RowsAttempted: 1200, RowsSucceeded: 1000}, nil)
+	report.Record(transfer.TableResult{Schema: "public", Table: "users"}, errors.New("duplicate key"))
+	report.Record(transfer.TableResult{Schema: "public", Table: "users"}, errors.New("later error"))
+
+	report.Record(transfer.TableResult{Schema: "public", Table: "audit", Status: transfer.StatusSkipped}, nil)
+
+	results := report.Results()
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "public.audit", results[0].QualifiedName())
+	assert.Equal(t, transfer.StatusSkipped, results[0].Status)
+
+	assert.Equal(t, transfer.StatusSucceeded, results[1].Status)
+	assert.Equal(t, int64(1000), results[1].RowsAttempted)
+	assert.Equal(t, int64(1000), results[1].RowsSucceeded)
+	assert.Zero(t, results[1].RowsFailed)
+
+	users := results[2]
+	assert.Equal(t, transfer.StatusFailed, users.Status)
+	assert.Equal(t, int64(200), users.RowsFailed)
+	assert.Equal(t, "duplicate key", users.FirstError)
+
+	totals := report.Totals()
+	assert.Equal(t, transfer.StatusFailed, totals.Status)
+	assert.Equal(t, int64(2200), totals.RowsAttempted)
+	assert.Equal(t, int64(2000), totals.RowsSucceeded)
+	assert.Equal(t, int64(200), totals.RowsFailed)
+
+	require.Len(t, report.Failed(), 1)
+	assert.Equal(t, "users", report.Failed()[0].Table)
+}
+
+func TestTransferReportSkippedDocumentsAreNotFailures(t *testing.T) {
+	report := transfer.NewTransferReport()
+	report.Record(transfer.TableResult{Table: "events", RowsAttempted: 10, RowsSucceeded: 7, RowsSkipped: 3}, nil)
+
+	result := report.Results()[0]
+	assert.Equal(t, "events", result.QualifiedName())
+	assert.Zero(t, result.RowsFailed)
+	assert.Equal(t, transfer.StatusSucceeded, result.Status)
+}
+
+func TestSummaryFormatsThroughput(t *testing.T) {
+	total := transfer.TableResult{
+		RowsSucceeded: 1234567,
+		Bytes:         2_300_000_000,
+		Duration:      3*time.Minute + 12*time.Second,
+	}
+	total.RowsPerSecond = float64(total.RowsSucceeded) / total.Duration.Seconds()
+
+	assert.Equal(t, "Transferred 1,234,567 rows (≈2.1 GB) in 3m 12s — 6.4k rows/s", transfer.Summary(total))
+	assert.Equal(t, "Transferred 0 rows", transfer.Summary(transfer.TableResult{}))
+}
+
+func TestFormatHelpers(t *testing.T) {
+	assert.Equal(t, "999", transfer.FormatCount(999))
+	assert.Equal(t, "1,000", transfer.FormatCount(1000))
+	assert.Equal(t, "-12,345", transfer.FormatCount(-12345))
+
+	assert.Equal(t, "42", transfer.FormatRate(42))
+	assert.Equal(t, "2.5M", transfer.FormatRate(2_500_000))
+}
+
+func TestReportComputesPerTableRates(t *testing.T) {
+	report := transfer.NewTransferReport()
+	report.Record(transfer.TableResult{Table: "events", RowsAttempted: 100, RowsSucceeded: 100, Bytes: 10, Duration: time.Second}, nil)
+	report.Record(transfer.TableResult{Table: "events", RowsAttempted: 100, RowsSucceeded: 100, Bytes: 10, Duration: 2 * time.Second}, nil)
+	report.Finish()
+
+	results := report.Results()
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(20), results[0].Bytes)
+	assert.Equal(t, 2*time.Second, results[0].Duration)
+	assert.InDelta(t, 100.0, results[0].RowsPerSecond, 0.001)
+	assert.Greater(t, report.Elapsed(), time.Duration(0))
+}
@@ -0,0 +1,51 @@
+package transfer_test
+
+import (
+	"testing"
+
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serverConfig(dbType, host string, port int, database string) *appconfig.Config {
+	return &appconfig.Config{Database: appconfig.DatabaseConfig{Type: dbType, Host: host, Port: port, Database: database}}
+}
+
+func TestSameServerDetection(t *testing.T) {
+	tests := []struct {
+		name   string
+		source *appconfig.Config
+		target *appconfig.Config
+		want   bool
+	}{
+		{"same host and port", serverConfig("postgres", "db.internal", 5432, "shop"), serverConfig("postgres", "db.internal", 5432, "shop_copy"), true},
+		{"loopback spellings", serverConfig("postgres", "localhost", 5433, "shop"), serverConfig("postgres", "127.0.0.1", 5433, "staging"), true},
+		{"host case and default port", serverConfig("postgres", "DB.internal", 0, "shop"), serverConfig("postgres", "db.internal", 5432, "staging"), true},
+		{"different port", serverConfig("postgres", "localhost", 5432, "shop"), serverConfig("postgres", "localhost", 5433, "shop"), false},
+		{"different host", serverConfig("postgres", "primary.internal", 5432, "shop"), serverConfig("postgres", "replica.internal", 5432, "shop"), false},
+		{"not postgres", serverConfig("mongo", "localhost", 27017, "shop"), serverConfig("mongo", "localhost", 27017, "staging"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, transfer.SameServer(tt.source, tt.target))
+		})
+	}
+}
+
+func TestServerSideCopyQueries(t *testing.T) {
+	table := schema.Table{
+		Schema:  "public",
+		Name:    "Orders",
+		Columns: []schema.Column{{Name: "id"}, {Name: "total"}},
+	}
+
+	sourceQuery, insertQuery := transfer.ServerSideCopyQueries(table)
+
+	assert.Equal(t, `SELECT row_to_json(t) FROM (SELECT "id", "total" FROM "public"."Orders") t`, sourceQuery)
+	assert.Equal(t, `INSERT INTO "public"."Orders" ("id", "total") SELECT r."id", r."total" FROM dblink($1, $2) AS source(doc json), `+
+		`LATERAL json_populate_record(NULL::"public"."Orders", source.doc) AS r ON CONFLICT DO NOTHING`, insertQuery)
+}
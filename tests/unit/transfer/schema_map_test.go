@@ -0,0 +1,77 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchemaMapFlags(t *testing.T) {
+	parsed, err := transfer.ParseSchemaMapFlags([]string{"app:public", "billing:finance", "app:public"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "public", "billing": "finance"}, parsed)
+
+	for _, value := range []string{"app", "app:", ":public"} {
+		_, err := transfer.ParseSchemaMapFlags([]string{value})
+		assert.Error(t, err, value)
+	}
+
+	_, err = transfer.ParseSchemaMapFlags([]string{"app:public", "app:finance"})
+	assert.EqualError(t, err, "schema app is mapped to both public and finance")
+}
+
+func TestInsertTargetsUseMappedSchema(t *testing.T) {
+	table := accountsTable()
+	table.Schema = "app"
+	job := &transfer.DataTransferJob{Table: table, SchemaMap: map[string]string{"app": "public"}}
+
+	assert.Equal(t,
+		`INSERT INTO "public"."UserAccounts" ("AccountID", "DisplayName") VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		job.BuildInsertQuery())
+	assert.Equal(t, `COPY "public"."UserAccounts" ("AccountID", "DisplayName") FROM STDIN`, job.BuildCopyQuery())
+	assert.Contains(t, job.BuildKeysetQuery("AccountID", false, 10), `FROM "app"."UserAccounts"`,
+		"rows are still read from the source schema")
+}
+
+func TestMapSchemaIsPostgresOnly(t *testing.T) {
+	source := postgresConfig("localhost", "shop")
+	source.Database.Type = "mongo"
+	target := postgresConfig("localhost", "shop_copy")
+	target.Database.Type = "mongo"
+
+	_, err := transfer.NewService(source, target, transfer.Options{
+		SchemaMap: map[string]string{"app": "public"},
+		Logger:    logger.NewLogger(false),
+	})
+	assert.EqualError(t, err, "--map-schema is only supported for PostgreSQL transfers")
+
+	_, err = transfer.NewService(postgresConfig("localhost", "shop"), postgresConfig("localhost", "shop_copy"), transfer.Options{
+		SchemaMap:  map[string]string{"app": "public"},
+		SchemaDiff: true,
+		Logger:     logger.NewLogger(false),
+	})
+	assert.EqualError(t, err, "--map-schema cannot be combined with --schema-diff")
+}
+
+func TestRefreshMatViewsRejectsRenamingOptions(t *testing.T) {
+	source, target := postgresConfig("localhost", "shop"), postgresConfig("localhost", "shop_copy")
+
+	_, err := transfer.NewService(source, target, transfer.Options{
+		RefreshMatViews: true,
+		SchemaMap:       map[string]string{"app": "public"},
+		Logger:          logger.NewLogger(false),
+	})
+	assert.EqualError(t, err, "--refresh-matviews cannot be combined with --map-schema")
+
+	_, err = transfer.NewService(source, target, transfer.Options{
+		RefreshMatViews: true,
+		IdentifierCase:  schema.IdentifierCaseLower,
+		Logger:          logger.NewLogger(false),
+	})
+	assert.EqualError(t, err, "--refresh-matviews cannot be combined with --identifier-case")
+}
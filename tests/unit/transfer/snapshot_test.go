@@ -0,0 +1,113 @@
+package transfer_test
+
+import (
+	"database/sql"
+	"io"
+	"regexp"
+	"testing"
+
+	appconfig "github.com/kadirbelkuyu/DBRTS/internal/config"
+	"github.com/kadirbelkuyu/DBRTS/internal/database"
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+	"github.com/kadirbelkuyu/DBRTS/pkg/progress"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTransactionSnapshotSQL(t *testing.T) {
+	assert.Equal(t, "SET TRANSACTION SNAPSHOT '00000003-0000001B-1'", transfer.SetTransactionSnapshotSQL("00000003-0000001B-1"))
+	assert.Equal(t, "SET TRANSACTION SNAPSHOT 'a''b'", transfer.SetTransactionSnapshotSQL("a'b"))
+
+	opts := transfer.SnapshotTxOptions()
+	assert.Equal(t, sql.LevelRepeatableRead, opts.Isolation, "importing a snapshot requires REPEATABLE READ")
+	assert.True(t, opts.ReadOnly)
+}
+
+func TestDataTransferJobReadsBatchesFromSnapshot(t *testing.T) {
+	sourceDB, source, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sourceDB.Close()
+	targetDB, target, err := sqlmock.New()
+	require.NoError(t, err)
+	defer targetDB.Close()
+
+	table := accountsTable()
+	table.RowCount = 1
+
+	source.ExpectBegin()
+	source.ExpectExec(regexp.QuoteMeta("SET TRANSACTION SNAPSHOT '00000003-0000001B-1'")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	source.ExpectQuery(`SELECT "AccountID", "DisplayName" FROM "public"."UserAccounts"`).
+		WillReturnRows(sqlmock.NewRows([]string{"AccountID", "DisplayName"}).AddRow(1, "Ada"))
+	source.ExpectRollback()
+
+	target.ExpectBegin()
+	target.ExpectPrepare("INSERT INTO")
+	target.ExpectExec("INSERT INTO").WithArgs(1, "Ada").WillReturnResult(sqlmock.NewResult(0, 1))
+	target.ExpectCommit()
+
+	job := &transfer.DataTransferJob{
+		Table:       table,
+		SourceConn:  &database.Connection{DB: sourceDB},
+		TargetConn:  &database.Connection{DB: targetDB},
+		BatchSize:   10,
+		ProgressBar: progress.NewMultiTo(io.Discard, 1, "Data transfer", false).Table("public.UserAccounts", 1),
+		Logger:      logger.NewLogger(false),
+		Snapshot:    "00000003-0000001B-1",
+	}
+	require.NoError(t, job.Execute())
+
+	assert.NoError(t, source.ExpectationsWereMet())
+	assert.NoError(t, target.ExpectationsWereMet())
+	assert.Equal(t, int64(1), job.Result().RowsSucceeded)
+}
+
+func TestConsistentSnapshotRequiresPostgres(t *testing.T) {
+	mongo := &appconfig.Config{Database: appconfig.DatabaseConfig{Type: "mongo", URI: "mongodb://localhost:27017", Database: "shop"}}
+
+	_, err := transfer.NewService(mongo, mongo, transfer.Options{ConsistentSnapshot: true, Logger: logger.NewLogger(false)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--consistent-snapshot")
+}
+
+func TestSnapshotTableChecksumReadsFromSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	query := transfer.BuildChecksumQuery(accountsTable(), "")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SET TRANSACTION SNAPSHOT '00000003-0000001B-1'")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WillReturnRows(sqlmock.NewRows([]string{"AccountID", "DisplayName"}).AddRow(1, "Ada"))
+	mock.ExpectRollback()
+
+	sum, rows, err := transfer.SnapshotTableChecksum(db, "00000003-0000001B-1", query)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	assert.Equal(t, hashRows([]interface{}{1, "Ada"}).Sum(), sum)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSnapshotRowCountReadsFromSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SET TRANSACTION SNAPSHOT '00000003-0000001B-1'")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM "Billing"."Accounts"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+	mock.ExpectRollback()
+
+	count, err := transfer.SnapshotRowCount(db, "00000003-0000001B-1", schema.Table{Schema: "Billing", Name: "Accounts"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,125 @@
+package transfer_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRangeCoversIntervalWithoutGaps(t *testing.T) {
+	ranges := transfer.SplitRange(1, 10, 3)
+
+	assert.Equal(t, []transfer.RowRange{
+		{Start: 1, End: 5},
+		{Start: 5, End: 9},
+		{Start: 9, End: 11},
+	}, ranges)
+}
+
+func TestSplitRangeCapsPartsAtSpan(t *testing.T) {
+	ranges := transfer.SplitRange(5, 6, 8)
+
+	assert.Equal(t, []transfer.RowRange{{Start: 5, End: 6}, {Start: 6, End: 7}}, ranges)
+}
+
+func TestSplitRangeEmptyInterval(t *testing.T) {
+	assert.Nil(t, transfer.SplitRange(10, 1, 4))
+	assert.Equal(t, []transfer.RowRange{{Start: 3, End: 4}}, transfer.SplitRange(3, 3, 0))
+}
+
+func TestSplitRangeFullInt64Interval(t *testing.T) {
+	ranges := transfer.SplitRange(math.MinInt64, math.MaxInt64, 4)
+
+	require.Len(t, ranges, 4)
+	assert.Equal(t, int64(math.MinInt64), ranges[0].Start)
+	for i := 1; i < len(ranges); i++ {
+		assert.Equal(t, ranges[i-1].End, ranges[i].Start)
+		assert.False(t, ranges[i-1].ToMax)
+	}
+	assert.Equal(t, transfer.RowRange{Start: math.MaxInt64/2 + 1, End: math.MaxInt64, ToMax: true}, ranges[3])
+}
+
+func TestSplitRangeEndingAtMaxInt64(t *testing.T) {
+	ranges := transfer.SplitRange(math.MaxInt64-2, math.MaxInt64, 2)
+
+	assert.Equal(t, []transfer.RowRange{
+		{Start: math.MaxInt64 - 2, End: math.MaxInt64},
+		{Start: math.MaxInt64, End: math.MaxInt64, ToMax: true},
+	}, ranges)
+	assert.Equal(t, "[9223372036854775807, 9223372036854775807]", ranges[1].String())
+}
+
+func TestSplitRangeStartingAtMinInt64(t *testing.T) {
+	ranges := transfer.SplitRange(math.MinInt64, math.MinInt64+3, 2)
+
+	assert.Equal(t, []transfer.RowRange{
+		{Start: math.MinInt64, End: math.MinInt64 + 2},
+		{Start: math.MinInt64 + 2, End: math.MinInt64 + 4},
+	}, ranges)
+}
+
+func TestShouldSplitTableRequiresNumericSinglePrimaryKey(t *testing.T) {
+	numeric := schema.Table{
+		Name:        "events",
+		Columns:     []schema.Column{{Name: "id", DataType: "bigint"}, {Name: "payload", DataType: "jsonb"}},
+		PrimaryKeys: []string{"id"},
+		RowCount:    5_000_000,
+	}
+	assert.True(t, transfer.ShouldSplitTable(numeric, 1_000_000))
+	assert.False(t, transfer.ShouldSplitTable(numeric, 0), "zero threshold disables splitting")
+	assert.False(t, transfer.ShouldSplitTable(numeric, 10_000_000), "tables under the threshold stay single-threaded")
+
+	uuidKey := numeric
+	uuidKey.Columns = []schema.Column{{Name: "id", DataType: "uuid"}}
+	assert.False(t, transfer.ShouldSplitTable(uuidKey, 1_000_000))
+
+	composite := numeric
+	composite.PrimaryKeys = []string{"id", "payload"}
+	assert.False(t, transfer.ShouldSplitTable(composite, 1_000_000))
+
+	noKey := numeric
+	noKey.PrimaryKeys = nil
+	assert.False(t, transfer.ShouldSplitTable(noKey, 1_000_000))
+}
+
+func TestPartitionByRowsWithNoTables(t *testing.T) {
+	pending, empty, total := transfer.PartitionByRows(nil)
+
+	assert.Empty(t, pending)
+	assert.Empty(t, empty)
+	assert.Zero(t, total)
+}
+
+func TestPartitionByRowsWhenEveryTableIsEmpty(t *testing.T) {
+	tables := []schema.Table{
+		{Schema: "public", Name: "a"},
+		{Schema: "public", Name: "b"},
+	}
+
+	pending, empty, total := transfer.PartitionByRows(tables)
+
+	assert.Empty(t, pending, "no table should start a copy or a progress bar")
+	assert.Len(t, empty, 2)
+	assert.Zero(t, total)
+}
+
+func TestPartitionByRowsMixed(t *testing.T) {
+	tables := []schema.Table{
+		{Schema: "public", Name: "a", RowCount: 10},
+		{Schema: "public", Name: "b"},
+		{Schema: "public", Name: "c", RowCount: 5},
+	}
+
+	pending, empty, total := transfer.PartitionByRows(tables)
+
+	require.Len(t, pending, 2)
+	assert.Equal(t, "a", pending[0].Name)
+	assert.Equal(t, "c", pending[1].Name)
+	require.Len(t, empty, 1)
+	assert.Equal(t, int64(15), total)
+}
@@ -0,0 +1,128 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinTransforms(t *testing.T) {
+	apply := func(spec string, value interface{}) interface{} {
+		fn, err := transfer.NewTransform(spec)
+		require.NoError(t, err, spec)
+		return fn(value)
+	}
+
+	assert.Equal(t, "j***@example.com", apply("mask", "jane@example.com"))
+	assert.Equal(t, "s*****", apply("mask", []byte("secret")))
+	assert.Nil(t, apply("mask", nil))
+
+	assert.Equal(t, "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b", apply("hash", "secret"))
+	assert.Equal(t, apply("hash", "secret"), apply("hash", []byte("secret")), "hash is stable across value types")
+	assert.Nil(t, apply("hash", nil))
+
+	assert.Nil(t, apply("nullify", "anything"))
+
+	assert.Equal(t, "0", apply("const=0", 85000))
+	assert.Equal(t, "", apply("const", "value"))
+}
+
+func TestNewTransformRejectsUnknownAndBadArguments(t *testing.T) {
+	_, err := transfer.NewTransform("scramble")
+	assert.ErrorContains(t, err, `unknown transform "scramble"`)
+
+	_, err = transfer.NewTransform("mask=3")
+	assert.Error(t, err)
+}
+
+func TestRegisterTransformExtendsRegistry(t *testing.T) {
+	transfer.RegisterTransform("upper-test", func(string) (transfer.TransformFunc, error) {
+		return func(interface{}) interface{} { return "X" }, nil
+	})
+
+	fn, err := transfer.NewTransform("upper-test")
+	require.NoError(t, err)
+	assert.Equal(t, "X", fn("y"))
+	assert.Contains(t, transfer.TransformNames(), "upper-test")
+}
+
+func TestColumnTransformsTargetQualifiedColumns(t *testing.T) {
+	table := schema.Table{
+		Schema: "public",
+		Name:   "users",
+		Columns: []schema.Column{
+			{Name: "id", DataType: "integer"},
+			{Name: "email", DataType: "character varying"},
+			{Name: "salary", DataType: "integer"},
+			{Name: "notes", DataType: "text"},
+		},
+	}
+
+	specs, err := transfer.ParseTransformFlags([]string{
+		"public.users.email:mask",
+		"public.users.salary:const=0",
+		"public.orders.notes:nullify",
+	})
+	require.NoError(t, err)
+
+	funcs, err := transfer.ColumnTransforms(table, specs)
+	require.NoError(t, err)
+
+	values := []interface{}{int64(7), "ann@example.com", int64(120000), "keep me"}
+	transfer.ApplyTransforms(values, funcs)
+
+	assert.Equal(t, []interface{}{int64(7), "a**@example.com", "0", "keep me"}, values)
+}
+
+func TestColumnTransformsNilWithoutMatches(t *testing.T) {
+	table := schema.Table{Schema: "public", Name: "users", Columns: []schema.Column{{Name: "id"}}}
+
+	funcs, err := transfer.ColumnTransforms(table, map[string]string{"public.orders.id": "hash"})
+	require.NoError(t, err)
+	assert.Nil(t, funcs)
+}
+
+func TestColumnTransformsRejectTextTransformsOnOtherTypes(t *testing.T) {
+	short := 32
+	table := schema.Table{
+		Schema: "public",
+		Name:   "users",
+		Columns: []schema.Column{
+			{Name: "salary", DataType: "integer"},
+			{Name: "hired_at", DataType: "timestamp without time zone"},
+			{Name: "code", DataType: "character varying", MaxLength: &short},
+			{Name: "email", DataType: "text"},
+		},
+	}
+
+	_, err := transfer.ColumnTransforms(table, map[string]string{"public.users.salary": "mask"})
+	assert.ErrorContains(t, err, `transform "mask" on public.users.salary needs a text column, not integer`)
+
+	_, err = transfer.ColumnTransforms(table, map[string]string{"public.users.hired_at": "hash"})
+	assert.ErrorContains(t, err, "needs a text column")
+
+	_, err = transfer.ColumnTransforms(table, map[string]string{"public.users.code": "hash"})
+	assert.ErrorContains(t, err, "needs room for 64 characters, but the column holds 32")
+
+	_, err = transfer.ColumnTransforms(table, map[string]string{
+		"public.users.salary": "const=0",
+		"public.users.code":   "mask",
+		"public.users.email":  "hash",
+	})
+	assert.NoError(t, err)
+}
+
+func TestParseTransformFlagsValidates(t *testing.T) {
+	_, err := transfer.ParseTransformFlags([]string{"users.email:mask"})
+	assert.Error(t, err)
+
+	_, err = transfer.ParseTransformFlags([]string{"public.users.email"})
+	assert.Error(t, err)
+
+	_, err = transfer.ParseTransformFlags([]string{"public.users.email:bogus"})
+	assert.Error(t, err)
+}
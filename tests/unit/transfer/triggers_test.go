@@ -0,0 +1,74 @@
+package transfer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+	"github.com/kadirbelkuyu/DBRTS/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerStatements(t *testing.T) {
+	disable, enable := transfer.TriggerStatements(schema.Table{Schema: "audit", Name: "Events"}, schema.IdentifierCaseLower)
+
+	assert.Equal(t, `ALTER TABLE "audit"."events" DISABLE TRIGGER ALL`, disable)
+	assert.Equal(t, `ALTER TABLE "audit"."events" ENABLE TRIGGER ALL`, enable)
+}
+
+func TestWithTriggersDisabledWrapsLoad(t *testing.T) {
+	var steps []string
+	exec := func(query string) error {
+		steps = append(steps, query)
+		return nil
+	}
+	load := func() error {
+		steps = append(steps, "load")
+		return nil
+	}
+
+	err := transfer.WithTriggersDisabled(exec, logger.NewLogger(false), schema.Table{Schema: "public", Name: "orders"}, "", load)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`ALTER TABLE "public"."orders" DISABLE TRIGGER ALL`,
+		"load",
+		`ALTER TABLE "public"."orders" ENABLE TRIGGER ALL`,
+	}, steps)
+}
+
+func TestWithTriggersDisabledReenablesAfterFailedLoad(t *testing.T) {
+	var steps []string
+	exec := func(query string) error {
+		steps = append(steps, query)
+		return nil
+	}
+	loadErr := errors.New("insert failed")
+
+	err := transfer.WithTriggersDisabled(exec, logger.NewLogger(false), schema.Table{Schema: "public", Name: "orders"}, "", func() error { return loadErr })
+
+	assert.ErrorIs(t, err, loadErr)
+	require.Len(t, steps, 2)
+	assert.Contains(t, steps[1], "ENABLE TRIGGER ALL")
+}
+
+func TestWithTriggersDisabledLoadsAnywayWhenDisableIsDenied(t *testing.T) {
+	var steps []string
+	exec := func(query string) error {
+		steps = append(steps, query)
+		return errors.New("must be owner of table orders")
+	}
+	loaded := false
+
+	err := transfer.WithTriggersDisabled(exec, logger.NewLogger(false), schema.Table{Schema: "public", Name: "orders"}, "", func() error {
+		loaded = true
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, loaded)
+	assert.Len(t, steps, 1, "no ENABLE is issued when DISABLE did not take effect")
+}
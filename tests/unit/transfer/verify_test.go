@@ -0,0 +1,88 @@
+package transfer_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hashRows(rows ...[]interface{}) *transfer.RowHasher {
+	hasher := transfer.NewRowHasher()
+	for _, row := range rows {
+		hasher.Add(row)
+	}
+	return hasher
+}
+
+func TestRowHasherIsDeterministic(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	rows := [][]interface{}{
+		{int64(1), "alice", created, nil},
+		{int64(2), []byte("bob"), created.UTC(), true},
+	}
+
+	first := hashRows(rows...)
+	second := hashRows(rows...)
+
+	assert.Equal(t, int64(2), first.Rows())
+	assert.Equal(t, first.Sum(), second.Sum())
+	assert.Len(t, first.Sum(), 64)
+}
+
+func TestRowHasherDetectsDifferences(t *testing.T) {
+	base := hashRows([]interface{}{int64(1), "a"}, []interface{}{int64(2), "b"}).Sum()
+
+	assert.NotEqual(t, base, hashRows([]interface{}{int64(2), "b"}, []interface{}{int64(1), "a"}).Sum(), "row order matters")
+	assert.NotEqual(t, base, hashRows([]interface{}{int64(1), "a"}, []interface{}{int64(2), "c"}).Sum(), "changed value")
+	assert.NotEqual(t, base, hashRows([]interface{}{int64(1), "a"}).Sum(), "missing row")
+
+	assert.NotEqual(t,
+		hashRows([]interface{}{"ab", "c"}).Sum(),
+		hashRows([]interface{}{"a", "bc"}).Sum(),
+		"values must not run together")
+	assert.NotEqual(t,
+		hashRows([]interface{}{nil}).Sum(),
+		hashRows([]interface{}{""}).Sum(),
+		"NULL differs from empty string")
+}
+
+func TestRowHasherTreatsTextAndBytesAlike(t *testing.T) {
+	assert.Equal(t,
+		hashRows([]interface{}{"payload"}).Sum(),
+		hashRows([]interface{}{[]byte("payload")}).Sum())
+}
+
+func TestBuildChecksumQueryOrdersByPrimaryKey(t *testing.T) {
+	assert.Equal(t,
+		`SELECT "AccountID", "DisplayName" FROM "public"."UserAccounts" ORDER BY "AccountID"`,
+		transfer.BuildChecksumQuery(accountsTable(), ""))
+	assert.Equal(t,
+		`SELECT "accountid", "displayname" FROM "public"."useraccounts" ORDER BY "accountid"`,
+		transfer.BuildChecksumQuery(accountsTable(), schema.IdentifierCaseLower))
+}
+
+func TestChecksumLeavesOutTransformedColumns(t *testing.T) {
+	table, skipped, ok := transfer.WithoutTransformedColumns(accountsTable(), map[string]string{
+		"public.UserAccounts.DisplayName": "mask",
+	})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"DisplayName"}, skipped)
+	assert.Equal(t,
+		`SELECT "AccountID" FROM "public"."UserAccounts" ORDER BY "AccountID"`,
+		transfer.BuildChecksumQuery(table, ""))
+
+	_, skipped, ok = transfer.WithoutTransformedColumns(accountsTable(), map[string]string{
+		"public.UserAccounts.AccountID": "hash",
+	})
+	assert.False(t, ok, "a transformed key reorders the target's rows")
+	assert.Equal(t, []string{"AccountID"}, skipped)
+
+	table, skipped, ok = transfer.WithoutTransformedColumns(accountsTable(), nil)
+	assert.True(t, ok)
+	assert.Empty(t, skipped)
+	assert.Equal(t, accountsTable(), table)
+}
@@ -0,0 +1,50 @@
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/kadirbelkuyu/DBRTS/internal/schema"
+	"github.com/kadirbelkuyu/DBRTS/internal/transfer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func accountsTable() schema.Table {
+	return schema.Table{
+		Name:   "UserAccounts",
+		Schema: "public",
+		Columns: []schema.Column{
+			{Name: "AccountID", DataType: "integer"},
+			{Name: "DisplayName", DataType: "text"},
+		},
+		PrimaryKeys: []string{"AccountID"},
+	}
+}
+
+func TestInsertQueryFoldsIdentifiers(t *testing.T) {
+	job := &transfer.DataTransferJob{Table: accountsTable(), IdentifierCase: schema.IdentifierCaseLower}
+
+	assert.Equal(t,
+		`INSERT INTO "public"."useraccounts" ("accountid", "displayname") VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		job.BuildInsertQuery())
+}
+
+func TestSelectQueryKeepsSourceIdentifiers(t *testing.T) {
+	job := &transfer.DataTransferJob{Table: accountsTable(), IdentifierCase: schema.IdentifierCaseLower}
+
+	assert.Equal(t,
+		`SELECT "AccountID", "DisplayName" FROM "public"."UserAccounts" ORDER BY "AccountID" OFFSET 0 LIMIT 100`,
+		job.BuildSelectQuery(0, 100))
+}
+
+func TestCopyQueryQuotesColumns(t *testing.T) {
+	job := &transfer.DataTransferJob{Table: accountsTable()}
+	assert.Equal(t,
+		`COPY "public"."UserAccounts" ("AccountID", "DisplayName") FROM STDIN`,
+		job.BuildCopyQuery())
+
+	job.IdentifierCase = schema.IdentifierCaseLower
+	assert.Equal(t,
+		`COPY "public"."useraccounts" ("accountid", "displayname") FROM STDIN`,
+		job.BuildCopyQuery())
+}